@@ -0,0 +1,237 @@
+package easylark
+
+import (
+	"fmt"
+)
+
+// CardTemplate 卡片标题栏颜色
+type CardTemplate string
+
+const (
+	CardTemplateDefault   CardTemplate = "default"
+	CardTemplateBlue      CardTemplate = "blue"
+	CardTemplateWathet    CardTemplate = "wathet"
+	CardTemplateTurquoise CardTemplate = "turquoise"
+	CardTemplateGreen     CardTemplate = "green"
+	CardTemplateYellow    CardTemplate = "yellow"
+	CardTemplateOrange    CardTemplate = "orange"
+	CardTemplateRed       CardTemplate = "red"
+	CardTemplateCarmine   CardTemplate = "carmine"
+	CardTemplateViolet    CardTemplate = "violet"
+	CardTemplatePurple    CardTemplate = "purple"
+	CardTemplateIndigo    CardTemplate = "indigo"
+	CardTemplateGrey      CardTemplate = "grey"
+)
+
+// CardHeader 卡片标题栏
+type CardHeader struct {
+	Title    *CardText    `json:"title"`
+	Subtitle *CardText    `json:"subtitle,omitempty"`
+	Template CardTemplate `json:"template,omitempty"`
+	Icon     *CardIcon    `json:"icon,omitempty"`
+}
+
+// CardIcon 标题栏图标
+type CardIcon struct {
+	ImgKey string `json:"img_key"`
+}
+
+// CardConfig 卡片全局配置
+type CardConfig struct {
+	WideScreenMode bool  `json:"wide_screen_mode"`
+	EnableForward  *bool `json:"enable_forward,omitempty"`
+	UpdateMulti    bool  `json:"update_multi,omitempty"`
+}
+
+// MessageCard 消息卡片
+type MessageCard struct {
+	header       *CardHeader
+	config       *CardConfig
+	elements     []CardElement
+	i18nElements map[string][]CardElement
+}
+
+// NewMessageCard 创建一个新的消息卡片
+func NewMessageCard() *MessageCard {
+	return &MessageCard{
+		elements: make([]CardElement, 0),
+	}
+}
+
+// ensureHeader 获取标题栏，不存在时创建
+func (c *MessageCard) ensureHeader() *CardHeader {
+	if c.header == nil {
+		c.header = &CardHeader{Title: NewPlainText("")}
+	}
+	return c.header
+}
+
+// ensureConfig 获取全局配置，不存在时创建
+func (c *MessageCard) ensureConfig() *CardConfig {
+	if c.config == nil {
+		c.config = &CardConfig{}
+	}
+	return c.config
+}
+
+// SetTitle 设置卡片标题
+func (c *MessageCard) SetTitle(title string) *MessageCard {
+	c.ensureHeader().Title.Content = title
+	return c
+}
+
+// SetI18nTitle 设置指定语言的卡片标题，lang如zh_cn、en_us、ja_jp
+func (c *MessageCard) SetI18nTitle(lang, title string) *MessageCard {
+	header := c.ensureHeader()
+	if header.Title.I18n == nil {
+		header.Title.I18n = make(map[string]string)
+	}
+	header.Title.I18n[lang] = title
+	return c
+}
+
+// SetSubtitle 设置卡片副标题
+func (c *MessageCard) SetSubtitle(subtitle string) *MessageCard {
+	c.ensureHeader().Subtitle = NewPlainText(subtitle)
+	return c
+}
+
+// SetTemplate 设置标题栏颜色
+func (c *MessageCard) SetTemplate(template CardTemplate) *MessageCard {
+	c.ensureHeader().Template = template
+	return c
+}
+
+// SetIcon 设置标题栏图标
+func (c *MessageCard) SetIcon(imgKey string) *MessageCard {
+	c.ensureHeader().Icon = &CardIcon{ImgKey: imgKey}
+	return c
+}
+
+// SetHeader 整体设置标题栏
+func (c *MessageCard) SetHeader(header *CardHeader) *MessageCard {
+	c.header = header
+	return c
+}
+
+// SetConfig 整体设置全局配置
+func (c *MessageCard) SetConfig(config *CardConfig) *MessageCard {
+	c.config = config
+	return c
+}
+
+// SetWideScreenMode 设置是否自适应屏幕宽度
+func (c *MessageCard) SetWideScreenMode(enabled bool) *MessageCard {
+	c.ensureConfig().WideScreenMode = enabled
+	return c
+}
+
+// SetUpdateMulti 设置是否为共享卡片，共享卡片更新后所有人可见
+func (c *MessageCard) SetUpdateMulti(enabled bool) *MessageCard {
+	c.ensureConfig().UpdateMulti = enabled
+	return c
+}
+
+// AddElement 添加卡片元素
+func (c *MessageCard) AddElement(elements ...CardElement) *MessageCard {
+	c.elements = append(c.elements, elements...)
+	return c
+}
+
+// AddText 添加文本内容
+func (c *MessageCard) AddText(text string) *MessageCard {
+	return c.AddElement(&CardDiv{Text: NewPlainText(text)})
+}
+
+// AddMarkdown 添加Markdown内容
+func (c *MessageCard) AddMarkdown(content string) *MessageCard {
+	return c.AddElement(&CardMarkdown{Content: content})
+}
+
+// AddHR 添加分割线
+func (c *MessageCard) AddHR() *MessageCard {
+	return c.AddElement(&CardHR{})
+}
+
+// AddI18nElements 添加指定语言的卡片元素，设置后将忽略通过AddElement添加的元素
+func (c *MessageCard) AddI18nElements(lang string, elements ...CardElement) *MessageCard {
+	if c.i18nElements == nil {
+		c.i18nElements = make(map[string][]CardElement)
+	}
+	c.i18nElements[lang] = append(c.i18nElements[lang], elements...)
+	return c
+}
+
+// Type 实现MessageContent接口
+func (c *MessageCard) Type() MessageType {
+	return MessageTypeInteractive
+}
+
+// Content 实现MessageContent接口
+func (c *MessageCard) Content() map[string]interface{} {
+	content := make(map[string]interface{})
+	if c.config != nil {
+		content["config"] = c.config
+	}
+	if c.header != nil {
+		content["header"] = c.header
+	}
+	if len(c.i18nElements) > 0 {
+		content["i18n_elements"] = c.i18nElements
+	} else {
+		content["elements"] = c.elements
+	}
+	return content
+}
+
+// Validate 校验卡片结构，在调用接口前发现问题
+func (c *MessageCard) Validate() error {
+	if c.header != nil {
+		if err := c.header.validate("header"); err != nil {
+			return fmt.Errorf("invalid card: %w", err)
+		}
+	}
+
+	if len(c.i18nElements) > 0 {
+		for lang, elements := range c.i18nElements {
+			if len(elements) == 0 {
+				return fmt.Errorf("invalid card: i18n_elements.%s is empty", lang)
+			}
+			if err := validateCardModules("i18n_elements."+lang, "card", elements); err != nil {
+				return fmt.Errorf("invalid card: %w", err)
+			}
+		}
+		return nil
+	}
+
+	if len(c.elements) == 0 && c.header == nil {
+		return fmt.Errorf("invalid card: card has neither header nor elements")
+	}
+	if err := validateCardModules("elements", "card", c.elements); err != nil {
+		return fmt.Errorf("invalid card: %w", err)
+	}
+	return nil
+}
+
+// validate 校验标题栏
+func (h *CardHeader) validate(path string) error {
+	if err := validateCardText(path+".title", h.Title); err != nil {
+		return err
+	}
+	if h.Subtitle != nil {
+		if err := h.Subtitle.validate(path + ".subtitle"); err != nil {
+			return err
+		}
+	}
+	switch h.Template {
+	case "", CardTemplateDefault, CardTemplateBlue, CardTemplateWathet, CardTemplateTurquoise,
+		CardTemplateGreen, CardTemplateYellow, CardTemplateOrange, CardTemplateRed,
+		CardTemplateCarmine, CardTemplateViolet, CardTemplatePurple, CardTemplateIndigo, CardTemplateGrey:
+	default:
+		return fmt.Errorf("%s: unknown template %q", path, h.Template)
+	}
+	if h.Icon != nil && h.Icon.ImgKey == "" {
+		return fmt.Errorf("%s.icon: img_key is required", path)
+	}
+	return nil
+}
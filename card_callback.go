@@ -0,0 +1,439 @@
+package easylark
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// 卡片回调toast类型
+const (
+	CardToastInfo    = "info"
+	CardToastSuccess = "success"
+	CardToastWarning = "warning"
+	CardToastError   = "error"
+)
+
+// defaultCardRouteKey 默认的路由字段，即按钮value中的action
+const defaultCardRouteKey = "action"
+
+// CardActionOperator 触发卡片交互的用户
+type CardActionOperator struct {
+	OpenID    string `json:"open_id"`
+	UserID    string `json:"user_id"`
+	UnionID   string `json:"union_id"`
+	TenantKey string `json:"tenant_key"`
+}
+
+// CardActionEvent 卡片交互回调，兼容旧版卡片回调和schema 2.0的card.action.trigger回调
+type CardActionEvent struct {
+	OpenMessageID string
+	OpenChatID    string
+	// Token 回调token，可在30分钟内通过DelayUpdateCard更新卡片
+	Token    string
+	Operator *CardActionOperator
+
+	Tag       string                 // 交互组件类型，如button、select_static
+	Name      string                 // 交互组件的name，表单提交时为提交按钮的name
+	Value     map[string]interface{} // 交互组件的value
+	Option    string                 // 下拉菜单、日期选择器等组件选中的值
+	Timezone  string
+	FormValue map[string]interface{} // 表单中各组件的值，key为组件name
+
+	// V2 是否为schema 2.0的回调
+	V2 bool
+	// Raw 解密后的完整payload
+	Raw json.RawMessage
+}
+
+// ValueString 获取value中key对应的字符串
+func (e *CardActionEvent) ValueString(key string) string {
+	s, _ := e.Value[key].(string)
+	return s
+}
+
+// FormString 获取表单中name对应组件的字符串值
+func (e *CardActionEvent) FormString(name string) string {
+	s, _ := e.FormValue[name].(string)
+	return s
+}
+
+// CardToast 回调响应中的toast提示
+type CardToast struct {
+	Type    string `json:"type"`
+	Content string `json:"content"`
+}
+
+// CardResponse 卡片回调的响应，Toast和Card可以同时设置
+type CardResponse struct {
+	Toast *CardToast
+	// Card 用于替换原卡片，可以是MessageCard或TemplateCard
+	Card MessageContent
+}
+
+// NewCardToast 创建toast响应，旧版卡片回调不支持toast
+func NewCardToast(toastType, content string) *CardResponse {
+	return &CardResponse{Toast: &CardToast{Type: toastType, Content: content}}
+}
+
+// NewCardUpdate 创建替换原卡片的响应
+func NewCardUpdate(card MessageContent) *CardResponse {
+	return &CardResponse{Card: card}
+}
+
+// CardActionHandlerFunc 卡片交互处理函数，返回nil时不更新卡片；返回错误时响应500
+type CardActionHandlerFunc func(ctx context.Context, event *CardActionEvent) (*CardResponse, error)
+
+// CardActionHandler 接收卡片交互回调的http.Handler
+//
+// 按交互组件value中RouteKey字段的值路由，value中没有该字段时按组件name路由：
+//
+//	card.AddElement(&easylark.CardAction{Actions: []easylark.CardElement{
+//		easylark.NewCardButton("批准", map[string]interface{}{"action": "approve", "id": "42"}),
+//	}})
+//	handler := easylark.NewCardActionHandler(token, key).
+//		On("approve", func(ctx context.Context, event *easylark.CardActionEvent) (*easylark.CardResponse, error) {
+//			return easylark.NewCardToast(easylark.CardToastSuccess, "已批准"), nil
+//		})
+//
+// 旧版回调校验X-Lark-Signature的sha1签名；schema 2.0回调校验token，配置了EncryptKey时校验sha256签名。
+type CardActionHandler struct {
+	VerificationToken string
+	EncryptKey        string
+	// RouteKey 用于路由的value字段，默认为action
+	RouteKey string
+
+	mu       sync.RWMutex
+	routes   map[string]CardActionHandlerFunc
+	fallback CardActionHandlerFunc
+}
+
+// NewCardActionHandler 创建卡片交互回调处理器，verificationToken和encryptKey为开发者后台的配置
+func NewCardActionHandler(verificationToken, encryptKey string) *CardActionHandler {
+	return &CardActionHandler{
+		VerificationToken: verificationToken,
+		EncryptKey:        encryptKey,
+		RouteKey:          defaultCardRouteKey,
+		routes:            make(map[string]CardActionHandlerFunc),
+	}
+}
+
+// On 注册路由值对应的处理函数
+func (h *CardActionHandler) On(action string, handler CardActionHandlerFunc) *CardActionHandler {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.routes == nil {
+		h.routes = make(map[string]CardActionHandlerFunc)
+	}
+	h.routes[action] = handler
+	return h
+}
+
+// OnDefault 注册未匹配任何路由时的处理函数
+func (h *CardActionHandler) OnDefault(handler CardActionHandlerFunc) *CardActionHandler {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.fallback = handler
+	return h
+}
+
+// route 查找事件对应的处理函数
+func (h *CardActionHandler) route(event *CardActionEvent) CardActionHandlerFunc {
+	key := h.RouteKey
+	if key == "" {
+		key = defaultCardRouteKey
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if action := event.ValueString(key); action != "" {
+		if handler, ok := h.routes[action]; ok {
+			return handler
+		}
+	} else if event.Name != "" {
+		if handler, ok := h.routes[event.Name]; ok {
+			return handler
+		}
+	}
+	return h.fallback
+}
+
+// cardActionPayload 卡片回调中的action字段
+type cardActionPayload struct {
+	Value     json.RawMessage        `json:"value"`
+	Tag       string                 `json:"tag"`
+	Name      string                 `json:"name"`
+	Option    string                 `json:"option"`
+	Timezone  string                 `json:"timezone"`
+	FormValue map[string]interface{} `json:"form_value"`
+}
+
+// cardCallbackEnvelope 卡片回调的外层结构，包含旧版、schema 2.0、url_verification和加密格式的字段
+type cardCallbackEnvelope struct {
+	Encrypt   string `json:"encrypt"`
+	Challenge string `json:"challenge"`
+	Type      string `json:"type"`
+	Token     string `json:"token"`
+
+	// 旧版
+	OpenID        string             `json:"open_id"`
+	UserID        string             `json:"user_id"`
+	UnionID       string             `json:"union_id"`
+	TenantKey     string             `json:"tenant_key"`
+	OpenMessageID string             `json:"open_message_id"`
+	OpenChatID    string             `json:"open_chat_id"`
+	Action        *cardActionPayload `json:"action"`
+
+	// schema 2.0
+	Schema string `json:"schema"`
+	Header *struct {
+		EventType string `json:"event_type"`
+		Token     string `json:"token"`
+	} `json:"header"`
+	Event *struct {
+		Operator *CardActionOperator `json:"operator"`
+		Token    string              `json:"token"`
+		Action   *cardActionPayload  `json:"action"`
+		Context  *struct {
+			OpenMessageID string `json:"open_message_id"`
+			OpenChatID    string `json:"open_chat_id"`
+		} `json:"context"`
+	} `json:"event"`
+}
+
+// ServeHTTP 实现http.Handler接口
+func (h *CardActionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxEventBodySize))
+	if err != nil {
+		http.Error(w, "read body failed", http.StatusBadRequest)
+		return
+	}
+
+	event, challenge, err := h.parse(r.Header, body)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrEventToken) || errors.Is(err, ErrEventSignature) {
+			status = http.StatusUnauthorized
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if event == nil {
+		writeEventJSON(w, http.StatusOK, map[string]string{"challenge": challenge})
+		return
+	}
+
+	var resp *CardResponse
+	if handler := h.route(event); handler != nil {
+		resp, err = handler(r.Context(), event)
+		if err != nil {
+			http.Error(w, "handle card action failed", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	body, err = resp.marshal(event.V2)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// parse 校验并解析卡片回调，url_verification请求返回challenge且event为nil
+//
+// header为nil时回调来自长连接，长连接的回调不签名，只校验token。
+func (h *CardActionHandler) parse(header http.Header, body []byte) (*CardActionEvent, string, error) {
+	var envelope cardCallbackEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, "", fmt.Errorf("parse card action failed: %w", err)
+	}
+
+	payload := body
+	if envelope.Encrypt != "" {
+		if h.EncryptKey == "" {
+			return nil, "", fmt.Errorf("parse card action failed: callback is encrypted but encrypt key is not configured")
+		}
+		decrypted, err := DecryptEvent(envelope.Encrypt, h.EncryptKey)
+		if err != nil {
+			return nil, "", err
+		}
+		payload = decrypted
+		envelope = cardCallbackEnvelope{}
+		if err := json.Unmarshal(payload, &envelope); err != nil {
+			return nil, "", fmt.Errorf("parse decrypted card action failed: %w", err)
+		}
+	}
+
+	if envelope.Type == "url_verification" {
+		if err := verifyEventToken(h.VerificationToken, envelope.Token); err != nil {
+			return nil, "", err
+		}
+		return nil, envelope.Challenge, nil
+	}
+
+	event := &CardActionEvent{Raw: payload}
+	var action *cardActionPayload
+	if envelope.Header != nil {
+		if err := verifyEventToken(h.VerificationToken, envelope.Header.Token); err != nil {
+			return nil, "", err
+		}
+		if h.EncryptKey != "" && header != nil {
+			if err := VerifyEventSignature(header, body, h.EncryptKey); err != nil {
+				return nil, "", err
+			}
+		}
+		if envelope.Event == nil {
+			return nil, "", fmt.Errorf("parse card action failed: missing event")
+		}
+		event.V2 = true
+		event.Token = envelope.Event.Token
+		event.Operator = envelope.Event.Operator
+		if envelope.Event.Context != nil {
+			event.OpenMessageID = envelope.Event.Context.OpenMessageID
+			event.OpenChatID = envelope.Event.Context.OpenChatID
+		}
+		action = envelope.Event.Action
+	} else {
+		if h.VerificationToken != "" && header != nil {
+			if err := VerifyCardSignature(header, body, h.VerificationToken); err != nil {
+				return nil, "", err
+			}
+		}
+		event.Token = envelope.Token
+		event.OpenMessageID = envelope.OpenMessageID
+		event.OpenChatID = envelope.OpenChatID
+		event.Operator = &CardActionOperator{
+			OpenID:    envelope.OpenID,
+			UserID:    envelope.UserID,
+			UnionID:   envelope.UnionID,
+			TenantKey: envelope.TenantKey,
+		}
+		action = envelope.Action
+	}
+
+	if action == nil {
+		return nil, "", fmt.Errorf("parse card action failed: missing action")
+	}
+	event.Tag = action.Tag
+	event.Name = action.Name
+	event.Option = action.Option
+	event.Timezone = action.Timezone
+	event.FormValue = action.FormValue
+	// value一般为对象，其他类型的value忽略
+	if len(action.Value) > 0 && action.Value[0] == '{' {
+		if err := json.Unmarshal(action.Value, &event.Value); err != nil {
+			return nil, "", fmt.Errorf("parse card action value failed: %w", err)
+		}
+	}
+	return event, "", nil
+}
+
+// marshal 序列化回调响应；旧版回调直接返回卡片内容，schema 2.0回调返回toast和card
+func (r *CardResponse) marshal(v2 bool) ([]byte, error) {
+	if r == nil {
+		return []byte("{}"), nil
+	}
+	if r.Card != nil {
+		if r.Card.Type() != MessageTypeInteractive {
+			return nil, fmt.Errorf("card response failed: unsupported message type %s", r.Card.Type())
+		}
+		if err := validateMessageContent(r.Card); err != nil {
+			return nil, err
+		}
+	}
+
+	if !v2 {
+		if r.Card == nil {
+			return []byte("{}"), nil
+		}
+		return json.Marshal(r.Card.Content())
+	}
+
+	resp := make(map[string]interface{})
+	if r.Toast != nil {
+		resp["toast"] = r.Toast
+	}
+	if r.Card != nil {
+		content := r.Card.Content()
+		if content["type"] == "template" {
+			resp["card"] = content
+		} else {
+			resp["card"] = map[string]interface{}{"type": "raw", "data": content}
+		}
+	}
+	return json.Marshal(resp)
+}
+
+// VerifyCardSignature 校验旧版卡片回调的签名，签名为sha1(timestamp+nonce+verificationToken+body)的十六进制
+func VerifyCardSignature(header http.Header, body []byte, verificationToken string) error {
+	signature := header.Get(HeaderLarkSignature)
+	if signature == "" {
+		return ErrEventSignature
+	}
+
+	hash := sha1.New()
+	hash.Write([]byte(header.Get(HeaderLarkTimestamp)))
+	hash.Write([]byte(header.Get(HeaderLarkNonce)))
+	hash.Write([]byte(verificationToken))
+	hash.Write(body)
+	expected := hex.EncodeToString(hash.Sum(nil))
+
+	if subtle.ConstantTimeCompare([]byte(signature), []byte(expected)) != 1 {
+		return ErrEventSignature
+	}
+	return nil
+}
+
+// DelayUpdateCard 使用卡片回调中的token延时更新卡片，token有效期30分钟，最多更新2次
+//
+// openIDs不为空时只更新这些用户看到的卡片，仅对未开启update_multi的卡片生效。
+func (s *MessageService) DelayUpdateCard(token string, card MessageContent, openIDs ...string) error {
+	if token == "" {
+		return fmt.Errorf("delay update card failed: token is required")
+	}
+	if card.Type() != MessageTypeInteractive {
+		return fmt.Errorf("delay update card failed: unsupported message type %s", card.Type())
+	}
+	if err := validateMessageContent(card); err != nil {
+		return err
+	}
+
+	content := card.Content()
+	if len(openIDs) > 0 {
+		copied := make(map[string]interface{}, len(content)+1)
+		for k, v := range content {
+			copied[k] = v
+		}
+		copied["open_ids"] = openIDs
+		content = copied
+	}
+
+	reqBody := map[string]interface{}{
+		"token": token,
+		"card":  content,
+	}
+
+	var result APIResponse
+	if err := s.client.DoRequest("POST", "/interactive/v1/card/update", reqBody, &result); err != nil {
+		return fmt.Errorf("delay update card failed: %w", err)
+	}
+	if result.Code != 0 {
+		return &Error{Code: result.Code, Message: result.Msg}
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package easylark
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newCardCallbackRequest 构造带sha1签名的旧版卡片回调请求
+func newCardCallbackRequest(body, verificationToken string) *http.Request {
+	req := httptest.NewRequest("POST", "/webhook/card", strings.NewReader(body))
+	req.Header.Set(HeaderLarkTimestamp, "1700000000")
+	req.Header.Set(HeaderLarkNonce, "nonce")
+	sum := sha1.Sum([]byte("1700000000" + "nonce" + verificationToken + body))
+	req.Header.Set(HeaderLarkSignature, hex.EncodeToString(sum[:]))
+	return req
+}
+
+const testCardCallbackV1 = `{
+	"open_id": "ou_1",
+	"user_id": "u_1",
+	"open_message_id": "om_1",
+	"open_chat_id": "oc_1",
+	"tenant_key": "tk_1",
+	"token": "c-token",
+	"action": {"tag": "button", "value": {"action": "approve", "id": "42"}}
+}`
+
+const testCardCallbackV2 = `{
+	"schema": "2.0",
+	"header": {"event_id": "ev_1", "event_type": "card.action.trigger", "token": "vtoken"},
+	"event": {
+		"operator": {"open_id": "ou_1", "tenant_key": "tk_1"},
+		"token": "c-token",
+		"action": {"tag": "button", "name": "submit", "form_value": {"reason": "上线"}},
+		"context": {"open_message_id": "om_1", "open_chat_id": "oc_1"}
+	}
+}`
+
+func TestCardActionHandlerV1(t *testing.T) {
+	var got *CardActionEvent
+	handler := NewCardActionHandler("vtoken", "").
+		On("approve", func(ctx context.Context, event *CardActionEvent) (*CardResponse, error) {
+			got = event
+			return NewCardUpdate(NewMessageCard().SetTitle("已批准").AddText("ok")), nil
+		})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newCardCallbackRequest(testCardCallbackV1, "vtoken"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	if got == nil || got.V2 || got.Token != "c-token" || got.OpenMessageID != "om_1" || got.Operator.OpenID != "ou_1" {
+		t.Fatalf("Unexpected event: %+v", got)
+	}
+	if got.ValueString("id") != "42" {
+		t.Errorf("Expected value id 42, got %v", got.Value)
+	}
+
+	// 旧版回调直接返回卡片内容
+	var card map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &card)
+	if card["header"] == nil || card["elements"] == nil {
+		t.Errorf("Expected card in response, got %s", rec.Body.String())
+	}
+
+	// 签名错误
+	req := newCardCallbackRequest(testCardCallbackV1, "other")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for bad signature, got %d", rec.Code)
+	}
+}
+
+func TestCardActionHandlerV2(t *testing.T) {
+	var got *CardActionEvent
+	handler := NewCardActionHandler("vtoken", "").
+		On("submit", func(ctx context.Context, event *CardActionEvent) (*CardResponse, error) {
+			got = event
+			resp := NewCardToast(CardToastSuccess, "已提交")
+			resp.Card = NewTemplateCard("tpl_1", "")
+			return resp, nil
+		})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader(testCardCallbackV2)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	if got == nil || !got.V2 || got.OpenChatID != "oc_1" || got.FormString("reason") != "上线" {
+		t.Fatalf("Unexpected event: %+v", got)
+	}
+
+	var resp struct {
+		Toast *CardToast `json:"toast"`
+		Card  struct {
+			Type string `json:"type"`
+		} `json:"card"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp.Toast == nil || resp.Toast.Content != "已提交" || resp.Card.Type != "template" {
+		t.Errorf("Unexpected response: %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader(strings.Replace(testCardCallbackV2, "vtoken", "wrong", 1))))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for wrong token, got %d", rec.Code)
+	}
+}
+
+func TestCardActionHandlerEncrypted(t *testing.T) {
+	called := false
+	handler := NewCardActionHandler("vtoken", "ekey").OnDefault(func(ctx context.Context, event *CardActionEvent) (*CardResponse, error) {
+		called = true
+		return nil, nil
+	})
+
+	challenge, _ := json.Marshal(map[string]string{"encrypt": encryptTestEvent(t, []byte(`{"challenge":"xyz","token":"vtoken","type":"url_verification"}`), "ekey")})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/", bytes.NewReader(challenge)))
+	if !strings.Contains(rec.Body.String(), `"challenge":"xyz"`) {
+		t.Errorf("Expected challenge response, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	body, _ := json.Marshal(map[string]string{"encrypt": encryptTestEvent(t, []byte(testCardCallbackV2), "ekey")})
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, newSignedEventRequest(body, "ekey"))
+	if rec.Code != http.StatusOK || rec.Body.String() != "{}" || !called {
+		t.Errorf("Expected empty response from default handler, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestCardActionHandlerErrors(t *testing.T) {
+	handler := NewCardActionHandler("", "").
+		On("approve", func(ctx context.Context, event *CardActionEvent) (*CardResponse, error) {
+			return nil, errors.New("boom")
+		}).
+		On("bad", func(ctx context.Context, event *CardActionEvent) (*CardResponse, error) {
+			return NewCardUpdate(NewMessageCard()), nil
+		})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader(testCardCallbackV1)))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500 when handler fails, got %d", rec.Code)
+	}
+
+	// 返回的卡片不合法
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader(strings.Replace(testCardCallbackV1, "approve", "bad", 1))))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500 for invalid card, got %d", rec.Code)
+	}
+
+	// 没有匹配的路由时返回空响应
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader(strings.Replace(testCardCallbackV1, "approve", "other", 1))))
+	if rec.Code != http.StatusOK || rec.Body.String() != "{}" {
+		t.Errorf("Expected empty response, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader(`{"token":"t"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for missing action, got %d", rec.Code)
+	}
+}
+
+func TestDelayUpdateCard(t *testing.T) {
+	var reqBody map[string]interface{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/open-apis/interactive/v1/card/update" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		writeTestResponse(w, nil)
+	})
+
+	card := NewMessageCard().SetTitle("处理中").AddText("请稍候")
+	if err := client.Message.DelayUpdateCard("c-token", card, "ou_1"); err != nil {
+		t.Fatalf("delay update card failed: %v", err)
+	}
+	if reqBody["token"] != "c-token" {
+		t.Errorf("Unexpected token %v", reqBody["token"])
+	}
+	content := reqBody["card"].(map[string]interface{})
+	if content["header"] == nil || len(content["open_ids"].([]interface{})) != 1 {
+		t.Errorf("Unexpected card: %v", content)
+	}
+	if _, ok := card.Content()["open_ids"]; ok {
+		t.Error("Expected card content not to be modified")
+	}
+
+	if err := client.Message.DelayUpdateCard("", card); err == nil {
+		t.Error("Expected error for empty token")
+	}
+}
@@ -0,0 +1,800 @@
+package easylark
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// CardElement 卡片元素
+//
+// 所有元素都以指针形式使用，序列化时会自动补充tag字段。
+type CardElement interface {
+	validate(path string) error
+}
+
+// marshalCardElement 序列化卡片元素并在首位补充tag字段
+func marshalCardElement(tag string, v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	tagField := fmt.Sprintf(`{"tag":%q`, tag)
+	if string(data) == "{}" {
+		return []byte(tagField + "}"), nil
+	}
+	return append([]byte(tagField+","), data[1:]...), nil
+}
+
+// validateCardElements 逐个校验卡片元素
+func validateCardElements(path string, elements []CardElement) error {
+	for i, element := range elements {
+		elementPath := fmt.Sprintf("%s[%d]", path, i)
+		if element == nil {
+			return fmt.Errorf("%s: element is nil", elementPath)
+		}
+		if err := element.validate(elementPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateCardModules 校验卡片正文、分栏、表单等容器中的元素，表单中还允许直接放置表单组件
+func validateCardModules(path, container string, elements []CardElement) error {
+	for i, element := range elements {
+		if element == nil || isCardModule(element) {
+			continue
+		}
+		switch element.(type) {
+		case *CardInput, *CardButton, *CardSelectMenu, *CardDatePicker:
+			if container == "form" {
+				continue
+			}
+		}
+		return fmt.Errorf("%s[%d]: %s does not support %T", path, i, container, element)
+	}
+	return validateCardElements(path, elements)
+}
+
+// isCardModule 判断元素是否可以直接放在卡片正文等容器中
+func isCardModule(element CardElement) bool {
+	switch element.(type) {
+	case *CardDiv, *CardMarkdown, *CardHR, *CardImage, *CardNote, *CardColumnSet,
+		*CardAction, *CardTable, *CardChart, *CardForm, *CardCollapsiblePanel:
+		return true
+	}
+	return false
+}
+
+// 卡片文本类型
+const (
+	CardTextPlain  = "plain_text" // 普通文本
+	CardTextLarkMD = "lark_md"    // 支持部分Markdown语法的文本
+)
+
+// CardText 卡片文本对象，也可作为备注元素的内容
+type CardText struct {
+	Tag     string            `json:"tag"`
+	Content string            `json:"content"`
+	Lines   int               `json:"lines,omitempty"`
+	I18n    map[string]string `json:"i18n,omitempty"`
+}
+
+// NewPlainText 创建普通文本
+func NewPlainText(content string) *CardText {
+	return &CardText{Tag: CardTextPlain, Content: content}
+}
+
+// NewLarkMD 创建lark_md文本
+func NewLarkMD(content string) *CardText {
+	return &CardText{Tag: CardTextLarkMD, Content: content}
+}
+
+// validate 实现CardElement接口
+func (t *CardText) validate(path string) error {
+	if t.Tag != CardTextPlain && t.Tag != CardTextLarkMD {
+		return fmt.Errorf("%s: unknown text tag %q", path, t.Tag)
+	}
+	if t.Lines < 0 {
+		return fmt.Errorf("%s: lines must not be negative", path)
+	}
+	return nil
+}
+
+// validateCardText 校验必填的文本对象
+func validateCardText(path string, t *CardText) error {
+	if t == nil || (t.Content == "" && len(t.I18n) == 0) {
+		return fmt.Errorf("%s: text is required", path)
+	}
+	return t.validate(path)
+}
+
+// CardMarkdown Markdown元素
+type CardMarkdown struct {
+	Content   string `json:"content"`
+	TextAlign string `json:"text_align,omitempty"`
+	TextSize  string `json:"text_size,omitempty"`
+}
+
+// MarshalJSON 序列化时补充tag字段
+func (e *CardMarkdown) MarshalJSON() ([]byte, error) {
+	type alias CardMarkdown
+	return marshalCardElement("markdown", (*alias)(e))
+}
+
+// validate 实现CardElement接口
+func (e *CardMarkdown) validate(path string) error {
+	if e.Content == "" {
+		return fmt.Errorf("%s: markdown requires content", path)
+	}
+	return validateTextAlign(path, e.TextAlign)
+}
+
+// CardField 内容模块中的字段
+type CardField struct {
+	IsShort bool      `json:"is_short"`
+	Text    *CardText `json:"text"`
+}
+
+// CardDiv 内容模块
+type CardDiv struct {
+	Text   *CardText    `json:"text,omitempty"`
+	Fields []*CardField `json:"fields,omitempty"`
+	Extra  CardElement  `json:"extra,omitempty"`
+}
+
+// MarshalJSON 序列化时补充tag字段
+func (e *CardDiv) MarshalJSON() ([]byte, error) {
+	type alias CardDiv
+	return marshalCardElement("div", (*alias)(e))
+}
+
+// validate 实现CardElement接口
+func (e *CardDiv) validate(path string) error {
+	if e.Text == nil && len(e.Fields) == 0 {
+		return fmt.Errorf("%s: div requires text or fields", path)
+	}
+	if e.Text != nil {
+		if err := validateCardText(path+".text", e.Text); err != nil {
+			return err
+		}
+	}
+	for i, field := range e.Fields {
+		fieldPath := fmt.Sprintf("%s.fields[%d]", path, i)
+		if field == nil {
+			return fmt.Errorf("%s: field is nil", fieldPath)
+		}
+		if err := validateCardText(fieldPath+".text", field.Text); err != nil {
+			return err
+		}
+	}
+	if e.Extra != nil {
+		switch e.Extra.(type) {
+		case *CardImage, *CardButton, *CardSelectMenu, *CardOverflow, *CardDatePicker:
+		default:
+			return fmt.Errorf("%s.extra: unsupported element %T", path, e.Extra)
+		}
+		return e.Extra.validate(path + ".extra")
+	}
+	return nil
+}
+
+// CardHR 分割线
+type CardHR struct{}
+
+// MarshalJSON 序列化时补充tag字段
+func (e *CardHR) MarshalJSON() ([]byte, error) {
+	return marshalCardElement("hr", struct{}{})
+}
+
+// validate 实现CardElement接口
+func (e *CardHR) validate(path string) error {
+	return nil
+}
+
+// CardImage 图片元素
+type CardImage struct {
+	ImgKey      string    `json:"img_key"`
+	Alt         *CardText `json:"alt"`
+	Title       *CardText `json:"title,omitempty"`
+	Mode        string    `json:"mode,omitempty"`
+	CustomWidth int       `json:"custom_width,omitempty"`
+	Preview     *bool     `json:"preview,omitempty"`
+}
+
+// NewCardImage 创建图片元素，alt为图片悬浮说明
+func NewCardImage(imgKey, alt string) *CardImage {
+	return &CardImage{ImgKey: imgKey, Alt: NewPlainText(alt)}
+}
+
+// MarshalJSON 序列化时补充tag字段
+func (e *CardImage) MarshalJSON() ([]byte, error) {
+	type alias CardImage
+	v := *e
+	if v.Alt == nil {
+		v.Alt = NewPlainText("")
+	}
+	return marshalCardElement("img", (*alias)(&v))
+}
+
+// validate 实现CardElement接口
+func (e *CardImage) validate(path string) error {
+	if e.ImgKey == "" {
+		return fmt.Errorf("%s: img requires img_key", path)
+	}
+	switch e.Mode {
+	case "", "crop_center", "fit_horizontal", "large", "medium", "small", "tiny":
+	default:
+		return fmt.Errorf("%s: unknown img mode %q", path, e.Mode)
+	}
+	return nil
+}
+
+// CardNote 备注元素，只能包含文本和图片
+type CardNote struct {
+	Elements []CardElement `json:"elements"`
+}
+
+// MarshalJSON 序列化时补充tag字段
+func (e *CardNote) MarshalJSON() ([]byte, error) {
+	type alias CardNote
+	return marshalCardElement("note", (*alias)(e))
+}
+
+// validate 实现CardElement接口
+func (e *CardNote) validate(path string) error {
+	if len(e.Elements) == 0 {
+		return fmt.Errorf("%s: note requires elements", path)
+	}
+	for i, element := range e.Elements {
+		switch element.(type) {
+		case *CardText, *CardImage:
+		default:
+			return fmt.Errorf("%s.elements[%d]: note only supports text and img, got %T", path, i, element)
+		}
+	}
+	return validateCardElements(path+".elements", e.Elements)
+}
+
+// CardColumn 分栏中的列
+type CardColumn struct {
+	Width         string        `json:"width,omitempty"`
+	Weight        int           `json:"weight,omitempty"`
+	VerticalAlign string        `json:"vertical_align,omitempty"`
+	Elements      []CardElement `json:"elements"`
+}
+
+// MarshalJSON 序列化时补充tag字段
+func (e *CardColumn) MarshalJSON() ([]byte, error) {
+	type alias CardColumn
+	return marshalCardElement("column", (*alias)(e))
+}
+
+// CardColumnSet 分栏元素
+type CardColumnSet struct {
+	FlexMode          string        `json:"flex_mode,omitempty"`
+	BackgroundStyle   string        `json:"background_style,omitempty"`
+	HorizontalSpacing string        `json:"horizontal_spacing,omitempty"`
+	Columns           []*CardColumn `json:"columns"`
+}
+
+// MarshalJSON 序列化时补充tag字段
+func (e *CardColumnSet) MarshalJSON() ([]byte, error) {
+	type alias CardColumnSet
+	return marshalCardElement("column_set", (*alias)(e))
+}
+
+// validate 实现CardElement接口
+func (e *CardColumnSet) validate(path string) error {
+	if len(e.Columns) == 0 {
+		return fmt.Errorf("%s: column_set requires columns", path)
+	}
+	switch e.FlexMode {
+	case "", "none", "stretch", "flow", "bisect", "trisect":
+	default:
+		return fmt.Errorf("%s: unknown flex_mode %q", path, e.FlexMode)
+	}
+	for i, column := range e.Columns {
+		columnPath := fmt.Sprintf("%s.columns[%d]", path, i)
+		if column == nil {
+			return fmt.Errorf("%s: column is nil", columnPath)
+		}
+		switch column.Width {
+		case "", "auto", "weighted":
+		default:
+			return fmt.Errorf("%s: unknown width %q", columnPath, column.Width)
+		}
+		if column.Width == "weighted" && (column.Weight < 1 || column.Weight > 5) {
+			return fmt.Errorf("%s: weight must be between 1 and 5", columnPath)
+		}
+		if err := validateCardModules(columnPath+".elements", "column", column.Elements); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CardConfirm 交互组件的二次确认弹框
+type CardConfirm struct {
+	Title *CardText `json:"title"`
+	Text  *CardText `json:"text"`
+}
+
+// validate 校验二次确认弹框
+func (c *CardConfirm) validate(path string) error {
+	if err := validateCardText(path+".title", c.Title); err != nil {
+		return err
+	}
+	return validateCardText(path+".text", c.Text)
+}
+
+// CardOption 下拉菜单和折叠按钮组的选项
+type CardOption struct {
+	Text  *CardText `json:"text,omitempty"`
+	Value string    `json:"value"`
+	URL   string    `json:"url,omitempty"`
+}
+
+// validateCardOptions 校验选项列表
+func validateCardOptions(path string, options []*CardOption) error {
+	seen := make(map[string]bool)
+	for i, option := range options {
+		optionPath := fmt.Sprintf("%s.options[%d]", path, i)
+		if option == nil || option.Value == "" {
+			return fmt.Errorf("%s: option requires value", optionPath)
+		}
+		if seen[option.Value] {
+			return fmt.Errorf("%s: duplicate option value %q", optionPath, option.Value)
+		}
+		seen[option.Value] = true
+		if option.Text != nil {
+			if err := option.Text.validate(optionPath + ".text"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// 按钮类型
+const (
+	CardButtonDefault = "default"
+	CardButtonPrimary = "primary"
+	CardButtonDanger  = "danger"
+)
+
+// CardButton 按钮
+type CardButton struct {
+	Text       *CardText              `json:"text"`
+	Type       string                 `json:"type,omitempty"`
+	URL        string                 `json:"url,omitempty"`
+	Value      map[string]interface{} `json:"value,omitempty"`
+	Confirm    *CardConfirm           `json:"confirm,omitempty"`
+	Name       string                 `json:"name,omitempty"`
+	ActionType string                 `json:"action_type,omitempty"`
+}
+
+// NewCardButton 创建回传交互按钮
+func NewCardButton(text string, value map[string]interface{}) *CardButton {
+	return &CardButton{Text: NewPlainText(text), Type: CardButtonDefault, Value: value}
+}
+
+// NewCardLinkButton 创建跳转链接按钮
+func NewCardLinkButton(text, url string) *CardButton {
+	return &CardButton{Text: NewPlainText(text), Type: CardButtonDefault, URL: url}
+}
+
+// NewCardSubmitButton 创建表单提交按钮
+func NewCardSubmitButton(text, name string) *CardButton {
+	return &CardButton{Text: NewPlainText(text), Type: CardButtonPrimary, Name: name, ActionType: "form_submit"}
+}
+
+// MarshalJSON 序列化时补充tag字段
+func (e *CardButton) MarshalJSON() ([]byte, error) {
+	type alias CardButton
+	return marshalCardElement("button", (*alias)(e))
+}
+
+// validate 实现CardElement接口
+func (e *CardButton) validate(path string) error {
+	if err := validateCardText(path+".text", e.Text); err != nil {
+		return err
+	}
+	switch e.Type {
+	case "", CardButtonDefault, CardButtonPrimary, CardButtonDanger:
+	default:
+		return fmt.Errorf("%s: unknown button type %q", path, e.Type)
+	}
+	switch e.ActionType {
+	case "", "link", "request", "multi", "form_submit", "form_reset":
+	default:
+		return fmt.Errorf("%s: unknown action_type %q", path, e.ActionType)
+	}
+	if e.Confirm != nil {
+		return e.Confirm.validate(path + ".confirm")
+	}
+	return nil
+}
+
+// 下拉菜单类型
+const (
+	CardSelectStatic = "select_static" // 选项下拉菜单
+	CardSelectPerson = "select_person" // 人员下拉菜单
+)
+
+// CardSelectMenu 下拉菜单
+type CardSelectMenu struct {
+	Tag           string                 `json:"tag"`
+	Placeholder   *CardText              `json:"placeholder,omitempty"`
+	InitialOption string                 `json:"initial_option,omitempty"`
+	Options       []*CardOption          `json:"options,omitempty"`
+	Value         map[string]interface{} `json:"value,omitempty"`
+	Confirm       *CardConfirm           `json:"confirm,omitempty"`
+	Name          string                 `json:"name,omitempty"`
+}
+
+// NewCardSelectMenu 创建选项下拉菜单
+func NewCardSelectMenu(placeholder string, options ...*CardOption) *CardSelectMenu {
+	return &CardSelectMenu{Tag: CardSelectStatic, Placeholder: NewPlainText(placeholder), Options: options}
+}
+
+// validate 实现CardElement接口
+func (e *CardSelectMenu) validate(path string) error {
+	switch e.Tag {
+	case CardSelectStatic:
+		if len(e.Options) == 0 {
+			return fmt.Errorf("%s: select_static requires options", path)
+		}
+	case CardSelectPerson:
+	default:
+		return fmt.Errorf("%s: unknown select menu tag %q", path, e.Tag)
+	}
+	if err := validateCardOptions(path, e.Options); err != nil {
+		return err
+	}
+	if e.InitialOption != "" && e.Tag == CardSelectStatic {
+		found := false
+		for _, option := range e.Options {
+			found = found || option.Value == e.InitialOption
+		}
+		if !found {
+			return fmt.Errorf("%s: initial_option %q is not one of the options", path, e.InitialOption)
+		}
+	}
+	if e.Confirm != nil {
+		return e.Confirm.validate(path + ".confirm")
+	}
+	return nil
+}
+
+// 日期选择器类型
+const (
+	CardPickerDate     = "date_picker"     // 日期选择器
+	CardPickerTime     = "picker_time"     // 时间选择器
+	CardPickerDatetime = "picker_datetime" // 日期时间选择器
+)
+
+// CardDatePicker 日期时间选择器
+type CardDatePicker struct {
+	Tag             string                 `json:"tag"`
+	Placeholder     *CardText              `json:"placeholder,omitempty"`
+	InitialDate     string                 `json:"initial_date,omitempty"`
+	InitialTime     string                 `json:"initial_time,omitempty"`
+	InitialDatetime string                 `json:"initial_datetime,omitempty"`
+	Value           map[string]interface{} `json:"value,omitempty"`
+	Confirm         *CardConfirm           `json:"confirm,omitempty"`
+	Name            string                 `json:"name,omitempty"`
+}
+
+// validate 实现CardElement接口
+func (e *CardDatePicker) validate(path string) error {
+	switch e.Tag {
+	case CardPickerDate, CardPickerTime, CardPickerDatetime:
+	default:
+		return fmt.Errorf("%s: unknown picker tag %q", path, e.Tag)
+	}
+	if e.Confirm != nil {
+		return e.Confirm.validate(path + ".confirm")
+	}
+	return nil
+}
+
+// CardOverflow 折叠按钮组
+type CardOverflow struct {
+	Options []*CardOption          `json:"options"`
+	Value   map[string]interface{} `json:"value,omitempty"`
+	Confirm *CardConfirm           `json:"confirm,omitempty"`
+}
+
+// MarshalJSON 序列化时补充tag字段
+func (e *CardOverflow) MarshalJSON() ([]byte, error) {
+	type alias CardOverflow
+	return marshalCardElement("overflow", (*alias)(e))
+}
+
+// validate 实现CardElement接口
+func (e *CardOverflow) validate(path string) error {
+	if len(e.Options) == 0 {
+		return fmt.Errorf("%s: overflow requires options", path)
+	}
+	if err := validateCardOptions(path, e.Options); err != nil {
+		return err
+	}
+	if e.Confirm != nil {
+		return e.Confirm.validate(path + ".confirm")
+	}
+	return nil
+}
+
+// CardAction 交互模块
+type CardAction struct {
+	Actions []CardElement `json:"actions"`
+	Layout  string        `json:"layout,omitempty"`
+}
+
+// MarshalJSON 序列化时补充tag字段
+func (e *CardAction) MarshalJSON() ([]byte, error) {
+	type alias CardAction
+	return marshalCardElement("action", (*alias)(e))
+}
+
+// validate 实现CardElement接口
+func (e *CardAction) validate(path string) error {
+	if len(e.Actions) == 0 {
+		return fmt.Errorf("%s: action requires actions", path)
+	}
+	switch e.Layout {
+	case "", "bisected", "trisection", "flow":
+	default:
+		return fmt.Errorf("%s: unknown layout %q", path, e.Layout)
+	}
+	for i, action := range e.Actions {
+		switch action.(type) {
+		case *CardButton, *CardSelectMenu, *CardDatePicker, *CardOverflow:
+		default:
+			return fmt.Errorf("%s.actions[%d]: unsupported interactive element %T", path, i, action)
+		}
+	}
+	return validateCardElements(path+".actions", e.Actions)
+}
+
+// CardTableColumn 表格列定义
+type CardTableColumn struct {
+	Name            string `json:"name"`
+	DisplayName     string `json:"display_name,omitempty"`
+	DataType        string `json:"data_type"`
+	Width           string `json:"width,omitempty"`
+	HorizontalAlign string `json:"horizontal_align,omitempty"`
+}
+
+// CardTableHeaderStyle 表头样式
+type CardTableHeaderStyle struct {
+	TextAlign       string `json:"text_align,omitempty"`
+	TextSize        string `json:"text_size,omitempty"`
+	BackgroundStyle string `json:"background_style,omitempty"`
+	Bold            bool   `json:"bold,omitempty"`
+}
+
+// CardTable 表格元素
+type CardTable struct {
+	PageSize    int                      `json:"page_size,omitempty"`
+	RowHeight   string                   `json:"row_height,omitempty"`
+	HeaderStyle *CardTableHeaderStyle    `json:"header_style,omitempty"`
+	Columns     []*CardTableColumn       `json:"columns"`
+	Rows        []map[string]interface{} `json:"rows"`
+}
+
+// MarshalJSON 序列化时补充tag字段
+func (e *CardTable) MarshalJSON() ([]byte, error) {
+	type alias CardTable
+	v := *e
+	if v.Rows == nil {
+		v.Rows = make([]map[string]interface{}, 0)
+	}
+	return marshalCardElement("table", (*alias)(&v))
+}
+
+// validate 实现CardElement接口
+func (e *CardTable) validate(path string) error {
+	if len(e.Columns) == 0 {
+		return fmt.Errorf("%s: table requires columns", path)
+	}
+	if e.PageSize < 0 || e.PageSize > 10 {
+		return fmt.Errorf("%s: page_size must not exceed 10", path)
+	}
+	names := make(map[string]bool)
+	for i, column := range e.Columns {
+		columnPath := fmt.Sprintf("%s.columns[%d]", path, i)
+		if column == nil || column.Name == "" {
+			return fmt.Errorf("%s: column requires name", columnPath)
+		}
+		if names[column.Name] {
+			return fmt.Errorf("%s: duplicate column name %q", columnPath, column.Name)
+		}
+		names[column.Name] = true
+		switch column.DataType {
+		case "text", "lark_md", "options", "number", "persons", "date", "markdown":
+		default:
+			return fmt.Errorf("%s: unknown data_type %q", columnPath, column.DataType)
+		}
+	}
+	for i, row := range e.Rows {
+		for key := range row {
+			if !names[key] {
+				return fmt.Errorf("%s.rows[%d]: unknown column %q", path, i, key)
+			}
+		}
+	}
+	return nil
+}
+
+// CardChart 图表元素，ChartSpec为VChart图表定义
+type CardChart struct {
+	ChartSpec   map[string]interface{} `json:"chart_spec"`
+	AspectRatio string                 `json:"aspect_ratio,omitempty"`
+	ColorTheme  string                 `json:"color_theme,omitempty"`
+	Preview     *bool                  `json:"preview,omitempty"`
+	Height      string                 `json:"height,omitempty"`
+}
+
+// MarshalJSON 序列化时补充tag字段
+func (e *CardChart) MarshalJSON() ([]byte, error) {
+	type alias CardChart
+	return marshalCardElement("chart", (*alias)(e))
+}
+
+// validate 实现CardElement接口
+func (e *CardChart) validate(path string) error {
+	if len(e.ChartSpec) == 0 {
+		return fmt.Errorf("%s: chart requires chart_spec", path)
+	}
+	if _, ok := e.ChartSpec["type"]; !ok {
+		return fmt.Errorf("%s: chart_spec requires type", path)
+	}
+	return nil
+}
+
+// CardInput 输入框，用于表单容器
+type CardInput struct {
+	Name         string    `json:"name"`
+	Required     bool      `json:"required,omitempty"`
+	Placeholder  *CardText `json:"placeholder,omitempty"`
+	DefaultValue string    `json:"default_value,omitempty"`
+	Label        *CardText `json:"label,omitempty"`
+	MaxLength    int       `json:"max_length,omitempty"`
+}
+
+// MarshalJSON 序列化时补充tag字段
+func (e *CardInput) MarshalJSON() ([]byte, error) {
+	type alias CardInput
+	return marshalCardElement("input", (*alias)(e))
+}
+
+// validate 实现CardElement接口
+func (e *CardInput) validate(path string) error {
+	if e.Name == "" {
+		return fmt.Errorf("%s: input requires name", path)
+	}
+	if e.MaxLength < 0 || e.MaxLength > 1000 {
+		return fmt.Errorf("%s: max_length must be between 1 and 1000", path)
+	}
+	return nil
+}
+
+// CardForm 表单容器，需要包含一个提交按钮
+type CardForm struct {
+	Name     string        `json:"name"`
+	Elements []CardElement `json:"elements"`
+}
+
+// MarshalJSON 序列化时补充tag字段
+func (e *CardForm) MarshalJSON() ([]byte, error) {
+	type alias CardForm
+	return marshalCardElement("form", (*alias)(e))
+}
+
+// validate 实现CardElement接口
+func (e *CardForm) validate(path string) error {
+	if e.Name == "" {
+		return fmt.Errorf("%s: form requires name", path)
+	}
+	if len(e.Elements) == 0 {
+		return fmt.Errorf("%s: form requires elements", path)
+	}
+	if err := validateCardModules(path+".elements", "form", e.Elements); err != nil {
+		return err
+	}
+
+	names := make(map[string]bool)
+	submit := false
+	var walk func(elements []CardElement) error
+	walk = func(elements []CardElement) error {
+		for _, element := range elements {
+			name := ""
+			switch v := element.(type) {
+			case *CardForm:
+				return fmt.Errorf("%s: forms cannot be nested", path)
+			case *CardInput:
+				name = v.Name
+			case *CardSelectMenu:
+				name = v.Name
+			case *CardDatePicker:
+				name = v.Name
+			case *CardButton:
+				name = v.Name
+				submit = submit || v.ActionType == "form_submit"
+			case *CardAction:
+				if err := walk(v.Actions); err != nil {
+					return err
+				}
+			case *CardColumnSet:
+				for _, column := range v.Columns {
+					if err := walk(column.Elements); err != nil {
+						return err
+					}
+				}
+			case *CardCollapsiblePanel:
+				if err := walk(v.Elements); err != nil {
+					return err
+				}
+			}
+			if name == "" {
+				continue
+			}
+			if names[name] {
+				return fmt.Errorf("%s: duplicate component name %q", path, name)
+			}
+			names[name] = true
+		}
+		return nil
+	}
+	if err := walk(e.Elements); err != nil {
+		return err
+	}
+	if !submit {
+		return fmt.Errorf("%s: form requires a button with action_type form_submit", path)
+	}
+	return nil
+}
+
+// CardPanelHeader 折叠面板标题
+type CardPanelHeader struct {
+	Title           *CardText `json:"title"`
+	BackgroundColor string    `json:"background_color,omitempty"`
+}
+
+// CardCollapsiblePanel 折叠面板
+type CardCollapsiblePanel struct {
+	Expanded bool             `json:"expanded"`
+	Header   *CardPanelHeader `json:"header"`
+	Elements []CardElement    `json:"elements"`
+}
+
+// MarshalJSON 序列化时补充tag字段
+func (e *CardCollapsiblePanel) MarshalJSON() ([]byte, error) {
+	type alias CardCollapsiblePanel
+	return marshalCardElement("collapsible_panel", (*alias)(e))
+}
+
+// validate 实现CardElement接口
+func (e *CardCollapsiblePanel) validate(path string) error {
+	if e.Header == nil {
+		return fmt.Errorf("%s: collapsible_panel requires header", path)
+	}
+	if err := validateCardText(path+".header.title", e.Header.Title); err != nil {
+		return err
+	}
+	if len(e.Elements) == 0 {
+		return fmt.Errorf("%s: collapsible_panel requires elements", path)
+	}
+	return validateCardModules(path+".elements", "collapsible_panel", e.Elements)
+}
+
+// validateTextAlign 校验对齐方式
+func validateTextAlign(path, align string) error {
+	switch align {
+	case "", "left", "center", "right":
+		return nil
+	}
+	return fmt.Errorf("%s: unknown text_align %q", path, align)
+}
@@ -0,0 +1,102 @@
+package easylark
+
+import (
+	"fmt"
+)
+
+// TemplateCard 基于卡片搭建工具中模板的卡片
+type TemplateCard struct {
+	TemplateID      string
+	TemplateVersion string
+	Variables       map[string]interface{}
+}
+
+// NewTemplateCard 创建模板卡片，version为空时使用模板最新版本
+func NewTemplateCard(templateID, version string) *TemplateCard {
+	return &TemplateCard{
+		TemplateID:      templateID,
+		TemplateVersion: version,
+		Variables:       make(map[string]interface{}),
+	}
+}
+
+// SetVariable 设置模板变量
+func (c *TemplateCard) SetVariable(name string, value interface{}) *TemplateCard {
+	if c.Variables == nil {
+		c.Variables = make(map[string]interface{})
+	}
+	c.Variables[name] = value
+	return c
+}
+
+// SetImageVariable 设置图片类型的模板变量
+func (c *TemplateCard) SetImageVariable(name, imgKey string) *TemplateCard {
+	return c.SetVariable(name, TemplateImage(imgKey))
+}
+
+// SetListVariable 设置对象数组类型的模板变量，用于循环容器和表格
+func (c *TemplateCard) SetListVariable(name string, items []map[string]interface{}) *TemplateCard {
+	if items == nil {
+		items = make([]map[string]interface{}, 0)
+	}
+	return c.SetVariable(name, items)
+}
+
+// SetImageListVariable 设置图片数组类型的模板变量，用于多图混排
+func (c *TemplateCard) SetImageListVariable(name string, imgKeys ...string) *TemplateCard {
+	images := make([]map[string]interface{}, 0, len(imgKeys))
+	for _, imgKey := range imgKeys {
+		images = append(images, TemplateImage(imgKey))
+	}
+	return c.SetVariable(name, images)
+}
+
+// TemplateImage 构造图片类型的模板变量值
+func TemplateImage(imgKey string) map[string]interface{} {
+	return map[string]interface{}{
+		"img_key": imgKey,
+	}
+}
+
+// Type 实现MessageContent接口
+func (c *TemplateCard) Type() MessageType {
+	return MessageTypeInteractive
+}
+
+// Content 实现MessageContent接口
+func (c *TemplateCard) Content() map[string]interface{} {
+	data := map[string]interface{}{
+		"template_id": c.TemplateID,
+	}
+	if c.TemplateVersion != "" {
+		data["template_version_name"] = c.TemplateVersion
+	}
+	if len(c.Variables) > 0 {
+		data["template_variable"] = c.Variables
+	}
+	return map[string]interface{}{
+		"type": "template",
+		"data": data,
+	}
+}
+
+// Validate 校验模板卡片
+func (c *TemplateCard) Validate() error {
+	if c.TemplateID == "" {
+		return fmt.Errorf("invalid template card: template_id is required")
+	}
+	for name := range c.Variables {
+		if name == "" {
+			return fmt.Errorf("invalid template card: variable name is empty")
+		}
+	}
+	return nil
+}
+
+// SendTemplateCard 发送模板卡片消息
+func (s *MessageService) SendTemplateCard(chatID string, card *TemplateCard, opts ...SendOption) error {
+	if err := card.Validate(); err != nil {
+		return err
+	}
+	return s.SendMessage(chatID, card, opts...)
+}
@@ -0,0 +1,71 @@
+package easylark
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestTemplateCardContent(t *testing.T) {
+	card := NewTemplateCard("AAqk1xxxx", "1.0.2").
+		SetVariable("title", "发布完成").
+		SetImageVariable("cover", "img_v2_123").
+		SetListVariable("services", []map[string]interface{}{{"name": "api"}, {"name": "web"}})
+
+	if err := card.Validate(); err != nil {
+		t.Fatalf("validate template card failed: %v", err)
+	}
+
+	data, err := json.Marshal(card.Content())
+	if err != nil {
+		t.Fatalf("marshal template card failed: %v", err)
+	}
+	want := `{"data":{"template_id":"AAqk1xxxx","template_variable":{"cover":{"img_key":"img_v2_123"},` +
+		`"services":[{"name":"api"},{"name":"web"}],"title":"发布完成"},"template_version_name":"1.0.2"},"type":"template"}`
+	if string(data) != want {
+		t.Errorf("Expected %s, got %s", want, data)
+	}
+
+	if err := (&TemplateCard{}).Validate(); err == nil {
+		t.Error("Expected error for template card without template_id")
+	}
+}
+
+func TestReplyAndUpdateTemplateCard(t *testing.T) {
+	var paths []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+
+		var reqBody map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		content, ok := reqBody["content"].(map[string]interface{})
+		if !ok || content["type"] != "template" {
+			t.Errorf("Expected template card content, got %v", reqBody["content"])
+		}
+		writeTestResponse(w, map[string]interface{}{"message_id": "om_reply"})
+	})
+
+	card := NewTemplateCard("AAqk1xxxx", "")
+	if err := client.Message.ReplyMessage("om_origin", card); err != nil {
+		t.Fatalf("reply message failed: %v", err)
+	}
+	if err := client.Message.UpdateCard("om_origin", card); err != nil {
+		t.Fatalf("update card failed: %v", err)
+	}
+	if err := client.Message.UpdateCard("om_origin", &TextContent{Text: "x"}); err == nil {
+		t.Error("Expected UpdateCard to reject non-card content")
+	}
+
+	want := []string{
+		"POST /open-apis/im/v1/messages/om_origin/reply",
+		"PATCH /open-apis/im/v1/messages/om_origin",
+	}
+	if len(paths) != len(want) {
+		t.Fatalf("Expected %d requests, got %v", len(want), paths)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Errorf("Expected request '%s', got '%s'", want[i], paths[i])
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package easylark
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestMessageCardContent(t *testing.T) {
+	card := NewMessageCard().
+		SetTitle("发布审批").
+		SetSubtitle("api-service").
+		SetTemplate(CardTemplateBlue).
+		SetWideScreenMode(true).
+		AddMarkdown("**版本**: v1.2.3").
+		AddHR().
+		AddElement(&CardAction{
+			Actions: []CardElement{
+				NewCardButton("同意", map[string]interface{}{"action": "approve"}),
+				NewCardSelectMenu("环境", &CardOption{Text: NewPlainText("生产"), Value: "prod"}),
+			},
+		})
+
+	if err := card.Validate(); err != nil {
+		t.Fatalf("validate card failed: %v", err)
+	}
+
+	data, err := json.Marshal(card.Content())
+	if err != nil {
+		t.Fatalf("marshal card failed: %v", err)
+	}
+
+	var content struct {
+		Config CardConfig `json:"config"`
+		Header struct {
+			Title    CardText `json:"title"`
+			Template string   `json:"template"`
+		} `json:"header"`
+		Elements []map[string]interface{} `json:"elements"`
+	}
+	if err := json.Unmarshal(data, &content); err != nil {
+		t.Fatalf("unmarshal card failed: %v", err)
+	}
+
+	if !content.Config.WideScreenMode {
+		t.Error("Expected wide_screen_mode to be true")
+	}
+	if content.Header.Title.Content != "发布审批" || content.Header.Template != "blue" {
+		t.Errorf("Unexpected header: %+v", content.Header)
+	}
+	if len(content.Elements) != 3 {
+		t.Fatalf("Expected 3 elements, got %d", len(content.Elements))
+	}
+	for i, tag := range []string{"markdown", "hr", "action"} {
+		if content.Elements[i]["tag"] != tag {
+			t.Errorf("Expected element %d tag '%s', got '%v'", i, tag, content.Elements[i]["tag"])
+		}
+	}
+	actions := content.Elements[2]["actions"].([]interface{})
+	if actions[0].(map[string]interface{})["tag"] != "button" {
+		t.Errorf("Expected first action to be button, got %v", actions[0])
+	}
+}
+
+func TestMessageCardI18n(t *testing.T) {
+	card := NewMessageCard().
+		SetI18nTitle("zh_cn", "告警").
+		SetI18nTitle("en_us", "Alert").
+		AddI18nElements("zh_cn", &CardMarkdown{Content: "服务异常"}).
+		AddI18nElements("en_us", &CardMarkdown{Content: "Service down"})
+
+	if err := card.Validate(); err != nil {
+		t.Fatalf("validate card failed: %v", err)
+	}
+	content := card.Content()
+	if _, ok := content["elements"]; ok {
+		t.Error("Expected elements to be omitted when i18n_elements is set")
+	}
+	if _, ok := content["i18n_elements"]; !ok {
+		t.Error("Expected i18n_elements to be set")
+	}
+}
+
+func TestMessageCardValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		card *MessageCard
+		want string
+	}{
+		{"empty", NewMessageCard(), "neither header nor elements"},
+		{"bad template", NewMessageCard().SetTitle("t").SetTemplate("pink"), "unknown template"},
+		{"empty markdown", NewMessageCard().AddMarkdown(""), "elements[0]: markdown requires content"},
+		{"button without text", NewMessageCard().AddElement(&CardAction{Actions: []CardElement{&CardButton{}}}), "elements[0].actions[0].text: text is required"},
+		{"markdown in action", NewMessageCard().AddElement(&CardAction{Actions: []CardElement{&CardMarkdown{Content: "x"}}}), "unsupported interactive element"},
+		{"image without key", NewMessageCard().AddElement(&CardNote{Elements: []CardElement{&CardImage{}}}), "img requires img_key"},
+		{"table unknown column", NewMessageCard().AddElement(&CardTable{
+			Columns: []*CardTableColumn{{Name: "name", DataType: "text"}},
+			Rows:    []map[string]interface{}{{"age": 1}},
+		}), "unknown column \"age\""},
+		{"form without submit", NewMessageCard().AddElement(&CardForm{
+			Name:     "f",
+			Elements: []CardElement{&CardInput{Name: "reason"}},
+		}), "form_submit"},
+		{"duplicate form names", NewMessageCard().AddElement(&CardForm{
+			Name:     "f",
+			Elements: []CardElement{&CardInput{Name: "x"}, NewCardSubmitButton("提交", "x")},
+		}), "duplicate component name"},
+		{"form in panel of form", NewMessageCard().AddElement(&CardForm{
+			Name: "f",
+			Elements: []CardElement{NewCardSubmitButton("提交", "ok"), &CardCollapsiblePanel{
+				Header:   &CardPanelHeader{Title: NewPlainText("更多")},
+				Elements: []CardElement{&CardForm{Name: "g", Elements: []CardElement{NewCardSubmitButton("提交", "ok")}}},
+			}},
+		}), "forms cannot be nested"},
+		{"duplicate names in panel", NewMessageCard().AddElement(&CardForm{
+			Name: "f",
+			Elements: []CardElement{NewCardSubmitButton("提交", "ok"), &CardCollapsiblePanel{
+				Header:   &CardPanelHeader{Title: NewPlainText("更多")},
+				Elements: []CardElement{&CardAction{Actions: []CardElement{NewCardSubmitButton("再次提交", "ok")}}},
+			}},
+		}), "duplicate component name \"ok\""},
+		{"table page size", NewMessageCard().AddElement(&CardTable{
+			Columns:  []*CardTableColumn{{Name: "name", DataType: "text"}},
+			PageSize: 11,
+		}), "page_size must not exceed 10"},
+		{"panel without title", NewMessageCard().AddElement(&CardCollapsiblePanel{
+			Header:   &CardPanelHeader{},
+			Elements: []CardElement{&CardHR{}},
+		}), "header.title"},
+		{"button at top level", NewMessageCard().AddElement(NewCardButton("ok", nil)), "elements[0]: card does not support *easylark.CardButton"},
+		{"input outside form", NewMessageCard().AddElement(&CardColumnSet{
+			Columns: []*CardColumn{{Elements: []CardElement{&CardInput{Name: "x"}}}},
+		}), "column does not support *easylark.CardInput"},
+		{"chart without type", NewMessageCard().AddElement(&CardChart{ChartSpec: map[string]interface{}{"data": 1}}), "requires type"},
+	}
+
+	for _, tt := range tests {
+		err := tt.card.Validate()
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected error containing '%s', got %v", tt.name, tt.want, err)
+		}
+	}
+
+	// 折叠面板中的提交按钮
+	form := NewMessageCard().AddElement(&CardForm{
+		Name: "f",
+		Elements: []CardElement{&CardInput{Name: "reason"}, &CardCollapsiblePanel{
+			Header:   &CardPanelHeader{Title: NewPlainText("更多")},
+			Elements: []CardElement{&CardAction{Actions: []CardElement{NewCardSubmitButton("提交", "submit")}}},
+		}},
+	})
+	if err := form.Validate(); err != nil {
+		t.Errorf("Expected submit button in panel to be found, got %v", err)
+	}
+
+	client := NewClient("test-app-id", "test-app-secret")
+	if err := client.Message.SendCard("chat123", NewMessageCard()); err == nil {
+		t.Error("Expected SendCard to reject invalid card")
+	}
+}
+
+func TestCardElementJSON(t *testing.T) {
+	tests := []struct {
+		element CardElement
+		want    string
+	}{
+		{&CardHR{}, `{"tag":"hr"}`},
+		{NewCardImage("img_1", "logo"), `{"tag":"img","img_key":"img_1","alt":{"tag":"plain_text","content":"logo"}}`},
+		{&CardDiv{Text: NewLarkMD("**hi**")}, `{"tag":"div","text":{"tag":"lark_md","content":"**hi**"}}`},
+	}
+
+	for _, tt := range tests {
+		data, err := json.Marshal(tt.element)
+		if err != nil {
+			t.Fatalf("marshal element failed: %v", err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("Expected %s, got %s", tt.want, data)
+		}
+	}
+}
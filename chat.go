@@ -0,0 +1,354 @@
+package easylark
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// ChatService 群组服务
+type ChatService struct {
+	client *Client
+}
+
+// newChatService 创建群组服务
+func newChatService(client *Client) *ChatService {
+	return &ChatService{
+		client: client,
+	}
+}
+
+// 群权限取值
+const (
+	ChatPermissionAllMembers = "all_members" // 所有群成员
+	ChatPermissionOnlyOwner  = "only_owner"  // 仅群主和群管理员
+	ChatPermissionAnyone     = "anyone"      // 任何人，用于share_card_permission等
+	ChatPermissionAllowed    = "allowed"     // 允许
+	ChatPermissionNotAllowed = "not_allowed" // 不允许
+)
+
+// 进群/退群消息可见性
+const (
+	ChatMessageVisibilityOnlyOwner  = "only_owner"
+	ChatMessageVisibilityAllMembers = "all_members"
+	ChatMessageVisibilityNotAnyone  = "not_anyone"
+)
+
+// 群模式和群类型
+const (
+	ChatModeGroup = "group" // 普通群
+
+	ChatTypePrivate = "private" // 私有群
+	ChatTypePublic  = "public"  // 公开群
+
+	GroupMessageTypeChat   = "chat"   // 对话消息
+	GroupMessageTypeThread = "thread" // 话题消息，即话题群
+)
+
+// ChatI18nNames 群名称的国际化配置
+type ChatI18nNames struct {
+	ZhCn string `json:"zh_cn,omitempty"`
+	EnUs string `json:"en_us,omitempty"`
+	JaJp string `json:"ja_jp,omitempty"`
+}
+
+// Chat 群组信息
+type Chat struct {
+	ChatID                 string         `json:"chat_id"`
+	Avatar                 string         `json:"avatar"`
+	Name                   string         `json:"name"`
+	Description            string         `json:"description"`
+	I18nNames              *ChatI18nNames `json:"i18n_names,omitempty"`
+	OwnerID                string         `json:"owner_id"`
+	OwnerIDType            string         `json:"owner_id_type"`
+	AddMemberPermission    string         `json:"add_member_permission,omitempty"`
+	ShareCardPermission    string         `json:"share_card_permission,omitempty"`
+	AtAllPermission        string         `json:"at_all_permission,omitempty"`
+	EditPermission         string         `json:"edit_permission,omitempty"`
+	ModerationPermission   string         `json:"moderation_permission,omitempty"`
+	JoinMessageVisibility  string         `json:"join_message_visibility,omitempty"`
+	LeaveMessageVisibility string         `json:"leave_message_visibility,omitempty"`
+	MembershipApproval     string         `json:"membership_approval,omitempty"`
+	ChatMode               string         `json:"chat_mode,omitempty"`
+	ChatType               string         `json:"chat_type,omitempty"`
+	ChatTag                string         `json:"chat_tag,omitempty"`
+	GroupMessageType       string         `json:"group_message_type,omitempty"`
+	ChatStatus             string         `json:"chat_status,omitempty"`
+	External               bool           `json:"external"`
+	TenantKey              string         `json:"tenant_key"`
+	UserCount              string         `json:"user_count,omitempty"`
+	BotCount               string         `json:"bot_count,omitempty"`
+	// UserManagerIDs 用户管理员，仅Get返回
+	UserManagerIDs []string `json:"user_manager_id_list,omitempty"`
+	// BotManagerIDs 机器人管理员的app_id，仅Get返回
+	BotManagerIDs []string `json:"bot_manager_id_list,omitempty"`
+}
+
+// CreateGroupRequest 创建群组请求
+type CreateGroupRequest struct {
+	Avatar                 string         `json:"avatar,omitempty"`
+	Name                   string         `json:"name"`
+	Description            string         `json:"description,omitempty"`
+	I18nNames              *ChatI18nNames `json:"i18n_names,omitempty"`
+	OwnerID                string         `json:"owner_id,omitempty"`     // 群主ID，为空时群主为机器人
+	UserIDs                []string       `json:"user_id_list,omitempty"` // 初始成员，ID类型由UserIDType指定
+	BotIDs                 []string       `json:"bot_id_list,omitempty"`  // 初始机器人成员的app_id
+	ChatMode               string         `json:"chat_mode,omitempty"`
+	ChatType               string         `json:"chat_type,omitempty"`
+	GroupMessageType       string         `json:"group_message_type,omitempty"`
+	External               bool           `json:"external,omitempty"` // 是否为外部群
+	JoinMessageVisibility  string         `json:"join_message_visibility,omitempty"`
+	LeaveMessageVisibility string         `json:"leave_message_visibility,omitempty"`
+	MembershipApproval     string         `json:"membership_approval,omitempty"`
+	AddMemberPermission    string         `json:"add_member_permission,omitempty"`
+	ShareCardPermission    string         `json:"share_card_permission,omitempty"`
+	AtAllPermission        string         `json:"at_all_permission,omitempty"`
+	EditPermission         string         `json:"edit_permission,omitempty"`
+
+	// UserIDType OwnerID和UserIDs的ID类型，默认open_id
+	UserIDType string `json:"-"`
+	// SetBotManager 是否将创建群组的机器人设为群管理员
+	SetBotManager bool `json:"-"`
+	// UUID 幂等键，相同UUID在短时间内重复创建只会创建一个群，超过50字符时按WithUUID的规则派生
+	UUID string `json:"-"`
+}
+
+// query 构造创建群组的查询参数
+func (r *CreateGroupRequest) query() url.Values {
+	values := url.Values{}
+	if r.UserIDType != "" {
+		values.Set("user_id_type", r.UserIDType)
+	}
+	if r.SetBotManager {
+		values.Set("set_bot_manager", "true")
+	}
+	if r.UUID != "" {
+		uuid := r.UUID
+		if len(uuid) > 50 {
+			uuid = deriveUUID(uuid)
+		}
+		values.Set("uuid", uuid)
+	}
+	return values
+}
+
+// Create 创建群组并返回群信息
+func (s *ChatService) Create(req *CreateGroupRequest) (*Chat, error) {
+	if req == nil {
+		return nil, fmt.Errorf("create chat failed: request is nil")
+	}
+	path := "/im/v1/chats"
+	if values := req.query(); len(values) > 0 {
+		path += "?" + values.Encode()
+	}
+
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Data *Chat  `json:"data"`
+	}
+
+	err := s.client.DoRequest("POST", path, req, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Code != 0 {
+		return nil, &Error{Code: result.Code, Message: result.Msg}
+	}
+
+	if result.Data == nil {
+		return nil, fmt.Errorf("create chat failed: empty response data")
+	}
+	return result.Data, nil
+}
+
+// Get 获取群组信息
+func (s *ChatService) Get(chatID string) (*Chat, error) {
+	return s.get(chatID, "")
+}
+
+// get 获取群组信息，idType为OwnerID的类型，为空时使用接口默认的open_id
+func (s *ChatService) get(chatID string, idType MemberIDType) (*Chat, error) {
+	path := fmt.Sprintf("/im/v1/chats/%s", chatID)
+	if idType != "" {
+		path += "?user_id_type=" + url.QueryEscape(string(idType))
+	}
+
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Data *Chat  `json:"data"`
+	}
+
+	err := s.client.DoRequest("GET", path, nil, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Code != 0 {
+		return nil, &Error{Code: result.Code, Message: result.Msg}
+	}
+
+	chat := result.Data
+	if chat == nil {
+		chat = &Chat{}
+	}
+	// 接口返回的群信息中不含chat_id
+	if chat.ChatID == "" {
+		chat.ChatID = chatID
+	}
+	return chat, nil
+}
+
+// UpdateChatRequest 更新群组请求，空字段不修改
+type UpdateChatRequest struct {
+	Avatar                 string         `json:"avatar,omitempty"`
+	Name                   string         `json:"name,omitempty"`
+	Description            string         `json:"description,omitempty"`
+	I18nNames              *ChatI18nNames `json:"i18n_names,omitempty"`
+	AddMemberPermission    string         `json:"add_member_permission,omitempty"`
+	ShareCardPermission    string         `json:"share_card_permission,omitempty"`
+	AtAllPermission        string         `json:"at_all_permission,omitempty"`
+	EditPermission         string         `json:"edit_permission,omitempty"`
+	OwnerID                string         `json:"owner_id,omitempty"`
+	JoinMessageVisibility  string         `json:"join_message_visibility,omitempty"`
+	LeaveMessageVisibility string         `json:"leave_message_visibility,omitempty"`
+	MembershipApproval     string         `json:"membership_approval,omitempty"`
+
+	// UserIDType OwnerID的ID类型，默认open_id
+	UserIDType string `json:"-"`
+}
+
+// Update 更新群组名称、描述、头像和权限等配置
+func (s *ChatService) Update(chatID string, req *UpdateChatRequest) error {
+	if req == nil {
+		return fmt.Errorf("update chat failed: request is nil")
+	}
+	path := fmt.Sprintf("/im/v1/chats/%s", chatID)
+	if req.UserIDType != "" {
+		path += "?user_id_type=" + url.QueryEscape(req.UserIDType)
+	}
+
+	var result APIResponse
+	err := s.client.DoRequest("PUT", path, req, &result)
+	if err != nil {
+		return err
+	}
+
+	if result.Code != 0 {
+		return &Error{Code: result.Code, Message: result.Msg}
+	}
+
+	return nil
+}
+
+// Dissolve 解散群组，仅群主或创建群组的机器人可操作
+func (s *ChatService) Dissolve(chatID string) error {
+	path := fmt.Sprintf("/im/v1/chats/%s", chatID)
+
+	var result APIResponse
+	err := s.client.DoRequest("DELETE", path, nil, &result)
+	if err != nil {
+		return err
+	}
+
+	if result.Code != 0 {
+		return &Error{Code: result.Code, Message: result.Msg}
+	}
+
+	return nil
+}
+
+// ListChatsRequest 分页获取群组的请求参数
+type ListChatsRequest struct {
+	PageSize   int    // 每页数量，最大100，为0时使用接口默认值
+	PageToken  string // 分页标记，第一页为空
+	SortType   string // 排序方式，ByCreateTimeAsc或ByActiveTimeDesc，仅List有效
+	UserIDType string // 返回的owner_id的ID类型，默认open_id
+}
+
+// query 构造查询参数
+func (r *ListChatsRequest) query() url.Values {
+	values := url.Values{}
+	if r == nil {
+		return values
+	}
+	if r.PageSize > 0 {
+		values.Set("page_size", strconv.Itoa(r.PageSize))
+	}
+	if r.PageToken != "" {
+		values.Set("page_token", r.PageToken)
+	}
+	if r.SortType != "" {
+		values.Set("sort_type", r.SortType)
+	}
+	if r.UserIDType != "" {
+		values.Set("user_id_type", r.UserIDType)
+	}
+	return values
+}
+
+// ChatList 分页获取的群组列表
+type ChatList struct {
+	Items     []*Chat `json:"items"`
+	PageToken string  `json:"page_token"`
+	HasMore   bool    `json:"has_more"`
+}
+
+// List 分页获取机器人所在的群组，req为空时获取第一页
+func (s *ChatService) List(req *ListChatsRequest) (*ChatList, error) {
+	return s.listChats("/im/v1/chats", req.query())
+}
+
+// ListAll 获取机器人所在的全部群组
+func (s *ChatService) ListAll() ([]*Chat, error) {
+	var chats []*Chat
+	req := &ListChatsRequest{PageSize: 100}
+	for {
+		page, err := s.List(req)
+		if err != nil {
+			return nil, err
+		}
+		chats = append(chats, page.Items...)
+		if !page.HasMore || page.PageToken == "" {
+			return chats, nil
+		}
+		req.PageToken = page.PageToken
+	}
+}
+
+// Search 按关键词搜索对用户或机器人可见的群组，结果分页返回
+func (s *ChatService) Search(query string, req *ListChatsRequest) (*ChatList, error) {
+	values := req.query()
+	values.Del("sort_type")
+	values.Set("query", query)
+	return s.listChats("/im/v1/chats/search", values)
+}
+
+// listChats 请求群组列表接口
+func (s *ChatService) listChats(path string, values url.Values) (*ChatList, error) {
+	if len(values) > 0 {
+		path += "?" + values.Encode()
+	}
+
+	var result struct {
+		Code int       `json:"code"`
+		Msg  string    `json:"msg"`
+		Data *ChatList `json:"data"`
+	}
+
+	err := s.client.DoRequest("GET", path, nil, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Code != 0 {
+		return nil, &Error{Code: result.Code, Message: result.Msg}
+	}
+
+	if result.Data == nil {
+		return &ChatList{}, nil
+	}
+	return result.Data, nil
+}
@@ -0,0 +1,369 @@
+package easylark
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// request 发送群配置相关请求，data不为空时将响应的data解析到data中
+func (s *ChatService) request(method, path string, body, data interface{}) error {
+	result := struct {
+		Code int         `json:"code"`
+		Msg  string      `json:"msg"`
+		Data interface{} `json:"data"`
+	}{Data: data}
+
+	err := s.client.DoRequest(method, path, body, &result)
+	if err != nil {
+		return err
+	}
+
+	if result.Code != 0 {
+		return &Error{Code: result.Code, Message: result.Msg}
+	}
+
+	return nil
+}
+
+// ChatAnnouncement 群公告
+type ChatAnnouncement struct {
+	Content        string `json:"content"`  // 云文档格式的公告内容
+	Revision       string `json:"revision"` // 公告版本号，更新公告时使用
+	CreateTime     string `json:"create_time"`
+	UpdateTime     string `json:"update_time"`
+	OwnerIDType    string `json:"owner_id_type"`
+	OwnerID        string `json:"owner_id"`
+	ModifierIDType string `json:"modifier_id_type"`
+	ModifierID     string `json:"modifier_id"`
+}
+
+// GetAnnouncement 获取群公告
+func (s *ChatService) GetAnnouncement(chatID string) (*ChatAnnouncement, error) {
+	path := fmt.Sprintf("/im/v1/chats/%s/announcement", chatID)
+
+	announcement := &ChatAnnouncement{}
+	if err := s.request("GET", path, nil, announcement); err != nil {
+		return nil, err
+	}
+	return announcement, nil
+}
+
+// PatchAnnouncement 更新群公告，revision为GetAnnouncement获取的版本号，requests为云文档的修改请求
+func (s *ChatService) PatchAnnouncement(chatID, revision string, requests []string) error {
+	if len(requests) == 0 {
+		return fmt.Errorf("patch announcement failed: requests is empty")
+	}
+	path := fmt.Sprintf("/im/v1/chats/%s/announcement", chatID)
+
+	reqBody := map[string]interface{}{
+		"revision": revision,
+		"requests": requests,
+	}
+	return s.request("PATCH", path, reqBody, nil)
+}
+
+// AppendAnnouncementText 在群公告末尾追加文本，每行为一个段落
+func (s *ChatService) AppendAnnouncementText(chatID, text string) error {
+	announcement, err := s.GetAnnouncement(chatID)
+	if err != nil {
+		return fmt.Errorf("append announcement failed: %w", err)
+	}
+	return s.PatchAnnouncement(chatID, announcement.Revision, []string{NewAnnouncementTextRequest(text)})
+}
+
+// NewAnnouncementTextRequest 生成在公告末尾插入文本段落的云文档修改请求，每行为一个段落
+func NewAnnouncementTextRequest(text string) string {
+	type textRun struct {
+		Text  string                 `json:"text"`
+		Style map[string]interface{} `json:"style"`
+	}
+	type element struct {
+		Type    string  `json:"type"`
+		TextRun textRun `json:"textRun"`
+	}
+	type paragraph struct {
+		Elements []element `json:"elements"`
+	}
+	type block struct {
+		Type      string    `json:"type"`
+		Paragraph paragraph `json:"paragraph"`
+	}
+
+	var blocks []block
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		blocks = append(blocks, block{
+			Type: "paragraph",
+			Paragraph: paragraph{Elements: []element{{
+				Type:    "textRun",
+				TextRun: textRun{Text: line, Style: map[string]interface{}{}},
+			}}},
+		})
+	}
+	payload, _ := json.Marshal(map[string]interface{}{"blocks": blocks})
+
+	request, _ := json.Marshal(map[string]interface{}{
+		"requestType": "InsertBlocksRequestType",
+		"insertBlocksRequest": map[string]interface{}{
+			"payload": string(payload),
+			"location": map[string]interface{}{
+				"zoneId":    "0",
+				"index":     0,
+				"endOfZone": true,
+			},
+		},
+	})
+	return string(request)
+}
+
+// 会话标签页类型
+const (
+	ChatTabTypeURL = "url" // 网页链接
+	ChatTabTypeDoc = "doc" // 云文档
+)
+
+// ChatTabContent 会话标签页内容
+type ChatTabContent struct {
+	URL string `json:"url,omitempty"`
+	Doc string `json:"doc,omitempty"`
+}
+
+// ChatTabConfig 会话标签页配置
+type ChatTabConfig struct {
+	IconKey   string `json:"icon_key,omitempty"`
+	IsBuiltIn bool   `json:"is_built_in,omitempty"`
+}
+
+// ChatTab 会话标签页
+type ChatTab struct {
+	TabID      string          `json:"tab_id,omitempty"`
+	TabName    string          `json:"tab_name,omitempty"`
+	TabType    string          `json:"tab_type"`
+	TabContent *ChatTabContent `json:"tab_content,omitempty"`
+	TabConfig  *ChatTabConfig  `json:"tab_config,omitempty"`
+}
+
+// NewURLTab 创建网页链接标签页
+func NewURLTab(name, url string) *ChatTab {
+	return &ChatTab{TabName: name, TabType: ChatTabTypeURL, TabContent: &ChatTabContent{URL: url}}
+}
+
+// NewDocTab 创建云文档标签页，docURL为云文档链接
+func NewDocTab(name, docURL string) *ChatTab {
+	return &ChatTab{TabName: name, TabType: ChatTabTypeDoc, TabContent: &ChatTabContent{Doc: docURL}}
+}
+
+// chatTabs 会话标签页接口的响应数据
+type chatTabs struct {
+	ChatTabs []*ChatTab `json:"chat_tabs"`
+}
+
+// CreateTabs 添加会话标签页，返回添加后的全部标签页
+func (s *ChatService) CreateTabs(chatID string, tabs []*ChatTab) ([]*ChatTab, error) {
+	if len(tabs) == 0 {
+		return nil, fmt.Errorf("create chat tabs failed: tabs is empty")
+	}
+	return s.tabsRequest("POST", fmt.Sprintf("/im/v1/chats/%s/chat_tabs", chatID), map[string]interface{}{
+		"chat_tabs": tabs,
+	})
+}
+
+// UpdateTabs 更新会话标签页，tabs需包含TabID，返回更新后的全部标签页
+func (s *ChatService) UpdateTabs(chatID string, tabs []*ChatTab) ([]*ChatTab, error) {
+	if len(tabs) == 0 {
+		return nil, fmt.Errorf("update chat tabs failed: tabs is empty")
+	}
+	return s.tabsRequest("POST", fmt.Sprintf("/im/v1/chats/%s/chat_tabs/update_tabs", chatID), map[string]interface{}{
+		"chat_tabs": tabs,
+	})
+}
+
+// ListTabs 获取会话标签页
+func (s *ChatService) ListTabs(chatID string) ([]*ChatTab, error) {
+	return s.tabsRequest("GET", fmt.Sprintf("/im/v1/chats/%s/chat_tabs/list_tabs", chatID), nil)
+}
+
+// SortTabs 按tabIDs的顺序排列会话标签页，返回排序后的全部标签页
+func (s *ChatService) SortTabs(chatID string, tabIDs []string) ([]*ChatTab, error) {
+	if len(tabIDs) == 0 {
+		return nil, fmt.Errorf("sort chat tabs failed: tab ids is empty")
+	}
+	return s.tabsRequest("POST", fmt.Sprintf("/im/v1/chats/%s/chat_tabs/sort_tabs", chatID), map[string]interface{}{
+		"tab_ids": tabIDs,
+	})
+}
+
+// DeleteTabs 删除会话标签页，返回删除后剩余的标签页
+func (s *ChatService) DeleteTabs(chatID string, tabIDs []string) ([]*ChatTab, error) {
+	if len(tabIDs) == 0 {
+		return nil, fmt.Errorf("delete chat tabs failed: tab ids is empty")
+	}
+	return s.tabsRequest("DELETE", fmt.Sprintf("/im/v1/chats/%s/chat_tabs/delete_tabs", chatID), map[string]interface{}{
+		"tab_ids": tabIDs,
+	})
+}
+
+// tabsRequest 请求会话标签页接口
+func (s *ChatService) tabsRequest(method, path string, body interface{}) ([]*ChatTab, error) {
+	data := &chatTabs{}
+	if err := s.request(method, path, body, data); err != nil {
+		return nil, err
+	}
+	return data.ChatTabs, nil
+}
+
+// 群菜单的动作类型
+const (
+	ChatMenuActionNone         = "NONE"          // 无动作，用于包含二级菜单的一级菜单
+	ChatMenuActionRedirectLink = "REDIRECT_LINK" // 跳转链接
+)
+
+// ChatMenuRedirectLink 群菜单跳转链接，CommonURL为各端默认链接
+type ChatMenuRedirectLink struct {
+	CommonURL  string `json:"common_url,omitempty"`
+	IOSURL     string `json:"ios_url,omitempty"`
+	AndroidURL string `json:"android_url,omitempty"`
+	PCURL      string `json:"pc_url,omitempty"`
+	WebURL     string `json:"web_url,omitempty"`
+}
+
+// ChatMenuItem 群菜单项
+type ChatMenuItem struct {
+	ActionType   string                `json:"action_type"`
+	RedirectLink *ChatMenuRedirectLink `json:"redirect_link,omitempty"`
+	ImageKey     string                `json:"image_key,omitempty"`
+	Name         string                `json:"name"`
+	I18nNames    *ChatI18nNames        `json:"i18n_names,omitempty"`
+}
+
+// ChatMenuSecondLevel 二级菜单
+type ChatMenuSecondLevel struct {
+	ID   string        `json:"chat_menu_second_level_id,omitempty"`
+	Item *ChatMenuItem `json:"chat_menu_item"`
+}
+
+// ChatMenuTopLevel 一级菜单
+type ChatMenuTopLevel struct {
+	ID       string                 `json:"chat_menu_top_level_id,omitempty"`
+	Item     *ChatMenuItem          `json:"chat_menu_item"`
+	Children []*ChatMenuSecondLevel `json:"children,omitempty"`
+}
+
+// ChatMenuTree 群菜单
+type ChatMenuTree struct {
+	TopLevels []*ChatMenuTopLevel `json:"chat_menu_top_levels"`
+}
+
+// NewChatMenu 创建一级菜单，url为空时菜单本身无动作，可通过AddChild添加二级菜单
+func NewChatMenu(name, url string) *ChatMenuTopLevel {
+	return &ChatMenuTopLevel{Item: newChatMenuItem(name, url)}
+}
+
+// AddChild 添加跳转链接的二级菜单
+func (m *ChatMenuTopLevel) AddChild(name, url string) *ChatMenuTopLevel {
+	m.Children = append(m.Children, &ChatMenuSecondLevel{Item: newChatMenuItem(name, url)})
+	return m
+}
+
+// newChatMenuItem 创建菜单项
+func newChatMenuItem(name, url string) *ChatMenuItem {
+	if url == "" {
+		return &ChatMenuItem{ActionType: ChatMenuActionNone, Name: name}
+	}
+	return &ChatMenuItem{
+		ActionType:   ChatMenuActionRedirectLink,
+		RedirectLink: &ChatMenuRedirectLink{CommonURL: url},
+		Name:         name,
+	}
+}
+
+// chatMenuTree 群菜单接口的响应数据
+type chatMenuTree struct {
+	MenuTree *ChatMenuTree `json:"menu_tree"`
+}
+
+// CreateMenu 添加群菜单，返回添加后的完整菜单
+func (s *ChatService) CreateMenu(chatID string, menus ...*ChatMenuTopLevel) (*ChatMenuTree, error) {
+	if len(menus) == 0 {
+		return nil, fmt.Errorf("create chat menu failed: menus is empty")
+	}
+	return s.menuRequest("POST", fmt.Sprintf("/im/v1/chats/%s/menu_tree", chatID), map[string]interface{}{
+		"menu_tree": &ChatMenuTree{TopLevels: menus},
+	})
+}
+
+// GetMenu 获取群菜单
+func (s *ChatService) GetMenu(chatID string) (*ChatMenuTree, error) {
+	return s.menuRequest("GET", fmt.Sprintf("/im/v1/chats/%s/menu_tree", chatID), nil)
+}
+
+// SortMenu 按topLevelIDs的顺序排列一级菜单，返回排序后的完整菜单
+func (s *ChatService) SortMenu(chatID string, topLevelIDs []string) (*ChatMenuTree, error) {
+	if len(topLevelIDs) == 0 {
+		return nil, fmt.Errorf("sort chat menu failed: menu ids is empty")
+	}
+	return s.menuRequest("POST", fmt.Sprintf("/im/v1/chats/%s/menu_tree/sort", chatID), map[string]interface{}{
+		"chat_menu_top_level_ids": topLevelIDs,
+	})
+}
+
+// DeleteMenu 删除一级菜单及其二级菜单，返回删除后的完整菜单
+func (s *ChatService) DeleteMenu(chatID string, topLevelIDs []string) (*ChatMenuTree, error) {
+	if len(topLevelIDs) == 0 {
+		return nil, fmt.Errorf("delete chat menu failed: menu ids is empty")
+	}
+	return s.menuRequest("DELETE", fmt.Sprintf("/im/v1/chats/%s/menu_tree", chatID), map[string]interface{}{
+		"chat_menu_top_level_ids": topLevelIDs,
+	})
+}
+
+// menuRequest 请求群菜单接口
+func (s *ChatService) menuRequest(method, path string, body interface{}) (*ChatMenuTree, error) {
+	data := &chatMenuTree{}
+	if err := s.request(method, path, body, data); err != nil {
+		return nil, err
+	}
+	if data.MenuTree == nil {
+		return &ChatMenuTree{}, nil
+	}
+	return data.MenuTree, nil
+}
+
+// 群置顶的类型
+const (
+	topNoticeMessage      = "1" // 置顶消息
+	topNoticeAnnouncement = "2" // 置顶群公告
+)
+
+// SetTopNotice 将消息设为群置顶
+func (s *ChatService) SetTopNotice(chatID, messageID string) error {
+	if messageID == "" {
+		return fmt.Errorf("set top notice failed: message id is empty")
+	}
+	return s.putTopNotice(chatID, map[string]interface{}{
+		"action_type": topNoticeMessage,
+		"message_id":  messageID,
+	})
+}
+
+// SetAnnouncementTopNotice 将群公告设为群置顶
+func (s *ChatService) SetAnnouncementTopNotice(chatID string) error {
+	return s.putTopNotice(chatID, map[string]interface{}{
+		"action_type": topNoticeAnnouncement,
+	})
+}
+
+// putTopNotice 设置群置顶
+func (s *ChatService) putTopNotice(chatID string, notice map[string]interface{}) error {
+	path := fmt.Sprintf("/im/v1/chats/%s/top_notice/put_top_notice", chatID)
+	reqBody := map[string]interface{}{
+		"chat_top_notice": []map[string]interface{}{notice},
+	}
+	return s.request("POST", path, reqBody, nil)
+}
+
+// RemoveTopNotice 撤销群置顶
+func (s *ChatService) RemoveTopNotice(chatID string) error {
+	path := fmt.Sprintf("/im/v1/chats/%s/top_notice/delete_top_notice", chatID)
+	return s.request("POST", path, nil, nil)
+}
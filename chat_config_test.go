@@ -0,0 +1,194 @@
+package easylark
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestChatAnnouncement(t *testing.T) {
+	var patched map[string]interface{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/open-apis/im/v1/chats/oc_1/announcement" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if r.Method == "PATCH" {
+			json.NewDecoder(r.Body).Decode(&patched)
+			writeTestResponse(w, nil)
+			return
+		}
+		writeTestResponse(w, map[string]interface{}{
+			"content":  "{}",
+			"revision": "12",
+			"owner_id": "ou_1",
+		})
+	})
+
+	announcement, err := client.Chat.GetAnnouncement("oc_1")
+	if err != nil {
+		t.Fatalf("get announcement failed: %v", err)
+	}
+	if announcement.Revision != "12" || announcement.OwnerID != "ou_1" {
+		t.Errorf("Unexpected announcement: %+v", announcement)
+	}
+
+	if err := client.Chat.AppendAnnouncementText("oc_1", "值班手册\n升级流程\n"); err != nil {
+		t.Fatalf("append announcement failed: %v", err)
+	}
+	if patched["revision"] != "12" {
+		t.Errorf("Expected revision 12, got %v", patched["revision"])
+	}
+	requests := patched["requests"].([]interface{})
+	if len(requests) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(requests))
+	}
+
+	var request struct {
+		RequestType         string `json:"requestType"`
+		InsertBlocksRequest struct {
+			Payload string `json:"payload"`
+		} `json:"insertBlocksRequest"`
+	}
+	if err := json.Unmarshal([]byte(requests[0].(string)), &request); err != nil {
+		t.Fatalf("Expected request to be json: %v", err)
+	}
+	if request.RequestType != "InsertBlocksRequestType" {
+		t.Errorf("Unexpected request type %s", request.RequestType)
+	}
+	var payload struct {
+		Blocks []interface{} `json:"blocks"`
+	}
+	json.Unmarshal([]byte(request.InsertBlocksRequest.Payload), &payload)
+	if len(payload.Blocks) != 2 || !strings.Contains(request.InsertBlocksRequest.Payload, "升级流程") {
+		t.Errorf("Expected 2 paragraphs, got %s", request.InsertBlocksRequest.Payload)
+	}
+
+	if err := client.Chat.PatchAnnouncement("oc_1", "12", nil); err == nil {
+		t.Error("Expected error for empty requests")
+	}
+}
+
+func TestChatTabs(t *testing.T) {
+	var requests []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		requests = append(requests, r.Method+" "+strings.TrimPrefix(r.URL.Path, "/open-apis/im/v1/chats/oc_1/"))
+
+		if r.URL.Path == "/open-apis/im/v1/chats/oc_1/chat_tabs" {
+			tabs := reqBody["chat_tabs"].([]interface{})
+			tab := tabs[0].(map[string]interface{})
+			content := tab["tab_content"].(map[string]interface{})
+			if tab["tab_type"] != ChatTabTypeURL || content["url"] != "https://runbook" {
+				t.Errorf("Unexpected tab: %v", tab)
+			}
+		}
+		writeTestResponse(w, map[string]interface{}{
+			"chat_tabs": []map[string]interface{}{
+				{"tab_id": "t_msg", "tab_type": "message"},
+				{"tab_id": "t_1", "tab_name": "Runbook", "tab_type": "url"},
+			},
+		})
+	})
+
+	tabs, err := client.Chat.CreateTabs("oc_1", []*ChatTab{NewURLTab("Runbook", "https://runbook"), NewDocTab("复盘", "https://doc")})
+	if err != nil {
+		t.Fatalf("create tabs failed: %v", err)
+	}
+	if len(tabs) != 2 || tabs[1].TabID != "t_1" {
+		t.Errorf("Unexpected tabs: %+v", tabs)
+	}
+	if _, err := client.Chat.ListTabs("oc_1"); err != nil {
+		t.Fatalf("list tabs failed: %v", err)
+	}
+	if _, err := client.Chat.SortTabs("oc_1", []string{"t_msg", "t_1"}); err != nil {
+		t.Fatalf("sort tabs failed: %v", err)
+	}
+	if _, err := client.Chat.DeleteTabs("oc_1", []string{"t_1"}); err != nil {
+		t.Fatalf("delete tabs failed: %v", err)
+	}
+
+	want := "POST chat_tabs|GET chat_tabs/list_tabs|POST chat_tabs/sort_tabs|DELETE chat_tabs/delete_tabs"
+	if strings.Join(requests, "|") != want {
+		t.Errorf("Expected requests %s, got %v", want, requests)
+	}
+}
+
+func TestChatMenu(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var reqBody struct {
+			MenuTree ChatMenuTree `json:"menu_tree"`
+		}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+
+		if r.Method == "POST" {
+			top := reqBody.MenuTree.TopLevels
+			if len(top) != 2 || top[0].Item.ActionType != ChatMenuActionNone || len(top[0].Children) != 2 {
+				t.Errorf("Unexpected menu tree: %+v", top)
+			}
+			if top[1].Item.RedirectLink.CommonURL != "https://status" {
+				t.Errorf("Unexpected redirect link: %+v", top[1].Item)
+			}
+		}
+		writeTestResponse(w, map[string]interface{}{
+			"menu_tree": map[string]interface{}{
+				"chat_menu_top_levels": []map[string]interface{}{
+					{"chat_menu_top_level_id": "m_1", "chat_menu_item": map[string]interface{}{"name": "文档", "action_type": "NONE"}},
+				},
+			},
+		})
+	})
+
+	tree, err := client.Chat.CreateMenu("oc_1",
+		NewChatMenu("文档", "").AddChild("Runbook", "https://runbook").AddChild("Dashboard", "https://dash"),
+		NewChatMenu("状态页", "https://status"),
+	)
+	if err != nil {
+		t.Fatalf("create menu failed: %v", err)
+	}
+	if len(tree.TopLevels) != 1 || tree.TopLevels[0].ID != "m_1" {
+		t.Errorf("Unexpected menu tree: %+v", tree)
+	}
+	if _, err := client.Chat.DeleteMenu("oc_1", []string{"m_1"}); err != nil {
+		t.Fatalf("delete menu failed: %v", err)
+	}
+	if _, err := client.Chat.CreateMenu("oc_1"); err == nil {
+		t.Error("Expected error for empty menus")
+	}
+}
+
+func TestChatTopNotice(t *testing.T) {
+	var bodies []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		data, _ := json.Marshal(map[string]interface{}{"path": r.URL.Path})
+		var reqBody map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		notice, _ := json.Marshal(reqBody["chat_top_notice"])
+		bodies = append(bodies, string(data)+string(notice))
+		writeTestResponse(w, nil)
+	})
+
+	if err := client.Chat.SetTopNotice("oc_1", "om_1"); err != nil {
+		t.Fatalf("set top notice failed: %v", err)
+	}
+	if err := client.Chat.SetAnnouncementTopNotice("oc_1"); err != nil {
+		t.Fatalf("set announcement top notice failed: %v", err)
+	}
+	if err := client.Chat.RemoveTopNotice("oc_1"); err != nil {
+		t.Fatalf("remove top notice failed: %v", err)
+	}
+
+	if len(bodies) != 3 {
+		t.Fatalf("Expected 3 requests, got %d", len(bodies))
+	}
+	if !strings.Contains(bodies[0], "put_top_notice") || !strings.Contains(bodies[0], `"action_type":"1"`) || !strings.Contains(bodies[0], `"message_id":"om_1"`) {
+		t.Errorf("Unexpected set top notice request: %s", bodies[0])
+	}
+	if !strings.Contains(bodies[1], `"action_type":"2"`) {
+		t.Errorf("Unexpected announcement top notice request: %s", bodies[1])
+	}
+	if !strings.Contains(bodies[2], "delete_top_notice") {
+		t.Errorf("Unexpected remove top notice request: %s", bodies[2])
+	}
+}
@@ -0,0 +1,177 @@
+package easylark
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// 群发言权限
+const (
+	ModerationAllMembers    = "all_members"    // 所有群成员可发言
+	ModerationOnlyOwner     = "only_owner"     // 仅群主和群管理员可发言
+	ModerationModeratorList = "moderator_list" // 仅指定的群成员可发言
+)
+
+// ChatManagers 群管理员
+type ChatManagers struct {
+	ChatManagers    []string `json:"chat_managers"`     // 用户管理员
+	ChatBotManagers []string `json:"chat_bot_managers"` // 机器人管理员
+}
+
+// AddManagers 指定群管理员，idType为空时使用open_id，指定机器人时使用MemberIDTypeAppID
+func (s *ChatService) AddManagers(chatID string, ids []string, idType MemberIDType) (*ChatManagers, error) {
+	return s.changeManagers(chatID, "add_managers", ids, idType)
+}
+
+// RemoveManagers 删除群管理员，idType为空时使用open_id
+func (s *ChatService) RemoveManagers(chatID string, ids []string, idType MemberIDType) (*ChatManagers, error) {
+	return s.changeManagers(chatID, "delete_managers", ids, idType)
+}
+
+// changeManagers 指定或删除群管理员，返回操作后的管理员列表
+func (s *ChatService) changeManagers(chatID, action string, ids []string, idType MemberIDType) (*ChatManagers, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("change chat managers failed: id list is empty")
+	}
+	if idType == "" {
+		idType = MemberIDTypeOpenID
+	}
+	path := fmt.Sprintf("/im/v1/chats/%s/managers/%s?member_id_type=%s", chatID, action, url.QueryEscape(string(idType)))
+
+	reqBody := map[string]interface{}{
+		"manager_ids": ids,
+	}
+
+	var result struct {
+		Code int          `json:"code"`
+		Msg  string       `json:"msg"`
+		Data ChatManagers `json:"data"`
+	}
+	err := s.client.DoRequest("POST", path, reqBody, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Code != 0 {
+		return nil, &Error{Code: result.Code, Message: result.Msg}
+	}
+
+	return &result.Data, nil
+}
+
+// TransferOwner 转让群主，idType为空时使用open_id
+func (s *ChatService) TransferOwner(chatID, ownerID string, idType MemberIDType) error {
+	if ownerID == "" {
+		return fmt.Errorf("transfer chat owner failed: owner id is empty")
+	}
+	return s.Update(chatID, &UpdateChatRequest{
+		OwnerID:    ownerID,
+		UserIDType: string(idType),
+	})
+}
+
+// ChatModerator 可发言的群成员
+type ChatModerator struct {
+	UserIDType string `json:"user_id_type"`
+	UserID     string `json:"user_id"`
+	TenantKey  string `json:"tenant_key"`
+}
+
+// ChatModeration 群发言权限设置
+type ChatModeration struct {
+	Setting    string           // 发言权限，取值见Moderation*常量
+	Moderators []*ChatModerator // Setting为ModerationModeratorList时可发言的成员
+}
+
+// GetModeration 获取群发言权限和可发言的成员，idType为空时使用open_id
+func (s *ChatService) GetModeration(chatID string, idType MemberIDType) (*ChatModeration, error) {
+	if idType == "" {
+		idType = MemberIDTypeOpenID
+	}
+
+	moderation := &ChatModeration{}
+	pageToken := ""
+	for {
+		values := url.Values{}
+		values.Set("user_id_type", string(idType))
+		values.Set("page_size", strconv.Itoa(100))
+		if pageToken != "" {
+			values.Set("page_token", pageToken)
+		}
+		path := fmt.Sprintf("/im/v1/chats/%s/moderation?%s", chatID, values.Encode())
+
+		var result struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+			Data struct {
+				ModerationSetting string           `json:"moderation_setting"`
+				PageToken         string           `json:"page_token"`
+				HasMore           bool             `json:"has_more"`
+				Items             []*ChatModerator `json:"items"`
+			} `json:"data"`
+		}
+		err := s.client.DoRequest("GET", path, nil, &result)
+		if err != nil {
+			return nil, err
+		}
+
+		if result.Code != 0 {
+			return nil, &Error{Code: result.Code, Message: result.Msg}
+		}
+
+		moderation.Setting = result.Data.ModerationSetting
+		moderation.Moderators = append(moderation.Moderators, result.Data.Items...)
+		if !result.Data.HasMore || result.Data.PageToken == "" {
+			return moderation, nil
+		}
+		pageToken = result.Data.PageToken
+	}
+}
+
+// SetModeration 设置群发言权限，setting取值见Moderation*常量
+func (s *ChatService) SetModeration(chatID, setting string) error {
+	if setting == "" {
+		return fmt.Errorf("set chat moderation failed: setting is empty")
+	}
+	return s.updateModeration(chatID, map[string]interface{}{
+		"moderation_setting": setting,
+	}, "")
+}
+
+// UpdateModerators 将发言权限设为仅指定成员可发言，并增减可发言的成员，idType为空时使用open_id
+func (s *ChatService) UpdateModerators(chatID string, added, removed []string, idType MemberIDType) error {
+	if len(added) == 0 && len(removed) == 0 {
+		return fmt.Errorf("update chat moderators failed: no moderator to add or remove")
+	}
+	reqBody := map[string]interface{}{
+		"moderation_setting": ModerationModeratorList,
+	}
+	if len(added) > 0 {
+		reqBody["moderator_added_list"] = added
+	}
+	if len(removed) > 0 {
+		reqBody["moderator_removed_list"] = removed
+	}
+	return s.updateModeration(chatID, reqBody, idType)
+}
+
+// updateModeration 更新群发言权限
+func (s *ChatService) updateModeration(chatID string, reqBody map[string]interface{}, idType MemberIDType) error {
+	if idType == "" {
+		idType = MemberIDTypeOpenID
+	}
+	path := fmt.Sprintf("/im/v1/chats/%s/moderation?user_id_type=%s", chatID, url.QueryEscape(string(idType)))
+
+	var result APIResponse
+	err := s.client.DoRequest("PUT", path, reqBody, &result)
+	if err != nil {
+		return err
+	}
+
+	if result.Code != 0 {
+		return &Error{Code: result.Code, Message: result.Msg}
+	}
+
+	return nil
+}
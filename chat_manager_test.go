@@ -0,0 +1,123 @@
+package easylark
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestChatManagers(t *testing.T) {
+	var requests []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var reqBody struct {
+			ManagerIDs []string `json:"manager_ids"`
+		}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		requests = append(requests, r.Method+" "+r.URL.RequestURI()+" "+strings.Join(reqBody.ManagerIDs, ","))
+		writeTestResponse(w, map[string]interface{}{
+			"chat_managers":     []string{"ou_1"},
+			"chat_bot_managers": []string{"cli_bot"},
+		})
+	})
+
+	managers, err := client.Chat.AddManagers("oc_1", []string{"ou_1"}, "")
+	if err != nil {
+		t.Fatalf("add managers failed: %v", err)
+	}
+	if len(managers.ChatManagers) != 1 || managers.ChatBotManagers[0] != "cli_bot" {
+		t.Errorf("Unexpected managers: %+v", managers)
+	}
+	if _, err := client.Chat.RemoveManagers("oc_1", []string{"cli_bot"}, MemberIDTypeAppID); err != nil {
+		t.Fatalf("remove managers failed: %v", err)
+	}
+	if _, err := client.Chat.AddManagers("oc_1", nil, ""); err == nil {
+		t.Error("Expected error for empty id list")
+	}
+
+	want := []string{
+		"POST /open-apis/im/v1/chats/oc_1/managers/add_managers?member_id_type=open_id ou_1",
+		"POST /open-apis/im/v1/chats/oc_1/managers/delete_managers?member_id_type=app_id cli_bot",
+	}
+	if strings.Join(requests, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected requests %v, got %v", want, requests)
+	}
+}
+
+func TestTransferOwner(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.RequestURI() != "/open-apis/im/v1/chats/oc_1?user_id_type=user_id" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.RequestURI())
+		}
+		var reqBody map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		if len(reqBody) != 1 || reqBody["owner_id"] != "u_commander" {
+			t.Errorf("Expected only owner_id in body, got %v", reqBody)
+		}
+		writeTestResponse(w, nil)
+	})
+
+	if err := client.Chat.TransferOwner("oc_1", "u_commander", MemberIDTypeUserID); err != nil {
+		t.Fatalf("transfer owner failed: %v", err)
+	}
+	if err := client.Chat.TransferOwner("oc_1", "", ""); err == nil {
+		t.Error("Expected error for empty owner id")
+	}
+}
+
+func TestChatModeration(t *testing.T) {
+	var updates []map[string]interface{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/open-apis/im/v1/chats/oc_1/moderation" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if r.Method == "PUT" {
+			var reqBody map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&reqBody)
+			updates = append(updates, reqBody)
+			writeTestResponse(w, nil)
+			return
+		}
+		if r.URL.Query().Get("page_token") == "" {
+			writeTestResponse(w, map[string]interface{}{
+				"moderation_setting": ModerationModeratorList,
+				"page_token":         "p2",
+				"has_more":           true,
+				"items":              []map[string]interface{}{{"user_id_type": "open_id", "user_id": "ou_1"}},
+			})
+			return
+		}
+		writeTestResponse(w, map[string]interface{}{
+			"moderation_setting": ModerationModeratorList,
+			"items":              []map[string]interface{}{{"user_id_type": "open_id", "user_id": "ou_2"}},
+		})
+	})
+
+	moderation, err := client.Chat.GetModeration("oc_1", "")
+	if err != nil {
+		t.Fatalf("get moderation failed: %v", err)
+	}
+	if moderation.Setting != ModerationModeratorList || len(moderation.Moderators) != 2 || moderation.Moderators[1].UserID != "ou_2" {
+		t.Errorf("Unexpected moderation: %+v", moderation)
+	}
+
+	if err := client.Chat.SetModeration("oc_1", ModerationOnlyOwner); err != nil {
+		t.Fatalf("set moderation failed: %v", err)
+	}
+	if err := client.Chat.UpdateModerators("oc_1", []string{"ou_3"}, []string{"ou_1"}, ""); err != nil {
+		t.Fatalf("update moderators failed: %v", err)
+	}
+	if err := client.Chat.UpdateModerators("oc_1", nil, nil, ""); err == nil {
+		t.Error("Expected error when no moderator changes")
+	}
+
+	if len(updates) != 2 {
+		t.Fatalf("Expected 2 updates, got %d", len(updates))
+	}
+	if updates[0]["moderation_setting"] != ModerationOnlyOwner || len(updates[0]) != 1 {
+		t.Errorf("Unexpected moderation update: %v", updates[0])
+	}
+	if updates[1]["moderation_setting"] != ModerationModeratorList || updates[1]["moderator_added_list"] == nil || updates[1]["moderator_removed_list"] == nil {
+		t.Errorf("Unexpected moderators update: %v", updates[1])
+	}
+}
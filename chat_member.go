@@ -0,0 +1,398 @@
+package easylark
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// MemberIDType 群成员ID的类型
+type MemberIDType string
+
+// 群成员ID类型
+const (
+	MemberIDTypeOpenID  MemberIDType = "open_id"
+	MemberIDTypeUserID  MemberIDType = "user_id"
+	MemberIDTypeUnionID MemberIDType = "union_id"
+	MemberIDTypeAppID   MemberIDType = "app_id" // 仅用于添加机器人成员
+)
+
+// memberBatchSize 单次添加或移除群成员的数量上限
+const memberBatchSize = 50
+
+// ChatMember 群成员
+type ChatMember struct {
+	MemberIDType string `json:"member_id_type"`
+	MemberID     string `json:"member_id"`
+	Name         string `json:"name"`
+	TenantKey    string `json:"tenant_key"`
+}
+
+// ChatMemberIterator 群成员迭代器，按需分页请求
+//
+//	it := client.Chat.ListMembers(chatID, easylark.MemberIDTypeOpenID)
+//	for it.Next() {
+//		member := it.Member()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type ChatMemberIterator struct {
+	service *ChatService
+	chatID  string
+	idType  MemberIDType
+
+	page      []*ChatMember
+	index     int
+	pageToken string
+	started   bool
+	hasMore   bool
+	total     int
+	member    *ChatMember
+	err       error
+}
+
+// ListMembers 获取群成员迭代器，idType为空时使用open_id，结果不包含机器人
+func (s *ChatService) ListMembers(chatID string, idType MemberIDType) *ChatMemberIterator {
+	if idType == "" {
+		idType = MemberIDTypeOpenID
+	}
+	return &ChatMemberIterator{
+		service: s,
+		chatID:  chatID,
+		idType:  idType,
+	}
+}
+
+// Next 移动到下一个成员，没有更多成员或出错时返回false
+func (it *ChatMemberIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	for it.index >= len(it.page) {
+		if it.started && !it.hasMore {
+			it.member = nil
+			return false
+		}
+		if err := it.fetch(); err != nil {
+			it.err = err
+			it.member = nil
+			return false
+		}
+	}
+	it.member = it.page[it.index]
+	it.index++
+	return true
+}
+
+// Member 获取当前成员
+func (it *ChatMemberIterator) Member() *ChatMember {
+	return it.member
+}
+
+// Err 获取迭代过程中的错误
+func (it *ChatMemberIterator) Err() error {
+	return it.err
+}
+
+// Total 获取群成员总数，在第一次调用Next后有效
+func (it *ChatMemberIterator) Total() int {
+	return it.total
+}
+
+// fetch 请求下一页成员
+func (it *ChatMemberIterator) fetch() error {
+	values := url.Values{}
+	values.Set("member_id_type", string(it.idType))
+	values.Set("page_size", strconv.Itoa(100))
+	if it.pageToken != "" {
+		values.Set("page_token", it.pageToken)
+	}
+	path := fmt.Sprintf("/im/v1/chats/%s/members?%s", it.chatID, values.Encode())
+
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			Items       []*ChatMember `json:"items"`
+			PageToken   string        `json:"page_token"`
+			HasMore     bool          `json:"has_more"`
+			MemberTotal int           `json:"member_total"`
+		} `json:"data"`
+	}
+
+	err := it.service.client.DoRequest("GET", path, nil, &result)
+	if err != nil {
+		return err
+	}
+
+	if result.Code != 0 {
+		return &Error{Code: result.Code, Message: result.Msg}
+	}
+
+	it.started = true
+	it.page = result.Data.Items
+	it.index = 0
+	it.pageToken = result.Data.PageToken
+	it.hasMore = result.Data.HasMore && result.Data.PageToken != ""
+	it.total = result.Data.MemberTotal
+	return nil
+}
+
+// ListAllMembers 获取全部群成员
+func (s *ChatService) ListAllMembers(chatID string, idType MemberIDType) ([]*ChatMember, error) {
+	var members []*ChatMember
+	it := s.ListMembers(chatID, idType)
+	for it.Next() {
+		members = append(members, it.Member())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// IsInChat 判断机器人是否在群中
+func (s *ChatService) IsInChat(chatID string) (bool, error) {
+	path := fmt.Sprintf("/im/v1/chats/%s/members/is_in_chat", chatID)
+
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			IsInChat bool `json:"is_in_chat"`
+		} `json:"data"`
+	}
+
+	err := s.client.DoRequest("GET", path, nil, &result)
+	if err != nil {
+		return false, err
+	}
+
+	if result.Code != 0 {
+		return false, &Error{Code: result.Code, Message: result.Msg}
+	}
+
+	return result.Data.IsInChat, nil
+}
+
+// MemberChangeResult 添加或移除群成员的结果，部分ID失败时接口仍返回成功
+type MemberChangeResult struct {
+	InvalidIDs         []string `json:"invalid_id_list"`          // 无效的ID
+	NotExistedIDs      []string `json:"not_existed_id_list"`      // 不存在的ID，仅添加时返回
+	PendingApprovalIDs []string `json:"pending_approval_id_list"` // 等待群主或管理员审批的ID，仅添加时返回
+}
+
+// Failed 获取添加或移除失败的ID，不含等待审批的ID
+func (r *MemberChangeResult) Failed() []string {
+	failed := make([]string, 0, len(r.InvalidIDs)+len(r.NotExistedIDs))
+	failed = append(failed, r.InvalidIDs...)
+	return append(failed, r.NotExistedIDs...)
+}
+
+// merge 合并分批请求的结果
+func (r *MemberChangeResult) merge(other *MemberChangeResult) {
+	r.InvalidIDs = append(r.InvalidIDs, other.InvalidIDs...)
+	r.NotExistedIDs = append(r.NotExistedIDs, other.NotExistedIDs...)
+	r.PendingApprovalIDs = append(r.PendingApprovalIDs, other.PendingApprovalIDs...)
+}
+
+// AddMembers 添加群成员，idType为空时使用open_id，添加机器人时使用MemberIDTypeAppID
+//
+// 超过50个ID时分批请求；不可用的ID不会导致整体失败，而是记录在返回结果中。
+func (s *ChatService) AddMembers(chatID string, ids []string, idType MemberIDType) (*MemberChangeResult, error) {
+	// succeed_type=1 将可用的ID全部拉入群聊，并返回不可用的ID
+	return s.changeMembers("POST", chatID, ids, idType, "succeed_type=1")
+}
+
+// RemoveMembers 移除群成员，idType为空时使用open_id，移除机器人时使用MemberIDTypeAppID
+func (s *ChatService) RemoveMembers(chatID string, ids []string, idType MemberIDType) (*MemberChangeResult, error) {
+	return s.changeMembers("DELETE", chatID, ids, idType, "")
+}
+
+// changeMembers 分批添加或移除群成员
+func (s *ChatService) changeMembers(method, chatID string, ids []string, idType MemberIDType, extraQuery string) (*MemberChangeResult, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("change chat members failed: id list is empty")
+	}
+	if idType == "" {
+		idType = MemberIDTypeOpenID
+	}
+	path := fmt.Sprintf("/im/v1/chats/%s/members?member_id_type=%s", chatID, url.QueryEscape(string(idType)))
+	if extraQuery != "" {
+		path += "&" + extraQuery
+	}
+
+	merged := &MemberChangeResult{}
+	for _, batch := range chunkIDs(ids, memberBatchSize) {
+		reqBody := map[string]interface{}{
+			"id_list": batch,
+		}
+
+		var result struct {
+			Code int                `json:"code"`
+			Msg  string             `json:"msg"`
+			Data MemberChangeResult `json:"data"`
+		}
+		err := s.client.DoRequest(method, path, reqBody, &result)
+		if err != nil {
+			return merged, err
+		}
+
+		if result.Code != 0 {
+			return merged, &Error{Code: result.Code, Message: result.Msg}
+		}
+		merged.merge(&result.Data)
+	}
+	return merged, nil
+}
+
+// MemberSyncResult 同步群成员的结果
+type MemberSyncResult struct {
+	Added   []string // 新加入的成员，不含失败和等待审批的成员
+	Removed []string // 被移除的成员，不含失败的成员
+
+	AddResult    *MemberChangeResult // 添加成员的原始结果
+	RemoveResult *MemberChangeResult // 移除成员的原始结果
+}
+
+// SyncMembers 将群成员同步为desired，idType为desired中ID的类型，为空时使用open_id
+//
+// 先获取当前成员并计算差异，再分批添加和移除成员；
+// 群主和机器人自身不会被移除；desired为空时返回错误，避免误将群成员全部移除。
+func (s *ChatService) SyncMembers(chatID string, desired []string, idType MemberIDType) (*MemberSyncResult, error) {
+	if len(desired) == 0 {
+		return nil, fmt.Errorf("sync members failed: desired members is empty")
+	}
+	if idType == "" {
+		idType = MemberIDTypeOpenID
+	}
+
+	members, err := s.ListAllMembers(chatID, idType)
+	if err != nil {
+		return nil, fmt.Errorf("sync members failed: %w", err)
+	}
+	keep, err := s.protectedMembers(chatID, idType)
+	if err != nil {
+		return nil, fmt.Errorf("sync members failed: %w", err)
+	}
+
+	toAdd, toRemove := diffMembers(members, desired, keep...)
+	result := &MemberSyncResult{}
+	if len(toAdd) > 0 {
+		result.AddResult, err = s.AddMembers(chatID, toAdd, idType)
+		if err != nil {
+			return result, fmt.Errorf("sync members failed: add members: %w", err)
+		}
+		result.Added = excludeIDs(toAdd, result.AddResult.Failed(), result.AddResult.PendingApprovalIDs)
+	}
+	if len(toRemove) > 0 {
+		result.RemoveResult, err = s.RemoveMembers(chatID, toRemove, idType)
+		if err != nil {
+			return result, fmt.Errorf("sync members failed: remove members: %w", err)
+		}
+		result.Removed = excludeIDs(toRemove, result.RemoveResult.Failed())
+	}
+	return result, nil
+}
+
+// protectedMembers 获取同步时不能移除的成员：群主和机器人自身
+func (s *ChatService) protectedMembers(chatID string, idType MemberIDType) ([]string, error) {
+	chat, err := s.get(chatID, idType)
+	if err != nil {
+		return nil, err
+	}
+	keep := []string{chat.OwnerID}
+	// 机器人只有open_id
+	if idType == MemberIDTypeOpenID {
+		botID, err := s.botOpenID()
+		if err != nil {
+			return nil, err
+		}
+		keep = append(keep, botID)
+	}
+	return keep, nil
+}
+
+// botOpenID 获取当前机器人的open_id
+func (s *ChatService) botOpenID() (string, error) {
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Bot  struct {
+			OpenID string `json:"open_id"`
+		} `json:"bot"`
+	}
+
+	err := s.client.DoRequest("GET", "/bot/v3/info", nil, &result)
+	if err != nil {
+		return "", err
+	}
+
+	if result.Code != 0 {
+		return "", &Error{Code: result.Code, Message: result.Msg}
+	}
+
+	return result.Bot.OpenID, nil
+}
+
+// excludeIDs 从ids中排除excluded中的ID
+func excludeIDs(ids []string, excluded ...[]string) []string {
+	skip := make(map[string]bool)
+	for _, list := range excluded {
+		for _, id := range list {
+			skip[id] = true
+		}
+	}
+	kept := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !skip[id] {
+			kept = append(kept, id)
+		}
+	}
+	return kept
+}
+
+// diffMembers 计算需要加入和移除的成员，保持desired和当前成员的顺序
+//
+// keep中的成员和机器人成员不会被移除。
+func diffMembers(members []*ChatMember, desired []string, keep ...string) (toAdd, toRemove []string) {
+	current := make(map[string]bool, len(members))
+	for _, member := range members {
+		current[member.MemberID] = true
+	}
+	want := make(map[string]bool, len(desired))
+	for _, id := range desired {
+		if id == "" || want[id] {
+			continue
+		}
+		want[id] = true
+		if !current[id] {
+			toAdd = append(toAdd, id)
+		}
+	}
+	for _, id := range keep {
+		if id != "" {
+			want[id] = true
+		}
+	}
+	for _, member := range members {
+		if !want[member.MemberID] && member.MemberIDType != string(MemberIDTypeAppID) {
+			toRemove = append(toRemove, member.MemberID)
+		}
+	}
+	return toAdd, toRemove
+}
+
+// chunkIDs 将ID列表按size分批
+func chunkIDs(ids []string, size int) [][]string {
+	var batches [][]string
+	for len(ids) > size {
+		batches = append(batches, ids[:size])
+		ids = ids[size:]
+	}
+	if len(ids) > 0 {
+		batches = append(batches, ids)
+	}
+	return batches
+}
@@ -0,0 +1,221 @@
+package easylark
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// memberPages 按page_token返回的群成员分页
+var memberPages = map[string]map[string]interface{}{
+	"": {
+		"items": []map[string]interface{}{
+			{"member_id_type": "open_id", "member_id": "ou_a", "name": "A"},
+			{"member_id_type": "open_id", "member_id": "ou_b", "name": "B"},
+		},
+		"page_token":   "p2",
+		"has_more":     true,
+		"member_total": 3,
+	},
+	"p2": {
+		"items": []map[string]interface{}{
+			{"member_id_type": "open_id", "member_id": "ou_c", "name": "C"},
+		},
+		"has_more":     false,
+		"member_total": 3,
+	},
+}
+
+func TestListMembers(t *testing.T) {
+	requests := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/open-apis/im/v1/chats/oc_1/members" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("member_id_type") != "open_id" {
+			t.Errorf("Expected open_id by default, got %s", r.URL.Query().Get("member_id_type"))
+		}
+		writeTestResponse(w, memberPages[r.URL.Query().Get("page_token")])
+	})
+
+	it := client.Chat.ListMembers("oc_1", "")
+	var names []string
+	for it.Next() {
+		names = append(names, it.Member().Name)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("list members failed: %v", err)
+	}
+	if strings.Join(names, ",") != "A,B,C" || it.Total() != 3 {
+		t.Errorf("Unexpected members %v, total %d", names, it.Total())
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 page requests, got %d", requests)
+	}
+	if it.Next() {
+		t.Error("Expected exhausted iterator to stay exhausted")
+	}
+}
+
+func TestListMembersError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":232011,"msg":"bot not in chat"}`))
+	})
+
+	it := client.Chat.ListMembers("oc_1", MemberIDTypeUserID)
+	if it.Next() {
+		t.Error("Expected Next to fail")
+	}
+	if e, ok := it.Err().(*Error); !ok || e.Code != 232011 {
+		t.Errorf("Expected API error, got %v", it.Err())
+	}
+}
+
+func TestIsInChat(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/open-apis/im/v1/chats/oc_1/members/is_in_chat" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		writeTestResponse(w, map[string]interface{}{"is_in_chat": true})
+	})
+
+	in, err := client.Chat.IsInChat("oc_1")
+	if err != nil || !in {
+		t.Errorf("Expected bot in chat, got %v, %v", in, err)
+	}
+}
+
+// newSyncMembersTestClient 创建同步群成员的测试客户端，记录添加和移除的ID
+func newSyncMembersTestClient(t *testing.T, ownerID, botID string) (*Client, *[]string, *[]string) {
+	var added, removed []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var reqBody struct {
+			IDList []string `json:"id_list"`
+		}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		switch {
+		case r.URL.Path == "/open-apis/bot/v3/info":
+			w.Write([]byte(`{"code":0,"msg":"ok","bot":{"open_id":"` + botID + `"}}`))
+		case r.URL.Path == "/open-apis/im/v1/chats/oc_1":
+			writeTestResponse(w, map[string]interface{}{"owner_id": ownerID, "owner_id_type": r.URL.Query().Get("user_id_type")})
+		case r.Method == "GET":
+			writeTestResponse(w, memberPages[r.URL.Query().Get("page_token")])
+		case r.Method == "POST":
+			added = append(added, reqBody.IDList...)
+			writeTestResponse(w, map[string]interface{}{"invalid_id_list": []string{"ou_x"}})
+		case r.Method == "DELETE":
+			removed = append(removed, reqBody.IDList...)
+			writeTestResponse(w, nil)
+		}
+	})
+	return client, &added, &removed
+}
+
+func TestSyncMembers(t *testing.T) {
+	client, added, removed := newSyncMembersTestClient(t, "ou_owner", "ou_bot")
+
+	result, err := client.Chat.SyncMembers("oc_1", []string{"ou_b", "ou_d", "ou_c", "ou_d", "ou_x"}, "")
+	if err != nil {
+		t.Fatalf("sync members failed: %v", err)
+	}
+	if strings.Join(*added, ",") != "ou_d,ou_x" {
+		t.Errorf("Expected ou_d and ou_x to be requested, got %v", *added)
+	}
+	if strings.Join(result.Added, ",") != "ou_d" {
+		t.Errorf("Expected only ou_d to be added, got %v", result.Added)
+	}
+	if strings.Join(result.AddResult.InvalidIDs, ",") != "ou_x" {
+		t.Errorf("Expected ou_x to be reported invalid, got %v", result.AddResult.InvalidIDs)
+	}
+	if strings.Join(result.Removed, ",") != "ou_a" || strings.Join(*removed, ",") != "ou_a" {
+		t.Errorf("Expected ou_a to be removed, got %v / %v", result.Removed, *removed)
+	}
+
+	if _, err := client.Chat.SyncMembers("oc_1", nil, ""); err == nil {
+		t.Error("Expected error for empty desired members")
+	}
+}
+
+func TestSyncMembersKeepsOwnerAndBot(t *testing.T) {
+	client, _, removed := newSyncMembersTestClient(t, "ou_b", "ou_c")
+	result, err := client.Chat.SyncMembers("oc_1", []string{"ou_d"}, "")
+	if err != nil {
+		t.Fatalf("sync members failed: %v", err)
+	}
+	if strings.Join(*removed, ",") != "ou_a" || strings.Join(result.Removed, ",") != "ou_a" {
+		t.Errorf("Expected owner and bot to be kept, removed %v", *removed)
+	}
+
+	members := []*ChatMember{{MemberID: "ou_a", MemberIDType: "open_id"}, {MemberID: "cli_1", MemberIDType: "app_id"}}
+	if _, toRemove := diffMembers(members, []string{"ou_d"}); strings.Join(toRemove, ",") != "ou_a" {
+		t.Errorf("Expected bot members to be kept, got %v", toRemove)
+	}
+}
+
+func TestAddAndRemoveMembers(t *testing.T) {
+	var requests []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var reqBody struct {
+			IDList []string `json:"id_list"`
+		}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		requests = append(requests, fmt.Sprintf("%s %s %d", r.Method, r.URL.RawQuery, len(reqBody.IDList)))
+
+		if r.Method == "POST" {
+			writeTestResponse(w, map[string]interface{}{
+				"invalid_id_list":          []string{"u_bad"},
+				"not_existed_id_list":      []string{"u_gone"},
+				"pending_approval_id_list": []string{"u_wait"},
+			})
+			return
+		}
+		writeTestResponse(w, map[string]interface{}{"invalid_id_list": []string{"u_bad"}})
+	})
+
+	ids := make([]string, 60)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("u_%d", i)
+	}
+	result, err := client.Chat.AddMembers("oc_1", ids, MemberIDTypeUserID)
+	if err != nil {
+		t.Fatalf("add members failed: %v", err)
+	}
+	if len(result.InvalidIDs) != 2 || len(result.PendingApprovalIDs) != 2 || len(result.Failed()) != 4 {
+		t.Errorf("Expected merged batch results, got %+v", result)
+	}
+
+	result, err = client.Message.RemoveGroupMember("oc_1", []string{"u_1", "u_bad"}, "")
+	if err != nil {
+		t.Fatalf("remove members failed: %v", err)
+	}
+	if strings.Join(result.Failed(), ",") != "u_bad" {
+		t.Errorf("Expected u_bad to fail, got %v", result.Failed())
+	}
+
+	want := []string{
+		"POST member_id_type=user_id&succeed_type=1 50",
+		"POST member_id_type=user_id&succeed_type=1 10",
+		"DELETE member_id_type=open_id 2",
+	}
+	if strings.Join(requests, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected requests %v, got %v", want, requests)
+	}
+
+	if _, err := client.Chat.AddMembers("oc_1", nil, ""); err == nil {
+		t.Error("Expected error for empty id list")
+	}
+}
+
+func TestChunkIDs(t *testing.T) {
+	ids := make([]string, 120)
+	batches := chunkIDs(ids, 50)
+	if len(batches) != 3 || len(batches[0]) != 50 || len(batches[2]) != 20 {
+		t.Errorf("Unexpected batches: %d", len(batches))
+	}
+	if chunkIDs(nil, 50) != nil {
+		t.Error("Expected no batches for empty ids")
+	}
+}
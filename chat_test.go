@@ -0,0 +1,201 @@
+package easylark
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestChatGet(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/open-apis/im/v1/chats/oc_1" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		writeTestResponse(w, map[string]interface{}{
+			"name":          "测试群组",
+			"owner_id":      "ou_owner",
+			"owner_id_type": "open_id",
+			"chat_mode":     "group",
+			"external":      true,
+			"user_count":    "3",
+			"i18n_names":    map[string]interface{}{"en_us": "Test"},
+		})
+	})
+
+	chat, err := client.Chat.Get("oc_1")
+	if err != nil {
+		t.Fatalf("get chat failed: %v", err)
+	}
+	if chat.ChatID != "oc_1" || chat.Name != "测试群组" || chat.OwnerID != "ou_owner" {
+		t.Errorf("Unexpected chat: %+v", chat)
+	}
+	if !chat.External || chat.UserCount != "3" || chat.I18nNames.EnUs != "Test" {
+		t.Errorf("Unexpected chat: %+v", chat)
+	}
+
+	info, err := client.Message.GetGroupInfo("oc_1")
+	if err != nil || info.Name != "测试群组" {
+		t.Errorf("Expected GetGroupInfo to return typed chat, got %+v, %v", info, err)
+	}
+}
+
+func TestChatUpdateAndDissolve(t *testing.T) {
+	var requests []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		if r.Method == "PUT" {
+			var reqBody map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&reqBody)
+			if reqBody["name"] != "新名称" || reqBody["edit_permission"] != ChatPermissionOnlyOwner {
+				t.Errorf("Unexpected body: %v", reqBody)
+			}
+			if _, ok := reqBody["description"]; ok {
+				t.Error("Expected empty fields to be omitted")
+			}
+			if _, ok := reqBody["UserIDType"]; ok {
+				t.Error("Expected UserIDType not to be sent in body")
+			}
+		}
+		writeTestResponse(w, nil)
+	})
+
+	err := client.Chat.Update("oc_1", &UpdateChatRequest{
+		Name:           "新名称",
+		EditPermission: ChatPermissionOnlyOwner,
+		OwnerID:        "u_1",
+		UserIDType:     "user_id",
+	})
+	if err != nil {
+		t.Fatalf("update chat failed: %v", err)
+	}
+	if err := client.Chat.Dissolve("oc_1"); err != nil {
+		t.Fatalf("dissolve chat failed: %v", err)
+	}
+
+	want := []string{
+		"PUT /open-apis/im/v1/chats/oc_1?user_id_type=user_id",
+		"DELETE /open-apis/im/v1/chats/oc_1",
+	}
+	if len(requests) != 2 || requests[0] != want[0] || requests[1] != want[1] {
+		t.Errorf("Expected requests %v, got %v", want, requests)
+	}
+}
+
+func TestChatListAndSearch(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case "/open-apis/im/v1/chats":
+			if query.Get("page_token") == "" {
+				writeTestResponse(w, map[string]interface{}{
+					"items":      []map[string]interface{}{{"chat_id": "oc_1", "name": "a"}},
+					"page_token": "next",
+					"has_more":   true,
+				})
+				return
+			}
+			writeTestResponse(w, map[string]interface{}{
+				"items":    []map[string]interface{}{{"chat_id": "oc_2", "name": "b"}},
+				"has_more": false,
+			})
+		case "/open-apis/im/v1/chats/search":
+			if query.Get("query") != "告警" || query.Get("page_size") != "20" {
+				t.Errorf("Unexpected search query: %v", query)
+			}
+			writeTestResponse(w, map[string]interface{}{
+				"items": []map[string]interface{}{{"chat_id": "oc_3", "name": "告警群"}},
+			})
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	})
+
+	page, err := client.Chat.List(nil)
+	if err != nil {
+		t.Fatalf("list chats failed: %v", err)
+	}
+	if len(page.Items) != 1 || !page.HasMore || page.PageToken != "next" {
+		t.Errorf("Unexpected page: %+v", page)
+	}
+
+	chats, err := client.Chat.ListAll()
+	if err != nil {
+		t.Fatalf("list all chats failed: %v", err)
+	}
+	if len(chats) != 2 || chats[0].ChatID != "oc_1" || chats[1].ChatID != "oc_2" {
+		t.Errorf("Unexpected chats: %+v", chats)
+	}
+
+	found, err := client.Chat.Search("告警", &ListChatsRequest{PageSize: 20})
+	if err != nil {
+		t.Fatalf("search chats failed: %v", err)
+	}
+	if len(found.Items) != 1 || found.Items[0].Name != "告警群" {
+		t.Errorf("Unexpected search result: %+v", found)
+	}
+}
+
+func TestChatCreate(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/open-apis/im/v1/chats" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		query := r.URL.Query()
+		if query.Get("user_id_type") != "user_id" || query.Get("set_bot_manager") != "true" || query.Get("uuid") != "create-oncall" {
+			t.Errorf("Unexpected query: %v", query)
+		}
+
+		var reqBody map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		if reqBody["owner_id"] != "u_owner" || reqBody["chat_type"] != ChatTypePublic || reqBody["group_message_type"] != GroupMessageTypeThread {
+			t.Errorf("Unexpected body: %v", reqBody)
+		}
+		if ids, ok := reqBody["user_id_list"].([]interface{}); !ok || len(ids) != 2 {
+			t.Errorf("Expected user_id_list, got %v", reqBody["user_id_list"])
+		}
+		if ids, ok := reqBody["bot_id_list"].([]interface{}); !ok || len(ids) != 1 {
+			t.Errorf("Expected bot_id_list, got %v", reqBody["bot_id_list"])
+		}
+		if reqBody["external"] != true {
+			t.Errorf("Expected external flag, got %v", reqBody["external"])
+		}
+		i18n := reqBody["i18n_names"].(map[string]interface{})
+		if i18n["en_us"] != "On-call" {
+			t.Errorf("Unexpected i18n names: %v", i18n)
+		}
+
+		writeTestResponse(w, map[string]interface{}{
+			"chat_id":   "oc_new",
+			"name":      reqBody["name"],
+			"owner_id":  "u_owner",
+			"chat_type": ChatTypePublic,
+		})
+	})
+
+	req := &CreateGroupRequest{
+		Name:             "值班群",
+		I18nNames:        &ChatI18nNames{EnUs: "On-call"},
+		OwnerID:          "u_owner",
+		UserIDs:          []string{"u_1", "u_2"},
+		BotIDs:           []string{"cli_bot"},
+		ChatType:         ChatTypePublic,
+		GroupMessageType: GroupMessageTypeThread,
+		External:         true,
+		EditPermission:   ChatPermissionOnlyOwner,
+		UserIDType:       "user_id",
+		SetBotManager:    true,
+		UUID:             "create-oncall",
+	}
+	chat, err := client.Chat.Create(req)
+	if err != nil {
+		t.Fatalf("create chat failed: %v", err)
+	}
+	if chat.ChatID != "oc_new" || chat.OwnerID != "u_owner" || chat.ChatType != ChatTypePublic {
+		t.Errorf("Unexpected chat: %+v", chat)
+	}
+
+	chatID, err := client.Message.CreateGroup(req)
+	if err != nil || chatID != "oc_new" {
+		t.Errorf("Expected CreateGroup to return chat id, got %q, %v", chatID, err)
+	}
+}
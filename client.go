@@ -31,6 +31,7 @@ type Client struct {
 	// API服务
 	Message *MessageService
 	Sheet   *SheetService
+	Chat    *ChatService
 }
 
 // NewClient 创建一个新的飞书API客户端
@@ -46,6 +47,7 @@ func NewClient(appID, appSecret string) *Client {
 	// 初始化各服务
 	c.Message = newMessageService(c)
 	c.Sheet = newSheetService(c)
+	c.Chat = newChatService(c)
 	
 	return c
 }
@@ -185,10 +187,32 @@ func (e *Error) Error() string {
 
 // UploadFile 上传文件
 func (c *Client) UploadFile(path string, fileBytes []byte, fileName string) (string, error) {
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			FileKey string `json:"file_key"`
+		} `json:"data"`
+	}
+	
+	if err := c.uploadMultipart(path, nil, fileBytes, fileName, &result); err != nil {
+		return "", err
+	}
+	
+	// 检查响应状态
+	if result.Code != 0 {
+		return "", fmt.Errorf("upload file failed: %s", result.Msg)
+	}
+	
+	return result.Data.FileKey, nil
+}
+
+// uploadMultipart 以multipart/form-data格式上传文件，fields为附加的表单字段
+func (c *Client) uploadMultipart(path string, fields map[string]string, fileBytes []byte, fileName string, result interface{}) error {
 	// 获取认证token
 	token, err := c.GetTenantAccessToken()
 	if err != nil {
-		return "", err
+		return err
 	}
 	
 	// 构造请求URL
@@ -198,26 +222,33 @@ func (c *Client) UploadFile(path string, fileBytes []byte, fileName string) (str
 	var requestBody bytes.Buffer
 	multipartWriter := multipart.NewWriter(&requestBody)
 	
+	// 添加表单字段
+	for key, value := range fields {
+		if err := multipartWriter.WriteField(key, value); err != nil {
+			return fmt.Errorf("write form field failed: %w", err)
+		}
+	}
+	
 	// 添加文件部分
 	filePart, err := multipartWriter.CreateFormFile("file", fileName)
 	if err != nil {
-		return "", fmt.Errorf("create form file failed: %w", err)
+		return fmt.Errorf("create form file failed: %w", err)
 	}
 	
 	// 写入文件内容
 	if _, err := filePart.Write(fileBytes); err != nil {
-		return "", fmt.Errorf("write file content failed: %w", err)
+		return fmt.Errorf("write file content failed: %w", err)
 	}
 	
 	// 关闭multipart writer
 	if err := multipartWriter.Close(); err != nil {
-		return "", fmt.Errorf("close multipart writer failed: %w", err)
+		return fmt.Errorf("close multipart writer failed: %w", err)
 	}
 	
 	// 创建请求
 	req, err := http.NewRequest("POST", url, &requestBody)
 	if err != nil {
-		return "", fmt.Errorf("create request failed: %w", err)
+		return fmt.Errorf("create request failed: %w", err)
 	}
 	
 	// 设置请求头
@@ -227,33 +258,20 @@ func (c *Client) UploadFile(path string, fileBytes []byte, fileName string) (str
 	// 发送请求
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("send request failed: %w", err)
+		return fmt.Errorf("send request failed: %w", err)
 	}
 	defer resp.Body.Close()
 	
 	// 读取响应体
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("read response body failed: %w", err)
+		return fmt.Errorf("read response body failed: %w", err)
 	}
 	
 	// 解析响应
-	var result struct {
-		Code int    `json:"code"`
-		Msg  string `json:"msg"`
-		Data struct {
-			FileKey string `json:"file_key"`
-		} `json:"data"`
+	if err := json.Unmarshal(respBody, result); err != nil {
+		return fmt.Errorf("unmarshal response body failed: %w", err)
 	}
 	
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return "", fmt.Errorf("unmarshal response body failed: %w", err)
-	}
-	
-	// 检查响应状态
-	if result.Code != 0 {
-		return "", fmt.Errorf("upload file failed: %s", result.Msg)
-	}
-	
-	return result.Data.FileKey, nil
-}
\ No newline at end of file
+	return nil
+}
@@ -355,4 +355,30 @@ func TestError(t *testing.T) {
 	if err.Error() != expected {
 		t.Errorf("Expected error message '%s', got '%s'", expected, err.Error())
 	}
-}
\ No newline at end of file
+}
+// newTestClient 创建一个请求指向测试服务器的客户端
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	originBaseURL := BaseURL
+	BaseURL = server.URL + "/open-apis"
+	t.Cleanup(func() {
+		BaseURL = originBaseURL
+		server.Close()
+	})
+
+	client := NewClient("test-app-id", "test-app-secret")
+	client.tenantAccessToken = "test-token"
+	client.tokenExpireTime = time.Now().Add(7200 * time.Second)
+	return client
+}
+
+// writeTestResponse 写入模拟的接口响应
+func writeTestResponse(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code": 0,
+		"msg":  "ok",
+		"data": data,
+	})
+}
@@ -0,0 +1,465 @@
+package easylark
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// defaultCommandPrefix 默认的斜杠命令前缀
+const defaultCommandPrefix = "/"
+
+// CommandUsageError 命令参数错误，命令处理函数返回该错误时自动回复命令用法
+type CommandUsageError struct {
+	Message string
+}
+
+// Error 实现error接口
+func (e *CommandUsageError) Error() string {
+	return e.Message
+}
+
+// NewCommandUsageError 创建命令参数错误
+func NewCommandUsageError(format string, args ...interface{}) error {
+	return &CommandUsageError{Message: fmt.Sprintf(format, args...)}
+}
+
+// Command 机器人命令
+type Command struct {
+	Name        string
+	Usage       string // 参数说明，如"<service> [--env prod]"
+	Description string
+	MinArgs     int // 最少的位置参数个数
+
+	// Flags 注册命令的flag，每次执行命令时使用新的FlagSet
+	Flags func(fs *flag.FlagSet)
+	// Handler 命令处理函数
+	Handler func(ctx context.Context, cmd *CommandContext) error
+
+	// AllowUsers 允许执行命令的用户，匹配open_id、user_id或union_id，为空时不限制
+	AllowUsers []string
+	// AllowChats 允许执行命令的群，为空时不限制
+	AllowChats []string
+}
+
+// allowed 判断发送者和群是否允许执行命令
+func (c *Command) allowed(event *MessageReceiveEvent) bool {
+	if len(c.AllowChats) > 0 && !containsString(c.AllowChats, event.Message.ChatID) {
+		return false
+	}
+	if len(c.AllowUsers) == 0 {
+		return true
+	}
+	if event.Sender == nil || event.Sender.SenderID == nil {
+		return false
+	}
+	id := event.Sender.SenderID
+	for _, user := range c.AllowUsers {
+		if user != "" && (user == id.OpenID || user == id.UserID || user == id.UnionID) {
+			return true
+		}
+	}
+	return false
+}
+
+// help 生成命令的帮助文本
+func (c *Command) help() string {
+	var b strings.Builder
+	b.WriteString("用法：" + c.Name)
+	if c.Usage != "" {
+		b.WriteString(" " + c.Usage)
+	}
+	if c.Description != "" {
+		b.WriteString("\n" + c.Description)
+	}
+	if c.Flags != nil {
+		fs := flag.NewFlagSet(c.Name, flag.ContinueOnError)
+		c.Flags(fs)
+		var defaults bytes.Buffer
+		fs.SetOutput(&defaults)
+		fs.PrintDefaults()
+		if defaults.Len() > 0 {
+			b.WriteString("\n参数：\n" + strings.TrimRight(defaults.String(), "\n"))
+		}
+	}
+	return b.String()
+}
+
+// CommandContext 命令的执行上下文
+type CommandContext struct {
+	Name  string
+	Args  []string // 位置参数
+	Flags *flag.FlagSet
+	Event *MessageReceiveEvent
+
+	router  *CommandRouter
+	replies int
+}
+
+// Arg 获取第i个位置参数，不存在时返回空
+func (c *CommandContext) Arg(i int) string {
+	if i < 0 || i >= len(c.Args) {
+		return ""
+	}
+	return c.Args[i]
+}
+
+// String 获取flag的值
+func (c *CommandContext) String(name string) string {
+	if f := c.Flags.Lookup(name); f != nil {
+		return f.Value.String()
+	}
+	return ""
+}
+
+// Bool 获取bool类型flag的值
+func (c *CommandContext) Bool(name string) bool {
+	v, _ := c.flagValue(name).(bool)
+	return v
+}
+
+// Int 获取int类型flag的值
+func (c *CommandContext) Int(name string) int {
+	v, _ := c.flagValue(name).(int)
+	return v
+}
+
+// flagValue 获取flag的原始值
+func (c *CommandContext) flagValue(name string) interface{} {
+	f := c.Flags.Lookup(name)
+	if f == nil {
+		return nil
+	}
+	if getter, ok := f.Value.(flag.Getter); ok {
+		return getter.Get()
+	}
+	return nil
+}
+
+// Mention 获取参数对应的@信息，参数不是@占位符时返回nil
+func (c *CommandContext) Mention(arg string) *Mention {
+	for _, mention := range c.Event.Message.Mentions {
+		if mention != nil && mention.Key == arg {
+			return mention
+		}
+	}
+	return nil
+}
+
+// SenderOpenID 发送者的open_id
+func (c *CommandContext) SenderOpenID() string {
+	if c.Event.Sender == nil || c.Event.Sender.SenderID == nil {
+		return ""
+	}
+	return c.Event.Sender.SenderID.OpenID
+}
+
+// Reply 回复命令消息，可以多次回复
+func (c *CommandContext) Reply(content MessageContent) error {
+	c.replies++
+	return c.router.reply(c.Event, content, strconv.Itoa(c.replies))
+}
+
+// ReplyText 以文本回复命令消息
+func (c *CommandContext) ReplyText(text string) error {
+	return c.Reply(&TextContent{Text: text})
+}
+
+// CommandRouter 解析@机器人或斜杠开头的文本消息并执行对应的命令
+//
+//	router := easylark.NewCommandRouter(client.Message, botOpenID)
+//	router.Register(&easylark.Command{
+//		Name:  "deploy",
+//		Usage: "<service> [--env prod]",
+//		MinArgs: 1,
+//		Flags: func(fs *flag.FlagSet) { fs.String("env", "staging", "目标环境") },
+//		Handler: func(ctx context.Context, cmd *easylark.CommandContext) error {
+//			return cmd.ReplyText("部署 " + cmd.Arg(0) + " 到 " + cmd.String("env"))
+//		},
+//	})
+//	dispatcher.OnMessageReceive(router.HandleMessage)
+//
+// 单聊中的消息都按命令处理；群聊中只处理@机器人或以Prefix开头的消息，识别@机器人需要配置BotOpenID。
+// 参数按空白分隔，支持单引号、双引号和反斜杠转义，flag可以出现在位置参数之后。
+// 内置help命令列出可用的命令，参数错误和无权限时自动回复提示；
+// 未知命令只在单聊或@机器人时回复，避免群聊中其他机器人的斜杠命令被误回复。
+type CommandRouter struct {
+	// Prefix 斜杠命令前缀，默认为"/"
+	Prefix string
+	// BotOpenID 机器人的open_id，用于识别群聊中的@机器人；为空时群聊只处理以Prefix开头的消息
+	BotOpenID string
+	// OnError 命令处理函数返回错误时调用，此时回复"命令执行失败"
+	OnError func(cmd *CommandContext, err error)
+
+	messages *MessageService
+	commands map[string]*Command
+}
+
+// NewCommandRouter 创建命令路由
+func NewCommandRouter(messages *MessageService, botOpenID string) *CommandRouter {
+	return &CommandRouter{
+		Prefix:    defaultCommandPrefix,
+		BotOpenID: botOpenID,
+		messages:  messages,
+		commands:  make(map[string]*Command),
+	}
+}
+
+// Register 注册命令，命令名不区分大小写
+func (r *CommandRouter) Register(cmd *Command) error {
+	if cmd == nil || cmd.Name == "" || strings.IndexFunc(cmd.Name, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("register command failed: invalid command name")
+	}
+	if cmd.Handler == nil {
+		return fmt.Errorf("register command %s failed: handler is required", cmd.Name)
+	}
+	name := strings.ToLower(cmd.Name)
+	if name == "help" {
+		return fmt.Errorf("register command failed: help is reserved")
+	}
+	if _, ok := r.commands[name]; ok {
+		return fmt.Errorf("register command failed: %s already registered", cmd.Name)
+	}
+	if r.commands == nil {
+		r.commands = make(map[string]*Command)
+	}
+	r.commands[name] = cmd
+	return nil
+}
+
+// HandleMessage 处理接收消息事件，可直接注册到EventDispatcher.OnMessageReceive
+func (r *CommandRouter) HandleMessage(ctx context.Context, event *MessageReceiveEvent) error {
+	if event.Message == nil || MessageType(event.Message.MessageType) != MessageTypeText {
+		return nil
+	}
+	// 忽略机器人发送的消息，避免相互触发
+	if event.Sender != nil && event.Sender.SenderType == "app" {
+		return nil
+	}
+	content, err := event.Message.DecodeContent()
+	if err != nil {
+		return err
+	}
+	text, ok, direct := r.commandText(event, content.(*TextContent).Text)
+	if !ok {
+		return nil
+	}
+
+	args, err := splitCommandArgs(text)
+	if err != nil {
+		return r.replyText(event, err.Error())
+	}
+	if len(args) == 0 {
+		return r.replyText(event, r.helpText(event))
+	}
+
+	name := strings.ToLower(args[0])
+	if name == "help" {
+		if len(args) > 1 {
+			if cmd, ok := r.commands[strings.ToLower(args[1])]; ok && cmd.allowed(event) {
+				return r.replyText(event, cmd.help())
+			}
+		}
+		return r.replyText(event, r.helpText(event))
+	}
+
+	cmd, ok := r.commands[name]
+	if !ok {
+		if !direct {
+			return nil
+		}
+		return r.replyText(event, fmt.Sprintf("未知命令 %s，发送 help 查看可用的命令", args[0]))
+	}
+	if !cmd.allowed(event) {
+		return r.replyText(event, fmt.Sprintf("没有权限执行命令 %s", cmd.Name))
+	}
+	return r.run(ctx, cmd, event, args[1:])
+}
+
+// run 解析参数并执行命令
+func (r *CommandRouter) run(ctx context.Context, cmd *Command, event *MessageReceiveEvent, args []string) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if cmd.Flags != nil {
+		cmd.Flags(fs)
+	}
+	positional, err := parseCommandFlags(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		return r.replyText(event, cmd.help())
+	}
+	if err != nil {
+		return r.replyText(event, err.Error()+"\n"+cmd.help())
+	}
+	if len(positional) < cmd.MinArgs {
+		return r.replyText(event, fmt.Sprintf("至少需要%d个参数\n%s", cmd.MinArgs, cmd.help()))
+	}
+
+	cmdCtx := &CommandContext{
+		Name:   cmd.Name,
+		Args:   positional,
+		Flags:  fs,
+		Event:  event,
+		router: r,
+	}
+	err = cmd.Handler(ctx, cmdCtx)
+	var usageErr *CommandUsageError
+	if errors.As(err, &usageErr) {
+		return r.replyText(event, usageErr.Message+"\n"+cmd.help())
+	}
+	if err != nil {
+		if r.OnError != nil {
+			r.OnError(cmdCtx, err)
+		}
+		return r.replyText(event, fmt.Sprintf("命令 %s 执行失败", cmd.Name))
+	}
+	return nil
+}
+
+// commandText 判断消息是否为命令，返回去掉@机器人和前缀后的文本；direct表示单聊或@了机器人
+func (r *CommandRouter) commandText(event *MessageReceiveEvent, text string) (cmd string, ok bool, direct bool) {
+	direct = event.Message.ChatType == "p2p"
+	if r.BotOpenID != "" {
+		for _, mention := range event.Message.Mentions {
+			if mention != nil && mention.Key != "" && mention.OpenID == r.BotOpenID {
+				text = strings.ReplaceAll(text, mention.Key, "")
+				direct = true
+			}
+		}
+	}
+	text = strings.TrimSpace(text)
+
+	prefix := r.Prefix
+	if prefix == "" {
+		prefix = defaultCommandPrefix
+	}
+	if strings.HasPrefix(text, prefix) {
+		return strings.TrimPrefix(text, prefix), true, direct
+	}
+	return text, direct, direct
+}
+
+// helpText 列出发送者可以执行的命令
+func (r *CommandRouter) helpText(event *MessageReceiveEvent) string {
+	names := make([]string, 0, len(r.commands))
+	for name, cmd := range r.commands {
+		if cmd.allowed(event) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "没有可用的命令"
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("可用的命令：")
+	for _, name := range names {
+		cmd := r.commands[name]
+		b.WriteString("\n" + cmd.Name)
+		if cmd.Usage != "" {
+			b.WriteString(" " + cmd.Usage)
+		}
+		if cmd.Description != "" {
+			b.WriteString("  " + cmd.Description)
+		}
+	}
+	b.WriteString("\n发送 help <命令> 查看命令的详细用法")
+	return b.String()
+}
+
+// replyText 以文本回复路由自身的提示
+func (r *CommandRouter) replyText(event *MessageReceiveEvent, text string) error {
+	return r.reply(event, &TextContent{Text: text}, "router")
+}
+
+// reply 回复消息，幂等key由消息ID和key组成，事件重新推送时不会重复回复
+func (r *CommandRouter) reply(event *MessageReceiveEvent, content MessageContent, key string) error {
+	return r.messages.ReplyMessage(event.Message.MessageID, content, WithUUID("command-reply/"+event.Message.MessageID+"/"+key))
+}
+
+// parseCommandFlags 解析flag，允许flag出现在位置参数之后，"--"之后的参数都作为位置参数
+func parseCommandFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// splitCommandArgs 按空白分隔参数，支持单引号、双引号、中文引号和反斜杠转义
+func splitCommandArgs(text string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune // 当前引号的结束字符
+	escaped := false
+
+	for _, c := range text {
+		switch {
+		case escaped:
+			current.WriteRune(c)
+			escaped = false
+		case c == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				current.WriteRune(c)
+			}
+		case c == '"' || c == '\'':
+			quote = c
+			inArg = true
+		case c == '“':
+			quote = '”'
+			inArg = true
+		case unicode.IsSpace(c):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(c)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("引号未闭合")
+	}
+	if escaped {
+		current.WriteRune('\\')
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// containsString 判断列表中是否包含s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,221 @@
+package easylark
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newCommandEvent 构造文本消息事件
+func newCommandEvent(chatType, text string, mentions ...*Mention) *MessageReceiveEvent {
+	content, _ := json.Marshal(map[string]string{"text": text})
+	return &MessageReceiveEvent{
+		Sender: &EventSender{SenderID: &EventUserID{OpenID: "ou_alice", UserID: "alice"}, SenderType: "user"},
+		Message: &EventMessage{
+			MessageID:   "om_1",
+			ChatID:      "oc_1",
+			ChatType:    chatType,
+			MessageType: "text",
+			Content:     string(content),
+			Mentions:    mentions,
+		},
+	}
+}
+
+// newCommandTestRouter 创建记录回复内容和uuid的命令路由
+func newCommandTestRouter(t *testing.T) (*CommandRouter, *[]string, *[]string) {
+	var replies, uuids []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/open-apis/im/v1/messages/om_1/reply" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		var reqBody struct {
+			Content map[string]string `json:"content"`
+			UUID    string            `json:"uuid"`
+		}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		uuids = append(uuids, reqBody.UUID)
+		replies = append(replies, reqBody.Content["text"])
+		writeTestResponse(w, map[string]string{"message_id": "om_reply"})
+	})
+	return NewCommandRouter(client.Message, "ou_bot"), &replies, &uuids
+}
+
+func TestCommandRouter(t *testing.T) {
+	router, replies, uuids := newCommandTestRouter(t)
+
+	var got *CommandContext
+	err := router.Register(&Command{
+		Name:        "deploy",
+		Usage:       "<service>",
+		Description: "部署服务",
+		MinArgs:     1,
+		Flags: func(fs *flag.FlagSet) {
+			fs.String("env", "staging", "目标环境")
+			fs.Bool("force", false, "跳过检查")
+		},
+		Handler: func(ctx context.Context, cmd *CommandContext) error {
+			got = cmd
+			cmd.ReplyText("开始部署")
+			return cmd.ReplyText("部署完成")
+		},
+	})
+	if err != nil {
+		t.Fatalf("register command failed: %v", err)
+	}
+
+	bot := &Mention{Key: "@_user_1", OpenID: "ou_bot", Name: "bot"}
+	event := newCommandEvent("group", `@_user_1 deploy service-x --env prod "release note" --force`, bot)
+	if err := router.HandleMessage(context.Background(), event); err != nil {
+		t.Fatalf("handle message failed: %v", err)
+	}
+	if got == nil {
+		t.Fatal("Expected deploy to be called")
+	}
+	if !reflect.DeepEqual(got.Args, []string{"service-x", "release note"}) {
+		t.Errorf("Unexpected args %q", got.Args)
+	}
+	if got.String("env") != "prod" || !got.Bool("force") || got.SenderOpenID() != "ou_alice" {
+		t.Errorf("Unexpected flags env=%s force=%v", got.String("env"), got.Bool("force"))
+	}
+	if strings.Join(*replies, "|") != "开始部署|部署完成" {
+		t.Errorf("Unexpected replies %q", *replies)
+	}
+	// 多次回复使用不同的uuid，避免被当作重复请求
+	if len(*uuids) != 2 || (*uuids)[0] == "" || (*uuids)[0] == (*uuids)[1] {
+		t.Errorf("Expected distinct reply uuids, got %q", *uuids)
+	}
+
+	// 群聊中没有@机器人也没有前缀时忽略
+	got = nil
+	router.HandleMessage(context.Background(), newCommandEvent("group", "deploy service-x"))
+	if got != nil {
+		t.Error("Expected group message without mention to be ignored")
+	}
+
+	// 斜杠命令和单聊
+	for _, event := range []*MessageReceiveEvent{
+		newCommandEvent("group", "/deploy a"),
+		newCommandEvent("p2p", "DEPLOY a"),
+	} {
+		got = nil
+		router.HandleMessage(context.Background(), event)
+		if got == nil || got.Arg(0) != "a" {
+			t.Errorf("Expected deploy to be called for %s", event.Message.Content)
+		}
+	}
+	// 群聊中的未知斜杠命令可能属于其他机器人，只在@机器人时回复
+	*replies = nil
+	router.HandleMessage(context.Background(), newCommandEvent("group", "/unknown"))
+	if len(*replies) != 0 {
+		t.Errorf("Expected unknown group command to be ignored, got %q", *replies)
+	}
+	router.HandleMessage(context.Background(), newCommandEvent("group", "@_user_1 /unknown", bot))
+	if len(*replies) != 1 || !strings.Contains((*replies)[0], "未知命令 unknown") {
+		t.Errorf("Expected unknown command reply when mentioned, got %q", *replies)
+	}
+
+	// 未配置BotOpenID时，群聊中@其他人不视为@机器人
+	router.BotOpenID = ""
+	got = nil
+	other := &Mention{Key: "@_user_2", OpenID: "ou_bob", Name: "bob"}
+	router.HandleMessage(context.Background(), newCommandEvent("group", "@_user_2 deploy a", other))
+	if got != nil {
+		t.Error("Expected group message mentioning another user to be ignored")
+	}
+}
+
+func TestCommandRouterReplies(t *testing.T) {
+	router, replies, _ := newCommandTestRouter(t)
+	handlerErr := errors.New("boom")
+	var reported error
+	router.OnError = func(cmd *CommandContext, err error) {
+		reported = err
+	}
+	router.Register(&Command{
+		Name:       "rollback",
+		Usage:      "<service>",
+		MinArgs:    1,
+		AllowUsers: []string{"bob"},
+		Handler:    func(ctx context.Context, cmd *CommandContext) error { return nil },
+	})
+	router.Register(&Command{
+		Name: "scale",
+		Flags: func(fs *flag.FlagSet) {
+			fs.Int("replicas", 1, "副本数")
+		},
+		Handler: func(ctx context.Context, cmd *CommandContext) error {
+			if cmd.Int("replicas") > 10 {
+				return NewCommandUsageError("副本数不能超过10")
+			}
+			return handlerErr
+		},
+	})
+
+	cases := []struct {
+		text string
+		want string
+	}{
+		{"unknown", "未知命令 unknown"},
+		{"rollback svc", "没有权限执行命令 rollback"},
+		{"scale --replicas=abc", "invalid value"},
+		{"scale --replicas 20", "副本数不能超过10"},
+		{"scale", "命令 scale 执行失败"},
+		{"scale -h", "-replicas int"},
+		{`scale "unterminated`, "引号未闭合"},
+		{"help", "可用的命令：\nscale"},
+	}
+	for _, c := range cases {
+		*replies = nil
+		if err := router.HandleMessage(context.Background(), newCommandEvent("p2p", c.text)); err != nil {
+			t.Errorf("handle %q failed: %v", c.text, err)
+		}
+		if len(*replies) != 1 || !strings.Contains((*replies)[0], c.want) {
+			t.Errorf("Expected reply to %q to contain %q, got %q", c.text, c.want, *replies)
+		}
+	}
+	if reported != handlerErr {
+		t.Errorf("Expected handler error to be reported, got %v", reported)
+	}
+	// help中不列出无权限的命令
+	if strings.Contains((*replies)[0], "rollback") {
+		t.Errorf("Expected help to hide rollback, got %s", (*replies)[0])
+	}
+
+	if err := router.Register(&Command{Name: "scale", Handler: func(ctx context.Context, cmd *CommandContext) error { return nil }}); err == nil {
+		t.Error("Expected error for duplicate command")
+	}
+	if err := router.Register(&Command{Name: "help", Handler: func(ctx context.Context, cmd *CommandContext) error { return nil }}); err == nil {
+		t.Error("Expected error for reserved command")
+	}
+}
+
+func TestSplitCommandArgs(t *testing.T) {
+	cases := map[string][]string{
+		`deploy  svc`:             {"deploy", "svc"},
+		`say "hello world" 'a b'`: {"say", "hello world", "a b"},
+		`say “你好 世界”`:             {"say", "你好 世界"},
+		`path a\ b "x\"y"`:        {"path", "a b", `x"y`},
+		`empty ""`:                {"empty", ""},
+	}
+	for text, want := range cases {
+		got, err := splitCommandArgs(text)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("splitCommandArgs(%q) = %q, %v, want %q", text, got, err, want)
+		}
+	}
+}
+
+func TestParseCommandFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	env := fs.String("env", "", "")
+	args, err := parseCommandFlags(fs, []string{"a", "--env", "prod", "b", "--", "--not-flag"})
+	if err != nil || *env != "prod" || !reflect.DeepEqual(args, []string{"a", "b", "--not-flag"}) {
+		t.Errorf("Unexpected result %q env=%s err=%v", args, *env, err)
+	}
+}
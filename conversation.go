@@ -0,0 +1,636 @@
+package easylark
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSessionNotFound 会话不存在
+var ErrSessionNotFound = errors.New("session not found")
+
+// FlowCardAction 会话中卡片按钮value的action，用于在CardActionHandler中路由
+const FlowCardAction = "easylark.flow"
+
+// FlowEnd 步骤处理函数返回FlowEnd时结束会话
+const FlowEnd = "$end"
+
+// defaultFlowTimeout 会话默认的超时时间
+const defaultFlowTimeout = 10 * time.Minute
+
+// sessionSweepInterval 内存存储清理超时会话的最小间隔
+const sessionSweepInterval = time.Minute
+
+// Session 用户在一个会话中的多轮对话状态，按群和用户区分
+type Session struct {
+	ChatID    string            `json:"chat_id"`
+	UserID    string            `json:"user_id"` // 用户的open_id
+	Flow      string            `json:"flow"`
+	Step      string            `json:"step"`
+	Data      map[string]string `json:"data"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// Key 会话在存储中的key
+func (s *Session) Key() string {
+	return sessionKey(s.ChatID, s.UserID)
+}
+
+// sessionKey 根据群和用户生成会话的key
+func sessionKey(chatID, userID string) string {
+	return chatID + "/" + userID
+}
+
+// copySession 复制会话，避免存储与调用方共享Data
+func copySession(s *Session) *Session {
+	copied := *s
+	copied.Data = make(map[string]string, len(s.Data))
+	for k, v := range s.Data {
+		copied.Data[k] = v
+	}
+	return &copied
+}
+
+// SessionStore 会话的存储，可以实现为基于Redis等的共享存储
+type SessionStore interface {
+	// Save 保存会话，key相同时覆盖
+	Save(session *Session) error
+	// Get 获取会话，不存在时返回ErrSessionNotFound
+	Get(key string) (*Session, error)
+	// Delete 删除会话，不存在时返回ErrSessionNotFound
+	Delete(key string) error
+}
+
+// MemorySessionStore 内存存储，进程重启后会话会丢失
+//
+// 保存会话时定期清理已超时的会话，用户放弃的会话不会一直占用内存。
+type MemorySessionStore struct {
+	mu        sync.Mutex
+	sessions  map[string]*Session
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+// NewMemorySessionStore 创建内存存储
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{
+		sessions: make(map[string]*Session),
+		now:      time.Now,
+	}
+}
+
+// Save 实现SessionStore接口
+func (m *MemorySessionStore) Save(session *Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sweep()
+	m.sessions[session.Key()] = copySession(session)
+	return nil
+}
+
+// sweep 清理已超时的会话，两次清理至少间隔sessionSweepInterval
+func (m *MemorySessionStore) sweep() {
+	now := m.now()
+	if now.Sub(m.lastSweep) < sessionSweepInterval {
+		return
+	}
+	m.lastSweep = now
+	for key, session := range m.sessions {
+		if !session.ExpiresAt.IsZero() && !now.Before(session.ExpiresAt) {
+			delete(m.sessions, key)
+		}
+	}
+}
+
+// Get 实现SessionStore接口
+func (m *MemorySessionStore) Get(key string) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.sessions[key]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	return copySession(session), nil
+}
+
+// Delete 实现SessionStore接口
+func (m *MemorySessionStore) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.sessions[key]; !ok {
+		return ErrSessionNotFound
+	}
+	delete(m.sessions, key)
+	return nil
+}
+
+// FlowInputError 用户的回答不合法，步骤处理函数返回该错误时回复提示并重新提问
+type FlowInputError struct {
+	Message string
+}
+
+// Error 实现error接口
+func (e *FlowInputError) Error() string {
+	return e.Message
+}
+
+// NewFlowInputError 创建回答不合法的错误
+func NewFlowInputError(format string, args ...interface{}) error {
+	return &FlowInputError{Message: fmt.Sprintf(format, args...)}
+}
+
+// FlowInput 用户在一个步骤中的回答，来自文本消息或卡片交互
+type FlowInput struct {
+	// Text 文本消息的内容，或卡片按钮value中的value、下拉菜单选中的值
+	Text    string
+	Message *MessageReceiveEvent
+	Action  *CardActionEvent
+}
+
+// FlowStep 会话中的一个步骤
+type FlowStep struct {
+	Name string
+	// Prompt 进入步骤时发送的提问，可以是文本或带按钮的卡片，为nil时不提问
+	Prompt func(conv *Conversation) MessageContent
+	// Handle 处理回答，返回下一步的名称；返回空时进入下一个步骤，返回FlowEnd时结束。
+	// 为nil时将回答的文本以步骤名称为key保存到会话中。
+	Handle func(ctx context.Context, conv *Conversation, input *FlowInput) (string, error)
+}
+
+// Flow 多轮对话的流程定义
+type Flow struct {
+	Name    string
+	Steps   []*FlowStep
+	Timeout time.Duration // 每一步等待回答的超时时间，默认10分钟
+
+	// OnComplete 所有步骤完成后调用
+	OnComplete func(ctx context.Context, conv *Conversation) error
+}
+
+// step 获取步骤及其序号
+func (f *Flow) step(name string) (int, *FlowStep) {
+	for i, step := range f.Steps {
+		if step.Name == name {
+			return i, step
+		}
+	}
+	return -1, nil
+}
+
+// timeout 每一步的超时时间
+func (f *Flow) timeout() time.Duration {
+	if f.Timeout > 0 {
+		return f.Timeout
+	}
+	return defaultFlowTimeout
+}
+
+// Conversation 步骤处理函数中的会话
+type Conversation struct {
+	Session *Session
+	Flow    *Flow
+
+	manager *ConversationManager
+	input   *FlowInput
+	step    string // 收到回答时所在的步骤
+	replies int
+}
+
+// Get 获取会话中保存的值
+func (c *Conversation) Get(key string) string {
+	return c.Session.Data[key]
+}
+
+// Set 在会话中保存值
+func (c *Conversation) Set(key, value string) {
+	if c.Session.Data == nil {
+		c.Session.Data = make(map[string]string)
+	}
+	c.Session.Data[key] = value
+}
+
+// Reply 回复用户，回答来自文本消息时回复该消息，否则发送到会话所在的群
+//
+// 回答的幂等key由消息ID或卡片回调的token、步骤和回复序号组成，事件重新推送时不会重复发送。
+func (c *Conversation) Reply(content MessageContent) error {
+	c.replies++
+	var opts []SendOption
+	if source := c.source(); source != "" {
+		opts = append(opts, WithUUID("conversation-reply/"+source+"/"+c.step+"/"+strconv.Itoa(c.replies)))
+	}
+	if c.input != nil && c.input.Message != nil && c.input.Message.Message != nil {
+		return c.manager.messages.ReplyMessage(c.input.Message.Message.MessageID, content, opts...)
+	}
+	return c.manager.messages.SendMessage(c.Session.ChatID, content, opts...)
+}
+
+// source 回答来源的唯一标识，Start发起的提问没有来源
+func (c *Conversation) source() string {
+	switch {
+	case c.input == nil:
+		return ""
+	case c.input.Message != nil && c.input.Message.Message != nil:
+		return c.input.Message.Message.MessageID
+	case c.input.Action != nil && c.input.Action.Token != "":
+		return "card:" + c.input.Action.Token
+	}
+	return ""
+}
+
+// ReplyText 以文本回复用户
+func (c *Conversation) ReplyText(text string) error {
+	return c.Reply(&TextContent{Text: text})
+}
+
+// Button 创建回答当前步骤的卡片按钮，点击后value作为回答的文本
+func (c *Conversation) Button(text, value string) *CardButton {
+	return NewCardButton(text, map[string]interface{}{
+		"action": FlowCardAction,
+		"flow":   c.Flow.Name,
+		"step":   c.Session.Step,
+		"value":  value,
+	})
+}
+
+// ChoiceCard 创建带选项按钮的提问卡片
+func (c *Conversation) ChoiceCard(question string, options ...string) *MessageCard {
+	buttons := make([]CardElement, 0, len(options))
+	for _, option := range options {
+		buttons = append(buttons, c.Button(option, option))
+	}
+	return NewMessageCard().AddMarkdown(question).AddElement(&CardAction{Actions: buttons})
+}
+
+// ConversationManager 管理多轮对话的会话，按群和用户保存每个会话所在的步骤
+//
+//	manager := easylark.NewConversationManager(client.Message, nil)
+//	manager.Register(&easylark.Flow{
+//		Name: "deploy",
+//		Steps: []*easylark.FlowStep{
+//			{Name: "env", Prompt: func(conv *easylark.Conversation) easylark.MessageContent {
+//				return conv.ChoiceCard("部署到哪个环境？", "staging", "prod")
+//			}},
+//			{Name: "confirm", Prompt: ...},
+//		},
+//		OnComplete: func(ctx context.Context, conv *easylark.Conversation) error { ... },
+//	})
+//	dispatcher.OnMessageReceive(manager.MessageHandler(router.HandleMessage))
+//	cardHandler.On(easylark.FlowCardAction, manager.HandleCardAction)
+//
+// 会话超时后在用户下一次回答时提示已超时。发送CancelWords中的文本可以取消会话。
+// 卡片按钮只对发出它的流程和步骤有效，点击旧的按钮时提示已失效。
+type ConversationManager struct {
+	// CancelWords 取消会话的文本，默认为"取消"和"cancel"
+	CancelWords []string
+
+	messages *MessageService
+	store    SessionStore
+	now      func() time.Time
+
+	mu    sync.Mutex
+	flows map[string]*Flow
+	locks map[string]*sessionLock
+}
+
+// sessionLock 同一会话的处理互斥
+type sessionLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// NewConversationManager 创建会话管理器，store为nil时使用内存存储
+func NewConversationManager(messages *MessageService, store SessionStore) *ConversationManager {
+	if store == nil {
+		store = NewMemorySessionStore()
+	}
+	return &ConversationManager{
+		CancelWords: []string{"取消", "cancel"},
+		messages:    messages,
+		store:       store,
+		now:         time.Now,
+		flows:       make(map[string]*Flow),
+		locks:       make(map[string]*sessionLock),
+	}
+}
+
+// Register 注册流程
+func (m *ConversationManager) Register(flow *Flow) error {
+	if flow == nil || flow.Name == "" {
+		return fmt.Errorf("register flow failed: name is required")
+	}
+	if len(flow.Steps) == 0 {
+		return fmt.Errorf("register flow %s failed: steps are required", flow.Name)
+	}
+	names := make(map[string]bool, len(flow.Steps))
+	for _, step := range flow.Steps {
+		if step.Name == "" || step.Name == FlowEnd || names[step.Name] {
+			return fmt.Errorf("register flow %s failed: invalid or duplicate step name %q", flow.Name, step.Name)
+		}
+		names[step.Name] = true
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.flows[flow.Name]; ok {
+		return fmt.Errorf("register flow failed: %s already registered", flow.Name)
+	}
+	m.flows[flow.Name] = flow
+	return nil
+}
+
+// Start 为用户开始一个流程并发送第一步的提问，用户已有的会话会被替换
+func (m *ConversationManager) Start(ctx context.Context, flowName, chatID, userID string, data map[string]string) (*Session, error) {
+	flow := m.flow(flowName)
+	if flow == nil {
+		return nil, fmt.Errorf("start flow failed: unknown flow %s", flowName)
+	}
+	key := sessionKey(chatID, userID)
+	unlock := m.lock(key)
+	defer unlock()
+
+	now := m.now()
+	session := &Session{
+		ChatID:    chatID,
+		UserID:    userID,
+		Flow:      flow.Name,
+		Step:      flow.Steps[0].Name,
+		Data:      make(map[string]string, len(data)),
+		UpdatedAt: now,
+		ExpiresAt: now.Add(flow.timeout()),
+	}
+	for k, v := range data {
+		session.Data[k] = v
+	}
+	if err := m.store.Save(session); err != nil {
+		return nil, fmt.Errorf("start flow failed: %w", err)
+	}
+
+	conv := &Conversation{Session: session, Flow: flow, manager: m}
+	if err := m.prompt(conv, flow.Steps[0]); err != nil {
+		return nil, fmt.Errorf("start flow failed: %w", err)
+	}
+	return copySession(session), nil
+}
+
+// Active 获取用户当前未超时的会话，不存在时返回ErrSessionNotFound
+func (m *ConversationManager) Active(chatID, userID string) (*Session, error) {
+	session, err := m.store.Get(sessionKey(chatID, userID))
+	if err != nil {
+		return nil, err
+	}
+	if !m.now().Before(session.ExpiresAt) {
+		return nil, ErrSessionNotFound
+	}
+	return session, nil
+}
+
+// Cancel 取消用户的会话
+func (m *ConversationManager) Cancel(chatID, userID string) error {
+	key := sessionKey(chatID, userID)
+	unlock := m.lock(key)
+	defer unlock()
+	return m.store.Delete(key)
+}
+
+// MessageHandler 返回接收消息事件的处理函数，用户有进行中的会话时作为回答处理，否则交给next，next可以为nil
+func (m *ConversationManager) MessageHandler(next func(ctx context.Context, event *MessageReceiveEvent) error) func(ctx context.Context, event *MessageReceiveEvent) error {
+	return func(ctx context.Context, event *MessageReceiveEvent) error {
+		handled, err := m.HandleMessage(ctx, event)
+		if handled || err != nil || next == nil {
+			return err
+		}
+		return next(ctx, event)
+	}
+}
+
+// HandleMessage 将文本消息作为会话的回答处理，用户没有进行中的会话时返回false
+func (m *ConversationManager) HandleMessage(ctx context.Context, event *MessageReceiveEvent) (bool, error) {
+	if event.Message == nil || event.Sender == nil || event.Sender.SenderID == nil ||
+		MessageType(event.Message.MessageType) != MessageTypeText {
+		return false, nil
+	}
+	content, err := event.Message.DecodeContent()
+	if err != nil {
+		return false, err
+	}
+	input := &FlowInput{
+		Text:    stripLeadingMentions(content.(*TextContent).Text, event.Message.Mentions),
+		Message: event,
+	}
+
+	handled, notice, err := m.advance(ctx, event.Message.ChatID, event.Sender.SenderID.OpenID, "", "", input)
+	if notice != "" {
+		uuid := WithUUID("conversation-reply/" + event.Message.MessageID + "/notice")
+		if replyErr := m.messages.ReplyMessage(event.Message.MessageID, &TextContent{Text: notice}, uuid); err == nil {
+			err = replyErr
+		}
+	}
+	return handled, err
+}
+
+// HandleCardAction 处理会话中卡片按钮的点击，可注册到CardActionHandler.On(FlowCardAction, ...)
+func (m *ConversationManager) HandleCardAction(ctx context.Context, event *CardActionEvent) (*CardResponse, error) {
+	if event.Operator == nil {
+		return nil, nil
+	}
+	text := event.ValueString("value")
+	if text == "" {
+		text = event.Option
+	}
+	input := &FlowInput{Text: text, Action: event}
+
+	handled, notice, err := m.advance(ctx, event.OpenChatID, event.Operator.OpenID, event.ValueString("flow"), event.ValueString("step"), input)
+	if err != nil {
+		return nil, err
+	}
+	if !handled {
+		return NewCardToast(CardToastInfo, "没有进行中的会话"), nil
+	}
+	if notice != "" {
+		return NewCardToast(CardToastWarning, notice), nil
+	}
+	return nil, nil
+}
+
+// advance 处理用户的回答并进入下一步；flowName和step不为空时只处理当前流程和步骤的回答。
+// notice为需要提示用户的内容，如超时、取消或回答不合法。
+func (m *ConversationManager) advance(ctx context.Context, chatID, userID, flowName, step string, input *FlowInput) (bool, string, error) {
+	key := sessionKey(chatID, userID)
+	unlock := m.lock(key)
+	defer unlock()
+
+	session, err := m.store.Get(key)
+	if errors.Is(err, ErrSessionNotFound) {
+		return false, "", nil
+	}
+	if err != nil {
+		return false, "", fmt.Errorf("get session failed: %w", err)
+	}
+
+	flow := m.flow(session.Flow)
+	var current *FlowStep
+	if flow != nil {
+		_, current = flow.step(session.Step)
+	}
+	if current == nil {
+		// 流程或步骤已不存在，丢弃会话
+		m.store.Delete(key)
+		return false, "", nil
+	}
+
+	now := m.now()
+	if !now.Before(session.ExpiresAt) {
+		m.store.Delete(key)
+		return true, "会话已超时，请重新开始", nil
+	}
+	if (flowName != "" && flowName != session.Flow) || (step != "" && step != session.Step) {
+		return true, "该选项已失效", nil
+	}
+	if input.Action == nil && m.isCancelWord(input.Text) {
+		m.store.Delete(key)
+		return true, "已取消", nil
+	}
+
+	conv := &Conversation{Session: session, Flow: flow, manager: m, input: input, step: session.Step}
+	next := ""
+	if current.Handle != nil {
+		next, err = current.Handle(ctx, conv, input)
+	} else {
+		conv.Set(current.Name, input.Text)
+	}
+
+	var inputErr *FlowInputError
+	if errors.As(err, &inputErr) {
+		session.UpdatedAt = now
+		session.ExpiresAt = now.Add(flow.timeout())
+		if err := m.store.Save(session); err != nil {
+			return true, "", fmt.Errorf("save session failed: %w", err)
+		}
+		if err := conv.ReplyText(inputErr.Message); err != nil {
+			return true, "", err
+		}
+		return true, "", m.prompt(conv, current)
+	}
+	if err != nil {
+		m.store.Delete(key)
+		return true, "", fmt.Errorf("handle step %s of flow %s failed: %w", current.Name, flow.Name, err)
+	}
+
+	nextStep, err := m.nextStep(flow, current, next)
+	if err != nil {
+		m.store.Delete(key)
+		return true, "", err
+	}
+	if nextStep == nil {
+		if err := m.store.Delete(key); err != nil && !errors.Is(err, ErrSessionNotFound) {
+			return true, "", fmt.Errorf("delete session failed: %w", err)
+		}
+		if flow.OnComplete != nil {
+			return true, "", flow.OnComplete(ctx, conv)
+		}
+		return true, "", nil
+	}
+
+	// 先提问再保存，提问失败时会话仍停留在当前步骤，事件重新推送时重新处理同一个回答
+	session.Step = nextStep.Name
+	session.UpdatedAt = now
+	session.ExpiresAt = now.Add(flow.timeout())
+	if err := m.prompt(conv, nextStep); err != nil {
+		return true, "", err
+	}
+	if err := m.store.Save(session); err != nil {
+		return true, "", fmt.Errorf("save session failed: %w", err)
+	}
+	return true, "", nil
+}
+
+// nextStep 根据步骤处理函数的返回值确定下一步，结束时返回nil
+func (m *ConversationManager) nextStep(flow *Flow, current *FlowStep, next string) (*FlowStep, error) {
+	switch next {
+	case FlowEnd:
+		return nil, nil
+	case "":
+		i, _ := flow.step(current.Name)
+		if i+1 >= len(flow.Steps) {
+			return nil, nil
+		}
+		return flow.Steps[i+1], nil
+	}
+	if _, step := flow.step(next); step != nil {
+		return step, nil
+	}
+	return nil, fmt.Errorf("flow %s has no step %s", flow.Name, next)
+}
+
+// prompt 发送步骤的提问
+func (m *ConversationManager) prompt(conv *Conversation, step *FlowStep) error {
+	if step.Prompt == nil {
+		return nil
+	}
+	content := step.Prompt(conv)
+	if content == nil {
+		return nil
+	}
+	return conv.Reply(content)
+}
+
+// flow 获取已注册的流程
+func (m *ConversationManager) flow(name string) *Flow {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.flows[name]
+}
+
+// isCancelWord 判断文本是否为取消会话
+func (m *ConversationManager) isCancelWord(text string) bool {
+	text = strings.TrimSpace(text)
+	for _, word := range m.CancelWords {
+		if strings.EqualFold(text, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// lock 锁定会话，同一用户的回答按顺序处理，返回解锁函数
+func (m *ConversationManager) lock(key string) func() {
+	m.mu.Lock()
+	l, ok := m.locks[key]
+	if !ok {
+		l = &sessionLock{}
+		m.locks[key] = l
+	}
+	l.refs++
+	m.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		m.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(m.locks, key)
+		}
+		m.mu.Unlock()
+	}
+}
+
+// stripLeadingMentions 去掉文本开头的@，如群聊中回答时@机器人
+func stripLeadingMentions(text string, mentions []*Mention) string {
+	text = strings.TrimSpace(text)
+	for trimmed := true; trimmed; {
+		trimmed = false
+		for _, mention := range mentions {
+			if mention != nil && mention.Key != "" && strings.HasPrefix(text, mention.Key) {
+				text = strings.TrimSpace(strings.TrimPrefix(text, mention.Key))
+				trimmed = true
+			}
+		}
+	}
+	return text
+}
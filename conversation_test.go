@@ -0,0 +1,333 @@
+package easylark
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newConversationTestManager 创建记录发送内容的会话管理器，文本记录内容，其他消息记录类型和原始content
+func newConversationTestManager(t *testing.T) (*ConversationManager, *[]string) {
+	var sent []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var reqBody struct {
+			MsgType string          `json:"msg_type"`
+			Content json.RawMessage `json:"content"`
+		}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		prefix := "send:"
+		if strings.HasSuffix(r.URL.Path, "/reply") {
+			prefix = "reply:"
+		}
+		if reqBody.MsgType == "text" {
+			var content map[string]string
+			json.Unmarshal(reqBody.Content, &content)
+			sent = append(sent, prefix+content["text"])
+		} else {
+			sent = append(sent, prefix+reqBody.MsgType+":"+string(reqBody.Content))
+		}
+		writeTestResponse(w, map[string]string{"message_id": "om_reply"})
+	})
+	return NewConversationManager(client.Message, nil), &sent
+}
+
+// newConversationMessage 构造用户在群中发送的文本消息
+func newConversationMessage(text string, mentions ...*Mention) *MessageReceiveEvent {
+	event := newCommandEvent("group", text, mentions...)
+	event.Message.MessageID = "om_answer"
+	return event
+}
+
+// newConversationAction 构造用户点击会话卡片按钮的回调
+func newConversationAction(step, value string) *CardActionEvent {
+	return &CardActionEvent{
+		OpenChatID: "oc_1",
+		Operator:   &CardActionOperator{OpenID: "ou_alice"},
+		Value:      map[string]interface{}{"action": FlowCardAction, "flow": "deploy", "step": step, "value": value},
+	}
+}
+
+// newDeployFlow 选择环境、输入版本号、确认的部署流程
+func newDeployFlow(completed *map[string]string) *Flow {
+	return &Flow{
+		Name: "deploy",
+		Steps: []*FlowStep{
+			{
+				Name: "env",
+				Prompt: func(conv *Conversation) MessageContent {
+					return conv.ChoiceCard("部署到哪个环境？", "staging", "prod")
+				},
+			},
+			{
+				Name: "version",
+				Prompt: func(conv *Conversation) MessageContent {
+					return &TextContent{Text: "请输入版本号"}
+				},
+				Handle: func(ctx context.Context, conv *Conversation, input *FlowInput) (string, error) {
+					if !strings.HasPrefix(input.Text, "v") {
+						return "", NewFlowInputError("版本号需要以v开头")
+					}
+					conv.Set("version", input.Text)
+					if conv.Get("env") != "prod" {
+						return FlowEnd, nil
+					}
+					return "", nil
+				},
+			},
+			{
+				Name: "confirm",
+				Prompt: func(conv *Conversation) MessageContent {
+					return conv.ChoiceCard("确认部署"+conv.Get("version")+"到生产环境？", "确认", "放弃")
+				},
+				Handle: func(ctx context.Context, conv *Conversation, input *FlowInput) (string, error) {
+					if input.Text != "确认" {
+						conv.Set("aborted", "true")
+					}
+					return "", nil
+				},
+			},
+		},
+		OnComplete: func(ctx context.Context, conv *Conversation) error {
+			*completed = conv.Session.Data
+			return conv.ReplyText("完成")
+		},
+	}
+}
+
+func TestConversationManager(t *testing.T) {
+	manager, sent := newConversationTestManager(t)
+	var completed map[string]string
+	if err := manager.Register(newDeployFlow(&completed)); err != nil {
+		t.Fatalf("register flow failed: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := manager.Start(ctx, "deploy", "oc_1", "ou_alice", map[string]string{"service": "api"}); err != nil {
+		t.Fatalf("start flow failed: %v", err)
+	}
+	if len(*sent) != 1 || !strings.HasPrefix((*sent)[0], "send:interactive:") ||
+		!strings.Contains((*sent)[0], FlowCardAction) || !strings.Contains((*sent)[0], `"step":"env"`) {
+		t.Fatalf("Expected choice card sent to chat, got %q", *sent)
+	}
+
+	// 旧步骤的按钮已失效
+	*sent = nil
+	resp, err := manager.HandleCardAction(ctx, newConversationAction("confirm", "确认"))
+	if err != nil || resp == nil || resp.Toast == nil || resp.Toast.Content != "该选项已失效" {
+		t.Errorf("Expected stale step toast, got %+v %v", resp, err)
+	}
+	// 其他流程的按钮
+	action := newConversationAction("env", "prod")
+	action.Value["flow"] = "rollback"
+	resp, err = manager.HandleCardAction(ctx, action)
+	if err != nil || resp == nil || resp.Toast == nil || resp.Toast.Content != "该选项已失效" {
+		t.Errorf("Expected stale flow toast, got %+v %v", resp, err)
+	}
+
+	// 点击按钮回答，进入文本输入的步骤
+	resp, err = manager.HandleCardAction(ctx, newConversationAction("env", "prod"))
+	if err != nil || resp != nil {
+		t.Fatalf("Expected card action to be handled, got %+v %v", resp, err)
+	}
+	session, err := manager.Active("oc_1", "ou_alice")
+	if err != nil || session.Step != "version" || session.Data["env"] != "prod" {
+		t.Fatalf("Unexpected session %+v %v", session, err)
+	}
+
+	// 不合法的回答提示后重新提问，群聊中@机器人的回答去掉@
+	*sent = nil
+	bot := &Mention{Key: "@_user_1", OpenID: "ou_bot"}
+	handled, err := manager.HandleMessage(ctx, newConversationMessage("@_user_1 1.2.0", bot))
+	if !handled || err != nil {
+		t.Fatalf("Expected message to be handled, got %v %v", handled, err)
+	}
+	if strings.Join(*sent, "|") != "reply:版本号需要以v开头|reply:请输入版本号" {
+		t.Errorf("Unexpected replies %q", *sent)
+	}
+
+	*sent = nil
+	manager.HandleMessage(ctx, newConversationMessage("@_user_1 v1.2.0", bot))
+	if len(*sent) != 1 || !strings.Contains((*sent)[0], "确认部署v1.2.0到生产环境") {
+		t.Errorf("Expected confirm card, got %q", *sent)
+	}
+
+	*sent = nil
+	if _, err := manager.HandleCardAction(ctx, newConversationAction("confirm", "确认")); err != nil {
+		t.Fatalf("handle confirm failed: %v", err)
+	}
+	if completed["service"] != "api" || completed["env"] != "prod" || completed["version"] != "v1.2.0" || completed["aborted"] != "" {
+		t.Errorf("Unexpected completed data %v", completed)
+	}
+	if strings.Join(*sent, "|") != "send:完成" {
+		t.Errorf("Unexpected replies %q", *sent)
+	}
+	if _, err := manager.Active("oc_1", "ou_alice"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected session to be removed, got %v", err)
+	}
+
+	// 没有会话时交给下一个处理函数
+	nextCalled := false
+	handler := manager.MessageHandler(func(ctx context.Context, event *MessageReceiveEvent) error {
+		nextCalled = true
+		return nil
+	})
+	if err := handler(ctx, newConversationMessage("hello")); err != nil || !nextCalled {
+		t.Errorf("Expected next handler to be called, got %v", err)
+	}
+	resp, _ = manager.HandleCardAction(ctx, newConversationAction("env", "prod"))
+	if resp == nil || resp.Toast == nil || resp.Toast.Content != "没有进行中的会话" {
+		t.Errorf("Expected no session toast, got %+v", resp)
+	}
+}
+
+func TestConversationTimeoutAndCancel(t *testing.T) {
+	manager, sent := newConversationTestManager(t)
+	var completed map[string]string
+	flow := newDeployFlow(&completed)
+	flow.Timeout = time.Minute
+	manager.Register(flow)
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	manager.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	manager.Start(ctx, "deploy", "oc_1", "ou_alice", nil)
+	now = now.Add(2 * time.Minute)
+	if _, err := manager.Active("oc_1", "ou_alice"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected expired session to be inactive, got %v", err)
+	}
+	*sent = nil
+	handled, err := manager.HandleMessage(ctx, newConversationMessage("staging"))
+	if !handled || err != nil || strings.Join(*sent, "|") != "reply:会话已超时，请重新开始" {
+		t.Errorf("Expected timeout reply, got %v %v %q", handled, err, *sent)
+	}
+	if handled, _ := manager.HandleMessage(ctx, newConversationMessage("staging")); handled {
+		t.Error("Expected expired session to be removed")
+	}
+
+	manager.Start(ctx, "deploy", "oc_1", "ou_alice", nil)
+	*sent = nil
+	manager.HandleMessage(ctx, newConversationMessage("取消"))
+	if strings.Join(*sent, "|") != "reply:已取消" {
+		t.Errorf("Expected cancel reply, got %q", *sent)
+	}
+	if _, err := manager.Active("oc_1", "ou_alice"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected session to be cancelled, got %v", err)
+	}
+	if completed != nil {
+		t.Errorf("Expected flow not to complete, got %v", completed)
+	}
+}
+
+func TestConversationPromptFailure(t *testing.T) {
+	fail := false
+	var uuids []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var reqBody struct {
+			UUID string `json:"uuid"`
+		}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		uuids = append(uuids, reqBody.UUID)
+		if fail {
+			w.Write([]byte(`{"code":230020,"msg":"rate limited"}`))
+			return
+		}
+		writeTestResponse(w, map[string]string{"message_id": "om_reply"})
+	})
+	manager := NewConversationManager(client.Message, nil)
+	var completed map[string]string
+	manager.Register(newDeployFlow(&completed))
+	ctx := context.Background()
+	manager.Start(ctx, "deploy", "oc_1", "ou_alice", nil)
+
+	// 下一步的提问发送失败时会话停留在当前步骤，重新推送的回答仍然有效
+	fail = true
+	uuids = nil
+	action := newConversationAction("env", "prod")
+	action.Token = "c-token"
+	if _, err := manager.HandleCardAction(ctx, action); err == nil {
+		t.Fatal("Expected prompt error")
+	}
+	if session, err := manager.Active("oc_1", "ou_alice"); err != nil || session.Step != "env" {
+		t.Fatalf("Expected session to stay at env, got %+v %v", session, err)
+	}
+
+	fail = false
+	if resp, err := manager.HandleCardAction(ctx, action); err != nil || resp != nil {
+		t.Fatalf("Expected redelivered action to be handled, got %+v %v", resp, err)
+	}
+	if session, err := manager.Active("oc_1", "ou_alice"); err != nil || session.Step != "version" {
+		t.Fatalf("Expected session at version, got %+v %v", session, err)
+	}
+	// 重新推送时使用相同的uuid，服务端不会重复发送
+	if len(uuids) != 2 || uuids[0] == "" || uuids[0] != uuids[1] {
+		t.Errorf("Expected the same uuid for redelivered prompt, got %q", uuids)
+	}
+
+	uuids = nil
+	manager.HandleMessage(ctx, newConversationMessage("1.2.0"))
+	if len(uuids) != 2 || uuids[0] == "" || uuids[0] == uuids[1] {
+		t.Errorf("Expected distinct uuids for replies to one message, got %q", uuids)
+	}
+}
+
+func TestConversationManagerRegister(t *testing.T) {
+	manager, _ := newConversationTestManager(t)
+	handle := func(ctx context.Context, conv *Conversation, input *FlowInput) (string, error) { return "", nil }
+	cases := []*Flow{
+		{Steps: []*FlowStep{{Name: "a"}}},
+		{Name: "empty"},
+		{Name: "dup", Steps: []*FlowStep{{Name: "a"}, {Name: "a", Handle: handle}}},
+	}
+	for _, flow := range cases {
+		if err := manager.Register(flow); err == nil {
+			t.Errorf("Expected error for flow %q", flow.Name)
+		}
+	}
+	manager.Register(&Flow{Name: "ok", Steps: []*FlowStep{{Name: "a"}}})
+	if err := manager.Register(&Flow{Name: "ok", Steps: []*FlowStep{{Name: "a"}}}); err == nil {
+		t.Error("Expected error for duplicate flow")
+	}
+	if _, err := manager.Start(context.Background(), "missing", "oc_1", "ou_alice", nil); err == nil {
+		t.Error("Expected error for unknown flow")
+	}
+}
+
+func TestMemorySessionStore(t *testing.T) {
+	store := NewMemorySessionStore()
+	session := &Session{ChatID: "oc_1", UserID: "ou_1", Flow: "deploy", Step: "env", Data: map[string]string{"a": "1"}}
+	store.Save(session)
+	session.Data["a"] = "2"
+
+	got, err := store.Get("oc_1/ou_1")
+	if err != nil || got.Data["a"] != "1" {
+		t.Errorf("Expected stored copy, got %+v %v", got, err)
+	}
+	if err := store.Delete("oc_1/ou_1"); err != nil {
+		t.Errorf("delete failed: %v", err)
+	}
+	if _, err := store.Get("oc_1/ou_1"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound, got %v", err)
+	}
+	if err := store.Delete("oc_1/ou_1"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound, got %v", err)
+	}
+
+	// 保存时清理已超时的会话
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	store = NewMemorySessionStore()
+	store.now = func() time.Time { return now }
+	store.Save(&Session{ChatID: "oc_1", UserID: "ou_1", ExpiresAt: now.Add(time.Minute)})
+	store.Save(&Session{ChatID: "oc_1", UserID: "ou_2", ExpiresAt: now.Add(time.Hour)})
+	now = now.Add(2 * time.Minute)
+	store.Save(&Session{ChatID: "oc_1", UserID: "ou_3", ExpiresAt: now.Add(time.Hour)})
+	if _, err := store.Get("oc_1/ou_1"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected expired session to be swept, got %v", err)
+	}
+	if _, err := store.Get("oc_1/ou_2"); err != nil {
+		t.Errorf("Expected active session to be kept, got %v", err)
+	}
+}
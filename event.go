@@ -0,0 +1,480 @@
+package easylark
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// 事件推送请求头
+const (
+	HeaderLarkTimestamp = "X-Lark-Request-Timestamp"
+	HeaderLarkNonce     = "X-Lark-Request-Nonce"
+	HeaderLarkSignature = "X-Lark-Signature"
+)
+
+// maxEventBodySize 事件请求体的大小上限
+const maxEventBodySize = 4 << 20
+
+// 事件校验错误
+var (
+	ErrEventToken     = errors.New("event verification token mismatch")
+	ErrEventSignature = errors.New("event signature mismatch")
+)
+
+// ErrEventQueueFull 异步处理的队列已满
+var ErrEventQueueFull = errors.New("event queue is full")
+
+// 异步处理事件的默认配置
+const (
+	defaultEventWorkers   = 16
+	defaultEventQueueSize = 1024
+)
+
+// Event 飞书推送的事件，兼容v1和v2（schema 2.0）格式
+type Event struct {
+	Schema     string // v2事件为"2.0"，v1事件为空
+	EventID    string // v2为header.event_id，v1为uuid，可用于去重
+	EventType  string // v2为header.event_type，v1为event.type
+	CreateTime string // v2为header.create_time（毫秒），v1为ts
+	Token      string
+	AppID      string
+	TenantKey  string
+
+	// Event 事件内容，即payload中的event字段
+	Event json.RawMessage
+	// Raw 解密后的完整payload
+	Raw json.RawMessage
+}
+
+// Decode 将事件内容解析到v
+func (e *Event) Decode(v interface{}) error {
+	if err := json.Unmarshal(e.Event, v); err != nil {
+		return fmt.Errorf("decode event %s failed: %w", e.EventType, err)
+	}
+	return nil
+}
+
+// EventHandlerFunc 事件处理函数，同步处理时返回错误会响应500，飞书会重新推送
+type EventHandlerFunc func(ctx context.Context, event *Event) error
+
+// EventHandler 接收飞书事件推送的http.Handler
+//
+// 自动响应url_verification请求；配置了VerificationToken时校验token；
+// 配置了EncryptKey时校验X-Lark-Signature签名并解密encrypt字段。
+// 配置了Seen时按事件ID去重，重复推送的事件直接响应成功。
+//
+// 飞书在3秒内未收到响应时会重新推送，因此默认收到事件后立即响应，
+// 由Workers个worker异步处理；设置Sync后改为处理完成再响应。
+type EventHandler struct {
+	VerificationToken string
+	EncryptKey        string
+	Handler           EventHandlerFunc
+
+	// Sync 为true时同步处理事件，处理失败时响应500，飞书会重新推送
+	Sync bool
+	// Workers 异步处理的worker数量，默认16
+	Workers int
+	// QueueSize 异步处理的队列长度，默认1024
+	QueueSize int
+
+	// Seen 事件去重存储，为nil时不去重
+	Seen SeenStore
+	// OnDuplicate 收到重复事件时调用
+	OnDuplicate func(event *Event)
+	// OnDrop 异步队列已满丢弃事件时调用，此时响应503，飞书会重新推送
+	OnDrop func(event *Event)
+	// OnError 事件处理失败时调用
+	OnError func(event *Event, err error)
+
+	mu      sync.RWMutex
+	queue   chan *Event
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+// NewEventHandler 创建事件推送处理器，verificationToken和encryptKey为开发者后台的配置，未开启时传空；
+// 默认使用MemorySeenStore去重
+func NewEventHandler(verificationToken, encryptKey string, handler EventHandlerFunc) *EventHandler {
+	return &EventHandler{
+		VerificationToken: verificationToken,
+		EncryptKey:        encryptKey,
+		Handler:           handler,
+		Seen:              NewMemorySeenStore(0, 0),
+	}
+}
+
+// StartAsync 启动workers个worker异步处理事件，队列最多缓存queueSize个事件
+//
+// 未调用StartAsync时，收到第一个事件后按Workers和QueueSize自动启动。
+// 异步处理时事件在处理前已响应成功，处理失败不会触发飞书重新推送，只会调用OnError。
+func (h *EventHandler) StartAsync(workers, queueSize int) {
+	h.start(workers, queueSize, true)
+}
+
+// start 启动异步处理，已启动时不做任何事；restart为false时Stop后不再启动
+func (h *EventHandler) start(workers, queueSize int, restart bool) {
+	if workers <= 0 {
+		workers = defaultEventWorkers
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.queue != nil || (h.stopped && !restart) {
+		return
+	}
+	h.stopped = false
+	h.queue = make(chan *Event, queueSize)
+	for i := 0; i < workers; i++ {
+		h.wg.Add(1)
+		go h.work(h.queue)
+	}
+}
+
+// Stop 停止异步处理，等待队列中的事件处理完成；之后收到的事件改为同步处理
+func (h *EventHandler) Stop() {
+	h.mu.Lock()
+	queue := h.queue
+	h.queue = nil
+	h.stopped = true
+	h.mu.Unlock()
+
+	if queue != nil {
+		close(queue)
+		h.wg.Wait()
+	}
+}
+
+// work 从队列中取出事件处理
+func (h *EventHandler) work(queue <-chan *Event) {
+	defer h.wg.Done()
+	for event := range queue {
+		h.handle(context.Background(), event)
+	}
+}
+
+// enqueue 将事件放入异步队列，同步处理或已Stop时返回false
+func (h *EventHandler) enqueue(event *Event) (bool, error) {
+	if h.Sync {
+		return false, nil
+	}
+	h.mu.RLock()
+	started := h.queue != nil || h.stopped
+	h.mu.RUnlock()
+	if !started {
+		queueSize := h.QueueSize
+		if queueSize <= 0 {
+			queueSize = defaultEventQueueSize
+		}
+		h.start(h.Workers, queueSize, false)
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.queue == nil {
+		return false, nil
+	}
+	select {
+	case h.queue <- event:
+		return true, nil
+	default:
+		return true, ErrEventQueueFull
+	}
+}
+
+// handle 调用处理函数
+func (h *EventHandler) handle(ctx context.Context, event *Event) error {
+	if h.Handler == nil {
+		return nil
+	}
+	err := h.Handler(ctx, event)
+	if err != nil && h.OnError != nil {
+		h.OnError(event, err)
+	}
+	return err
+}
+
+// markSeen 记录事件ID，返回事件是否重复；去重存储出错时按未重复处理
+func (h *EventHandler) markSeen(event *Event) bool {
+	if h.Seen == nil || event.EventID == "" {
+		return false
+	}
+	added, err := h.Seen.Add(event.EventID)
+	return err == nil && !added
+}
+
+// forget 删除事件ID，使飞书重新推送的事件能被处理
+func (h *EventHandler) forget(event *Event) {
+	if h.Seen != nil && event.EventID != "" {
+		h.Seen.Remove(event.EventID)
+	}
+}
+
+// eventEnvelope 事件推送的外层结构，包含v1、v2、url_verification和加密格式的字段
+type eventEnvelope struct {
+	Encrypt   string `json:"encrypt"`
+	Challenge string `json:"challenge"`
+	Type      string `json:"type"`
+	Token     string `json:"token"`
+
+	// v1
+	UUID string `json:"uuid"`
+	TS   string `json:"ts"`
+
+	// v2
+	Schema string `json:"schema"`
+	Header *struct {
+		EventID    string `json:"event_id"`
+		EventType  string `json:"event_type"`
+		CreateTime string `json:"create_time"`
+		Token      string `json:"token"`
+		AppID      string `json:"app_id"`
+		TenantKey  string `json:"tenant_key"`
+	} `json:"header"`
+
+	Event json.RawMessage `json:"event"`
+}
+
+// ServeHTTP 实现http.Handler接口
+func (h *EventHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxEventBodySize))
+	if err != nil {
+		http.Error(w, "read body failed", http.StatusBadRequest)
+		return
+	}
+
+	event, challenge, err := h.parse(r.Header, body)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrEventToken) || errors.Is(err, ErrEventSignature) {
+			status = http.StatusUnauthorized
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	if event == nil {
+		writeEventJSON(w, http.StatusOK, map[string]string{"challenge": challenge})
+		return
+	}
+
+	if status, err := h.process(r.Context(), event); err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	writeEventJSON(w, http.StatusOK, map[string]string{"msg": "success"})
+}
+
+// process 去重后同步或异步处理已校验的事件，失败时返回响应的HTTP状态码
+func (h *EventHandler) process(ctx context.Context, event *Event) (int, error) {
+	if h.markSeen(event) {
+		if h.OnDuplicate != nil {
+			h.OnDuplicate(event)
+		}
+		return http.StatusOK, nil
+	}
+
+	async, err := h.enqueue(event)
+	if err != nil {
+		h.forget(event)
+		if h.OnDrop != nil {
+			h.OnDrop(event)
+		}
+		return http.StatusServiceUnavailable, err
+	}
+	if !async {
+		if err := h.handle(ctx, event); err != nil {
+			h.forget(event)
+			return http.StatusInternalServerError, fmt.Errorf("handle event failed")
+		}
+	}
+	return http.StatusOK, nil
+}
+
+// parse 校验并解析事件推送，url_verification请求返回challenge且event为nil
+func (h *EventHandler) parse(header http.Header, body []byte) (*Event, string, error) {
+	var envelope eventEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, "", fmt.Errorf("parse event failed: %w", err)
+	}
+
+	payload := body
+	if envelope.Encrypt != "" {
+		if h.EncryptKey == "" {
+			return nil, "", fmt.Errorf("parse event failed: event is encrypted but encrypt key is not configured")
+		}
+		decrypted, err := DecryptEvent(envelope.Encrypt, h.EncryptKey)
+		if err != nil {
+			return nil, "", err
+		}
+		payload = decrypted
+		envelope = eventEnvelope{}
+		if err := json.Unmarshal(payload, &envelope); err != nil {
+			return nil, "", fmt.Errorf("parse decrypted event failed: %w", err)
+		}
+	}
+
+	// url_verification请求不携带签名，只校验token
+	if envelope.Type == "url_verification" {
+		if err := h.verifyToken(envelope.Token); err != nil {
+			return nil, "", err
+		}
+		return nil, envelope.Challenge, nil
+	}
+
+	if h.EncryptKey != "" {
+		if err := VerifyEventSignature(header, body, h.EncryptKey); err != nil {
+			return nil, "", err
+		}
+	}
+
+	event := &Event{Event: envelope.Event, Raw: payload}
+	if envelope.Header != nil {
+		event.Schema = envelope.Schema
+		event.EventID = envelope.Header.EventID
+		event.EventType = envelope.Header.EventType
+		event.CreateTime = envelope.Header.CreateTime
+		event.Token = envelope.Header.Token
+		event.AppID = envelope.Header.AppID
+		event.TenantKey = envelope.Header.TenantKey
+	} else {
+		var inner struct {
+			Type      string `json:"type"`
+			AppID     string `json:"app_id"`
+			TenantKey string `json:"tenant_key"`
+		}
+		if len(envelope.Event) > 0 {
+			if err := json.Unmarshal(envelope.Event, &inner); err != nil {
+				return nil, "", fmt.Errorf("parse event failed: %w", err)
+			}
+		}
+		event.EventID = envelope.UUID
+		event.EventType = inner.Type
+		event.CreateTime = envelope.TS
+		event.Token = envelope.Token
+		event.AppID = inner.AppID
+		event.TenantKey = inner.TenantKey
+	}
+
+	if event.EventType == "" {
+		return nil, "", fmt.Errorf("parse event failed: missing event type")
+	}
+	if err := h.verifyToken(event.Token); err != nil {
+		return nil, "", err
+	}
+	return event, "", nil
+}
+
+// verifyToken 校验verification token，未配置时跳过
+func (h *EventHandler) verifyToken(token string) error {
+	return verifyEventToken(h.VerificationToken, token)
+}
+
+// verifyEventToken 校验token与配置的verification token一致，未配置时跳过
+func verifyEventToken(verificationToken, token string) error {
+	if verificationToken == "" {
+		return nil
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(verificationToken)) != 1 {
+		return ErrEventToken
+	}
+	return nil
+}
+
+// VerifyEventSignature 校验事件推送的签名，签名为sha256(timestamp+nonce+encryptKey+body)的十六进制
+func VerifyEventSignature(header http.Header, body []byte, encryptKey string) error {
+	signature := header.Get(HeaderLarkSignature)
+	if signature == "" {
+		return ErrEventSignature
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(header.Get(HeaderLarkTimestamp)))
+	hash.Write([]byte(header.Get(HeaderLarkNonce)))
+	hash.Write([]byte(encryptKey))
+	hash.Write(body)
+	expected := hex.EncodeToString(hash.Sum(nil))
+
+	if subtle.ConstantTimeCompare([]byte(signature), []byte(expected)) != 1 {
+		return ErrEventSignature
+	}
+	return nil
+}
+
+// DecryptEvent 解密事件推送的encrypt字段
+//
+// 使用AES-256-CBC，密钥为sha256(encryptKey)，密文base64解码后前16字节为IV。
+func DecryptEvent(encrypted, encryptKey string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt event failed: %w", err)
+	}
+	if len(data) < aes.BlockSize || len(data)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("decrypt event failed: invalid ciphertext length %d", len(data))
+	}
+
+	key := sha256.Sum256([]byte(encryptKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("decrypt event failed: %w", err)
+	}
+
+	iv, ciphertext := data[:aes.BlockSize], data[aes.BlockSize:]
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+
+	plaintext, err = pkcs7Unpad(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt event failed: %w", err)
+	}
+	// 部分实现会在JSON前后附加填充字符，只保留JSON对象部分
+	start := bytes.IndexByte(plaintext, '{')
+	end := bytes.LastIndexByte(plaintext, '}')
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("decrypt event failed: plaintext is not json")
+	}
+	return plaintext[start : end+1], nil
+}
+
+// pkcs7Unpad 去除PKCS#7填充
+func pkcs7Unpad(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+	padding := int(data[len(data)-1])
+	if padding == 0 || padding > aes.BlockSize || padding > len(data) {
+		return nil, fmt.Errorf("invalid padding")
+	}
+	for _, b := range data[len(data)-padding:] {
+		if int(b) != padding {
+			return nil, fmt.Errorf("invalid padding")
+		}
+	}
+	return data[:len(data)-padding], nil
+}
+
+// writeEventJSON 写入JSON响应
+func writeEventJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,359 @@
+package easylark
+
+import (
+	"context"
+	"sync"
+)
+
+// 事件类型
+const (
+	EventTypeMessageReceive    = "im.message.receive_v1"
+	EventTypeMessageRead       = "im.message.message_read_v1"
+	EventTypeMessageRecalled   = "im.message.recalled_v1"
+	EventTypeReactionCreated   = "im.message.reaction.created_v1"
+	EventTypeReactionDeleted   = "im.message.reaction.deleted_v1"
+	EventTypeChatMemberAdded   = "im.chat.member.user.added_v1"
+	EventTypeChatMemberDeleted = "im.chat.member.user.deleted_v1"
+	EventTypeChatDisbanded     = "im.chat.disbanded_v1"
+	EventTypeChatUpdated       = "im.chat.updated_v1"
+	EventTypeBotAdded          = "im.chat.member.bot.added_v1"
+	EventTypeBotDeleted        = "im.chat.member.bot.deleted_v1"
+	EventTypeUserCreated       = "contact.user.created_v3"
+	EventTypeUserUpdated       = "contact.user.updated_v3"
+	EventTypeUserDeleted       = "contact.user.deleted_v3"
+)
+
+// EventUserID 事件中的用户ID
+type EventUserID struct {
+	OpenID  string `json:"open_id"`
+	UserID  string `json:"user_id"`
+	UnionID string `json:"union_id"`
+}
+
+// EventSender 消息事件的发送者
+type EventSender struct {
+	SenderID   *EventUserID `json:"sender_id"`
+	SenderType string       `json:"sender_type"` // user或app
+	TenantKey  string       `json:"tenant_key"`
+}
+
+// EventMessage 消息事件中的消息
+type EventMessage struct {
+	MessageID   string     `json:"message_id"`
+	RootID      string     `json:"root_id"`
+	ParentID    string     `json:"parent_id"`
+	ThreadID    string     `json:"thread_id"`
+	CreateTime  string     `json:"create_time"`
+	UpdateTime  string     `json:"update_time"`
+	ChatID      string     `json:"chat_id"`
+	ChatType    string     `json:"chat_type"` // p2p或group
+	MessageType string     `json:"message_type"`
+	Content     string     `json:"content"`
+	Mentions    []*Mention `json:"mentions"`
+	UserAgent   string     `json:"user_agent"`
+}
+
+// DecodeContent 解析消息内容，返回值与Message.DecodeContent相同
+func (m *EventMessage) DecodeContent() (MessageContent, error) {
+	return DecodeMessageContent(m.MessageType, m.Content, m.Mentions)
+}
+
+// MessageReceiveEvent 接收消息事件
+type MessageReceiveEvent struct {
+	Sender  *EventSender  `json:"sender"`
+	Message *EventMessage `json:"message"`
+}
+
+// ToMessage 转换为Message，便于复用消息相关的方法
+func (e *MessageReceiveEvent) ToMessage() *Message {
+	msg := &Message{}
+	if e.Message != nil {
+		msg.MessageID = e.Message.MessageID
+		msg.RootID = e.Message.RootID
+		msg.ParentID = e.Message.ParentID
+		msg.ThreadID = e.Message.ThreadID
+		msg.MsgType = e.Message.MessageType
+		msg.CreateTime = e.Message.CreateTime
+		msg.UpdateTime = e.Message.UpdateTime
+		msg.ChatID = e.Message.ChatID
+		msg.Body = MessageBody{Content: e.Message.Content}
+		msg.Mentions = e.Message.Mentions
+	}
+	if e.Sender != nil {
+		msg.Sender = &MessageSender{SenderType: e.Sender.SenderType, TenantKey: e.Sender.TenantKey}
+		if e.Sender.SenderID != nil {
+			msg.Sender.ID = e.Sender.SenderID.OpenID
+			msg.Sender.IDType = "open_id"
+		}
+	}
+	return msg
+}
+
+// MessageReadEvent 消息已读事件
+type MessageReadEvent struct {
+	Reader *struct {
+		ReaderID  *EventUserID `json:"reader_id"`
+		ReadTime  string       `json:"read_time"`
+		TenantKey string       `json:"tenant_key"`
+	} `json:"reader"`
+	MessageIDList []string `json:"message_id_list"`
+}
+
+// MessageRecalledEvent 消息撤回事件
+type MessageRecalledEvent struct {
+	MessageID  string `json:"message_id"`
+	ChatID     string `json:"chat_id"`
+	RecallTime string `json:"recall_time"`
+	RecallType string `json:"recall_type"`
+}
+
+// ReactionEvent 消息表情回复事件
+type ReactionEvent struct {
+	MessageID    string `json:"message_id"`
+	ReactionType *struct {
+		EmojiType string `json:"emoji_type"`
+	} `json:"reaction_type"`
+	OperatorType string       `json:"operator_type"` // user或app
+	UserID       *EventUserID `json:"user_id"`
+	AppID        string       `json:"app_id"`
+	ActionTime   string       `json:"action_time"`
+}
+
+// ChatEventUser 群事件中的用户
+type ChatEventUser struct {
+	Name      string       `json:"name"`
+	TenantKey string       `json:"tenant_key"`
+	UserID    *EventUserID `json:"user_id"`
+}
+
+// ChatMemberEvent 用户进群、出群事件
+type ChatMemberEvent struct {
+	ChatID            string           `json:"chat_id"`
+	OperatorID        *EventUserID     `json:"operator_id"`
+	External          bool             `json:"external"`
+	OperatorTenantKey string           `json:"operator_tenant_key"`
+	Users             []*ChatEventUser `json:"users"`
+	Name              string           `json:"name"`
+	I18nNames         *ChatI18nNames   `json:"i18n_names"`
+}
+
+// ChatEvent 群解散、机器人进群和出群等群事件
+type ChatEvent struct {
+	ChatID            string         `json:"chat_id"`
+	OperatorID        *EventUserID   `json:"operator_id"`
+	External          bool           `json:"external"`
+	OperatorTenantKey string         `json:"operator_tenant_key"`
+	Name              string         `json:"name"`
+	I18nNames         *ChatI18nNames `json:"i18n_names"`
+}
+
+// ContactUser 通讯录事件中的用户
+type ContactUser struct {
+	OpenID        string   `json:"open_id"`
+	UserID        string   `json:"user_id"`
+	UnionID       string   `json:"union_id"`
+	Name          string   `json:"name"`
+	EnName        string   `json:"en_name"`
+	Email         string   `json:"email"`
+	Mobile        string   `json:"mobile"`
+	DepartmentIDs []string `json:"department_ids"`
+	LeaderUserID  string   `json:"leader_user_id"`
+	JobTitle      string   `json:"job_title"`
+	EmployeeNo    string   `json:"employee_no"`
+}
+
+// ContactUserEvent 员工入职、变更和离职事件，OldObject仅在变更事件中包含变更前的字段
+type ContactUserEvent struct {
+	Object    *ContactUser `json:"object"`
+	OldObject *ContactUser `json:"old_object"`
+}
+
+// eventContextKey 在context中保存原始事件的key
+type eventContextKey struct{}
+
+// EventFromContext 获取当前处理的原始事件，可用于在类型化的处理函数中读取event_id等头部信息
+func EventFromContext(ctx context.Context) *Event {
+	event, _ := ctx.Value(eventContextKey{}).(*Event)
+	return event
+}
+
+// EventDispatcher 按事件类型分发事件，Dispatch可直接作为EventHandler的处理函数
+//
+//	dispatcher := easylark.NewEventDispatcher().
+//		OnMessageReceive(func(ctx context.Context, event *easylark.MessageReceiveEvent) error {
+//			...
+//		})
+//	http.Handle("/webhook/event", easylark.NewEventHandler(token, key, dispatcher.Dispatch))
+type EventDispatcher struct {
+	mu       sync.RWMutex
+	handlers map[string]EventHandlerFunc
+	fallback EventHandlerFunc
+}
+
+// NewEventDispatcher 创建事件分发器
+func NewEventDispatcher() *EventDispatcher {
+	return &EventDispatcher{
+		handlers: make(map[string]EventHandlerFunc),
+	}
+}
+
+// On 注册指定类型事件的处理函数，同一类型重复注册会覆盖；v1事件的类型为event.type，如message
+func (d *EventDispatcher) On(eventType string, handler EventHandlerFunc) *EventDispatcher {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.handlers == nil {
+		d.handlers = make(map[string]EventHandlerFunc)
+	}
+	d.handlers[eventType] = handler
+	return d
+}
+
+// OnDefault 注册未匹配任何类型时的处理函数
+func (d *EventDispatcher) OnDefault(handler EventHandlerFunc) *EventDispatcher {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.fallback = handler
+	return d
+}
+
+// Dispatch 分发事件，没有匹配的处理函数时忽略事件
+func (d *EventDispatcher) Dispatch(ctx context.Context, event *Event) error {
+	d.mu.RLock()
+	handler, ok := d.handlers[event.EventType]
+	if !ok {
+		handler = d.fallback
+	}
+	d.mu.RUnlock()
+
+	if handler == nil {
+		return nil
+	}
+	return handler(context.WithValue(ctx, eventContextKey{}, event), event)
+}
+
+// OnMessageReceive 注册接收消息事件的处理函数
+func (d *EventDispatcher) OnMessageReceive(handler func(ctx context.Context, event *MessageReceiveEvent) error) *EventDispatcher {
+	return d.On(EventTypeMessageReceive, func(ctx context.Context, event *Event) error {
+		payload := &MessageReceiveEvent{}
+		if err := event.Decode(payload); err != nil {
+			return err
+		}
+		return handler(ctx, payload)
+	})
+}
+
+// OnMessageRead 注册消息已读事件的处理函数
+func (d *EventDispatcher) OnMessageRead(handler func(ctx context.Context, event *MessageReadEvent) error) *EventDispatcher {
+	return d.On(EventTypeMessageRead, func(ctx context.Context, event *Event) error {
+		payload := &MessageReadEvent{}
+		if err := event.Decode(payload); err != nil {
+			return err
+		}
+		return handler(ctx, payload)
+	})
+}
+
+// OnMessageRecalled 注册消息撤回事件的处理函数
+func (d *EventDispatcher) OnMessageRecalled(handler func(ctx context.Context, event *MessageRecalledEvent) error) *EventDispatcher {
+	return d.On(EventTypeMessageRecalled, func(ctx context.Context, event *Event) error {
+		payload := &MessageRecalledEvent{}
+		if err := event.Decode(payload); err != nil {
+			return err
+		}
+		return handler(ctx, payload)
+	})
+}
+
+// OnReactionCreated 注册新增表情回复事件的处理函数
+func (d *EventDispatcher) OnReactionCreated(handler func(ctx context.Context, event *ReactionEvent) error) *EventDispatcher {
+	return d.onReaction(EventTypeReactionCreated, handler)
+}
+
+// OnReactionDeleted 注册删除表情回复事件的处理函数
+func (d *EventDispatcher) OnReactionDeleted(handler func(ctx context.Context, event *ReactionEvent) error) *EventDispatcher {
+	return d.onReaction(EventTypeReactionDeleted, handler)
+}
+
+// onReaction 注册表情回复事件的处理函数
+func (d *EventDispatcher) onReaction(eventType string, handler func(ctx context.Context, event *ReactionEvent) error) *EventDispatcher {
+	return d.On(eventType, func(ctx context.Context, event *Event) error {
+		payload := &ReactionEvent{}
+		if err := event.Decode(payload); err != nil {
+			return err
+		}
+		return handler(ctx, payload)
+	})
+}
+
+// OnChatMemberAdded 注册用户进群事件的处理函数
+func (d *EventDispatcher) OnChatMemberAdded(handler func(ctx context.Context, event *ChatMemberEvent) error) *EventDispatcher {
+	return d.onChatMember(EventTypeChatMemberAdded, handler)
+}
+
+// OnChatMemberDeleted 注册用户出群事件的处理函数
+func (d *EventDispatcher) OnChatMemberDeleted(handler func(ctx context.Context, event *ChatMemberEvent) error) *EventDispatcher {
+	return d.onChatMember(EventTypeChatMemberDeleted, handler)
+}
+
+// onChatMember 注册用户进出群事件的处理函数
+func (d *EventDispatcher) onChatMember(eventType string, handler func(ctx context.Context, event *ChatMemberEvent) error) *EventDispatcher {
+	return d.On(eventType, func(ctx context.Context, event *Event) error {
+		payload := &ChatMemberEvent{}
+		if err := event.Decode(payload); err != nil {
+			return err
+		}
+		return handler(ctx, payload)
+	})
+}
+
+// OnChatDisbanded 注册群解散事件的处理函数
+func (d *EventDispatcher) OnChatDisbanded(handler func(ctx context.Context, event *ChatEvent) error) *EventDispatcher {
+	return d.onChat(EventTypeChatDisbanded, handler)
+}
+
+// OnBotAdded 注册机器人进群事件的处理函数
+func (d *EventDispatcher) OnBotAdded(handler func(ctx context.Context, event *ChatEvent) error) *EventDispatcher {
+	return d.onChat(EventTypeBotAdded, handler)
+}
+
+// OnBotDeleted 注册机器人被移出群事件的处理函数
+func (d *EventDispatcher) OnBotDeleted(handler func(ctx context.Context, event *ChatEvent) error) *EventDispatcher {
+	return d.onChat(EventTypeBotDeleted, handler)
+}
+
+// onChat 注册群事件的处理函数
+func (d *EventDispatcher) onChat(eventType string, handler func(ctx context.Context, event *ChatEvent) error) *EventDispatcher {
+	return d.On(eventType, func(ctx context.Context, event *Event) error {
+		payload := &ChatEvent{}
+		if err := event.Decode(payload); err != nil {
+			return err
+		}
+		return handler(ctx, payload)
+	})
+}
+
+// OnUserCreated 注册员工入职事件的处理函数
+func (d *EventDispatcher) OnUserCreated(handler func(ctx context.Context, event *ContactUserEvent) error) *EventDispatcher {
+	return d.onContactUser(EventTypeUserCreated, handler)
+}
+
+// OnUserUpdated 注册员工信息变更事件的处理函数
+func (d *EventDispatcher) OnUserUpdated(handler func(ctx context.Context, event *ContactUserEvent) error) *EventDispatcher {
+	return d.onContactUser(EventTypeUserUpdated, handler)
+}
+
+// OnUserDeleted 注册员工离职事件的处理函数
+func (d *EventDispatcher) OnUserDeleted(handler func(ctx context.Context, event *ContactUserEvent) error) *EventDispatcher {
+	return d.onContactUser(EventTypeUserDeleted, handler)
+}
+
+// onContactUser 注册通讯录用户事件的处理函数
+func (d *EventDispatcher) onContactUser(eventType string, handler func(ctx context.Context, event *ContactUserEvent) error) *EventDispatcher {
+	return d.On(eventType, func(ctx context.Context, event *Event) error {
+		payload := &ContactUserEvent{}
+		if err := event.Decode(payload); err != nil {
+			return err
+		}
+		return handler(ctx, payload)
+	})
+}
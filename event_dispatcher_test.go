@@ -0,0 +1,146 @@
+package easylark
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testMessageReceiveEvent = `{
+	"schema": "2.0",
+	"header": {"event_id": "ev_1", "event_type": "im.message.receive_v1", "token": "vtoken"},
+	"event": {
+		"sender": {"sender_id": {"open_id": "ou_1", "user_id": "u_1"}, "sender_type": "user", "tenant_key": "tk_1"},
+		"message": {
+			"message_id": "om_1",
+			"chat_id": "oc_1",
+			"chat_type": "group",
+			"message_type": "text",
+			"content": "{\"text\":\"@_user_1 deploy prod\"}",
+			"mentions": [{"key": "@_user_1", "id": {"open_id": "ou_bot"}, "name": "bot"}]
+		}
+	}
+}`
+
+func TestEventDispatcherMessageReceive(t *testing.T) {
+	var got *MessageReceiveEvent
+	var eventID string
+	dispatcher := NewEventDispatcher().OnMessageReceive(func(ctx context.Context, event *MessageReceiveEvent) error {
+		got = event
+		eventID = EventFromContext(ctx).EventID
+		return nil
+	})
+
+	handler := NewEventHandler("vtoken", "", dispatcher.Dispatch)
+	handler.Sync = true
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newSignedEventRequest([]byte(testMessageReceiveEvent), ""))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	if got == nil || got.Message.MessageID != "om_1" || got.Sender.SenderID.OpenID != "ou_1" {
+		t.Fatalf("Unexpected event: %+v", got)
+	}
+	if eventID != "ev_1" {
+		t.Errorf("Expected event id ev_1 from context, got %s", eventID)
+	}
+
+	content, err := got.Message.DecodeContent()
+	if err != nil {
+		t.Fatalf("decode content failed: %v", err)
+	}
+	text, ok := content.(*TextContent)
+	if !ok {
+		t.Fatalf("Expected *TextContent, got %T", content)
+	}
+	if !strings.Contains(text.Text, "deploy prod") {
+		t.Errorf("Unexpected text %q", text.Text)
+	}
+
+	msg := got.ToMessage()
+	if msg.MsgType != "text" || msg.ChatID != "oc_1" || msg.Sender.ID != "ou_1" || len(msg.Mentions) != 1 {
+		t.Errorf("Unexpected message: %+v", msg)
+	}
+}
+
+func TestEventDispatcherTypedEvents(t *testing.T) {
+	var calls []string
+	dispatcher := NewEventDispatcher().
+		OnChatMemberAdded(func(ctx context.Context, event *ChatMemberEvent) error {
+			calls = append(calls, "member:"+event.ChatID+":"+event.Users[0].UserID.OpenID)
+			return nil
+		}).
+		OnChatDisbanded(func(ctx context.Context, event *ChatEvent) error {
+			calls = append(calls, "disbanded:"+event.ChatID)
+			return nil
+		}).
+		OnBotAdded(func(ctx context.Context, event *ChatEvent) error {
+			calls = append(calls, "bot:"+event.ChatID+":"+event.OperatorID.OpenID)
+			return nil
+		}).
+		OnMessageRead(func(ctx context.Context, event *MessageReadEvent) error {
+			calls = append(calls, "read:"+event.Reader.ReaderID.OpenID+":"+strings.Join(event.MessageIDList, ","))
+			return nil
+		}).
+		OnReactionCreated(func(ctx context.Context, event *ReactionEvent) error {
+			calls = append(calls, "reaction:"+event.ReactionType.EmojiType)
+			return nil
+		}).
+		OnUserUpdated(func(ctx context.Context, event *ContactUserEvent) error {
+			calls = append(calls, "user:"+event.Object.Name+":"+event.OldObject.Name)
+			return nil
+		})
+
+	events := []*Event{
+		{EventType: EventTypeChatMemberAdded, Event: []byte(`{"chat_id":"oc_1","users":[{"name":"a","user_id":{"open_id":"ou_1"}}]}`)},
+		{EventType: EventTypeChatDisbanded, Event: []byte(`{"chat_id":"oc_2"}`)},
+		{EventType: EventTypeBotAdded, Event: []byte(`{"chat_id":"oc_3","operator_id":{"open_id":"ou_2"}}`)},
+		{EventType: EventTypeMessageRead, Event: []byte(`{"reader":{"reader_id":{"open_id":"ou_3"}},"message_id_list":["om_1","om_2"]}`)},
+		{EventType: EventTypeReactionCreated, Event: []byte(`{"message_id":"om_1","reaction_type":{"emoji_type":"THUMBSUP"}}`)},
+		{EventType: EventTypeUserUpdated, Event: []byte(`{"object":{"name":"新名字"},"old_object":{"name":"旧名字"}}`)},
+	}
+	for _, event := range events {
+		if err := dispatcher.Dispatch(context.Background(), event); err != nil {
+			t.Fatalf("dispatch %s failed: %v", event.EventType, err)
+		}
+	}
+
+	want := "member:oc_1:ou_1|disbanded:oc_2|bot:oc_3:ou_2|read:ou_3:om_1,om_2|reaction:THUMBSUP|user:新名字:旧名字"
+	if strings.Join(calls, "|") != want {
+		t.Errorf("Expected calls %s, got %v", want, calls)
+	}
+}
+
+func TestEventDispatcherFallback(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+
+	// 没有处理函数时忽略事件
+	if err := dispatcher.Dispatch(context.Background(), &Event{EventType: "unknown"}); err != nil {
+		t.Errorf("Expected unknown event to be ignored, got %v", err)
+	}
+
+	var fallback []string
+	dispatcher.OnDefault(func(ctx context.Context, event *Event) error {
+		fallback = append(fallback, event.EventType)
+		return nil
+	}).On("message", func(ctx context.Context, event *Event) error {
+		return errors.New("boom")
+	})
+
+	dispatcher.Dispatch(context.Background(), &Event{EventType: "unknown"})
+	if len(fallback) != 1 || fallback[0] != "unknown" {
+		t.Errorf("Expected fallback to receive unknown event, got %v", fallback)
+	}
+	if err := dispatcher.Dispatch(context.Background(), &Event{EventType: "message"}); err == nil {
+		t.Error("Expected handler error to be returned")
+	}
+
+	// 事件内容无法解析时返回错误
+	dispatcher.OnChatDisbanded(func(ctx context.Context, event *ChatEvent) error { return nil })
+	if err := dispatcher.Dispatch(context.Background(), &Event{EventType: EventTypeChatDisbanded, Event: []byte(`[]`)}); err == nil {
+		t.Error("Expected decode error")
+	}
+}
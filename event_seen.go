@@ -0,0 +1,103 @@
+package easylark
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// 默认的事件去重配置，飞书的重试会持续数小时
+const (
+	defaultSeenCapacity = 10000
+	defaultSeenTTL      = 24 * time.Hour
+)
+
+// SeenStore 记录已接收的事件ID，用于事件去重，可以实现为基于Redis等的共享存储
+type SeenStore interface {
+	// Add 记录事件ID，ID已存在时返回false
+	Add(eventID string) (bool, error)
+	// Remove 删除事件ID，事件处理失败需要飞书重新推送时调用
+	Remove(eventID string) error
+}
+
+// MemorySeenStore 基于内存的SeenStore，超过容量时淘汰最久未访问的ID，ID在TTL后过期
+type MemorySeenStore struct {
+	capacity int
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu    sync.Mutex
+	items map[string]*list.Element
+	order *list.List // 最近访问的在前
+}
+
+// seenEntry MemorySeenStore中的一条记录
+type seenEntry struct {
+	id      string
+	expires time.Time
+}
+
+// NewMemorySeenStore 创建基于内存的SeenStore，capacity和ttl小于等于0时使用默认值10000和24小时
+func NewMemorySeenStore(capacity int, ttl time.Duration) *MemorySeenStore {
+	if capacity <= 0 {
+		capacity = defaultSeenCapacity
+	}
+	if ttl <= 0 {
+		ttl = defaultSeenTTL
+	}
+	return &MemorySeenStore{
+		capacity: capacity,
+		ttl:      ttl,
+		now:      time.Now,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Add 记录事件ID，ID已存在且未过期时返回false
+func (s *MemorySeenStore) Add(eventID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if elem, ok := s.items[eventID]; ok {
+		if now.Before(elem.Value.(*seenEntry).expires) {
+			s.order.MoveToFront(elem)
+			return false, nil
+		}
+		s.remove(elem)
+	}
+
+	s.items[eventID] = s.order.PushFront(&seenEntry{id: eventID, expires: now.Add(s.ttl)})
+	for s.order.Len() > s.capacity {
+		s.remove(s.order.Back())
+	}
+	// 清理末尾已过期的记录
+	for back := s.order.Back(); back != nil && !now.Before(back.Value.(*seenEntry).expires); back = s.order.Back() {
+		s.remove(back)
+	}
+	return true, nil
+}
+
+// Remove 删除事件ID
+func (s *MemorySeenStore) Remove(eventID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.items[eventID]; ok {
+		s.remove(elem)
+	}
+	return nil
+}
+
+// Len 返回记录的事件ID数量，包含未清理的过期记录
+func (s *MemorySeenStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// remove 删除一条记录，调用方需持有锁
+func (s *MemorySeenStore) remove(elem *list.Element) {
+	s.order.Remove(elem)
+	delete(s.items, elem.Value.(*seenEntry).id)
+}
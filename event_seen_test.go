@@ -0,0 +1,47 @@
+package easylark
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemorySeenStore(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemorySeenStore(2, time.Minute)
+	store.now = func() time.Time { return now }
+
+	if added, _ := store.Add("ev_1"); !added {
+		t.Error("Expected ev_1 to be added")
+	}
+	if added, _ := store.Add("ev_1"); added {
+		t.Error("Expected ev_1 to be duplicate")
+	}
+
+	// 超过容量时淘汰最久未访问的ID
+	store.Add("ev_2")
+	store.Add("ev_1")
+	store.Add("ev_3")
+	if store.Len() != 2 {
+		t.Errorf("Expected 2 ids, got %d", store.Len())
+	}
+	if added, _ := store.Add("ev_1"); added {
+		t.Error("Expected recently used ev_1 to be kept")
+	}
+	if added, _ := store.Add("ev_2"); !added {
+		t.Error("Expected ev_2 to be evicted")
+	}
+
+	// 过期后可以重新添加
+	now = now.Add(2 * time.Minute)
+	if added, _ := store.Add("ev_2"); !added {
+		t.Error("Expected expired ev_2 to be added again")
+	}
+	if store.Len() != 1 {
+		t.Errorf("Expected expired ids to be cleaned up, got %d", store.Len())
+	}
+
+	store.Remove("ev_2")
+	if added, _ := store.Add("ev_2"); !added {
+		t.Error("Expected removed ev_2 to be added again")
+	}
+}
@@ -0,0 +1,360 @@
+package easylark
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// encryptTestEvent 按飞书的方式加密事件
+func encryptTestEvent(t *testing.T, plaintext []byte, encryptKey string) string {
+	t.Helper()
+	key := sha256.Sum256([]byte(encryptKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		t.Fatalf("create cipher failed: %v", err)
+	}
+	padding := aes.BlockSize - len(plaintext)%aes.BlockSize
+	plaintext = append(plaintext, bytes.Repeat([]byte{byte(padding)}, padding)...)
+
+	data := make([]byte, aes.BlockSize+len(plaintext))
+	rand.Read(data[:aes.BlockSize])
+	cipher.NewCBCEncrypter(block, data[:aes.BlockSize]).CryptBlocks(data[aes.BlockSize:], plaintext)
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// newSignedEventRequest 构造带签名的事件推送请求
+func newSignedEventRequest(body []byte, encryptKey string) *http.Request {
+	req := httptest.NewRequest("POST", "/webhook/event", bytes.NewReader(body))
+	if encryptKey != "" {
+		req.Header.Set(HeaderLarkTimestamp, "1700000000")
+		req.Header.Set(HeaderLarkNonce, "nonce")
+		sum := sha256.Sum256([]byte("1700000000" + "nonce" + encryptKey + string(body)))
+		req.Header.Set(HeaderLarkSignature, hex.EncodeToString(sum[:]))
+	}
+	return req
+}
+
+const testEventV2 = `{
+	"schema": "2.0",
+	"header": {
+		"event_id": "ev_1",
+		"event_type": "im.message.receive_v1",
+		"create_time": "1700000000000",
+		"token": "vtoken",
+		"app_id": "cli_1",
+		"tenant_key": "tk_1"
+	},
+	"event": {"message": {"message_id": "om_1"}}
+}`
+
+const testEventV1 = `{
+	"uuid": "uuid_1",
+	"ts": "1700000000.0",
+	"token": "vtoken",
+	"type": "event_callback",
+	"event": {"type": "message", "app_id": "cli_1", "tenant_key": "tk_1", "text": "hi"}
+}`
+
+func TestEventHandlerChallenge(t *testing.T) {
+	handler := NewEventHandler("vtoken", "", nil)
+
+	rec := httptest.NewRecorder()
+	body := `{"challenge":"abc","token":"vtoken","type":"url_verification"}`
+	handler.ServeHTTP(rec, newSignedEventRequest([]byte(body), ""))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"challenge":"abc"`) {
+		t.Errorf("Expected challenge response, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	body = `{"challenge":"abc","token":"wrong","type":"url_verification"}`
+	handler.ServeHTTP(rec, newSignedEventRequest([]byte(body), ""))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for wrong token, got %d", rec.Code)
+	}
+}
+
+func TestEventHandlerEncryptedChallenge(t *testing.T) {
+	handler := NewEventHandler("vtoken", "ekey", nil)
+
+	encrypted := encryptTestEvent(t, []byte(`{"challenge":"xyz","token":"vtoken","type":"url_verification"}`), "ekey")
+	body, _ := json.Marshal(map[string]string{"encrypt": encrypted})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"challenge":"xyz"`) {
+		t.Errorf("Expected challenge response, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestEventHandlerParse(t *testing.T) {
+	var events []*Event
+	handler := NewEventHandler("vtoken", "", func(ctx context.Context, event *Event) error {
+		events = append(events, event)
+		return nil
+	})
+	handler.Sync = true
+
+	for _, body := range []string{testEventV2, testEventV1} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, newSignedEventRequest([]byte(body), ""))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+	v2 := events[0]
+	if v2.Schema != "2.0" || v2.EventID != "ev_1" || v2.EventType != "im.message.receive_v1" || v2.TenantKey != "tk_1" {
+		t.Errorf("Unexpected v2 event: %+v", v2)
+	}
+	var body struct {
+		Message struct {
+			MessageID string `json:"message_id"`
+		} `json:"message"`
+	}
+	if err := v2.Decode(&body); err != nil || body.Message.MessageID != "om_1" {
+		t.Errorf("Expected to decode event body, got %+v, %v", body, err)
+	}
+
+	v1 := events[1]
+	if v1.Schema != "" || v1.EventID != "uuid_1" || v1.EventType != "message" || v1.AppID != "cli_1" || v1.CreateTime != "1700000000.0" {
+		t.Errorf("Unexpected v1 event: %+v", v1)
+	}
+}
+
+func TestEventHandlerEncryptedAndSigned(t *testing.T) {
+	var got *Event
+	handler := NewEventHandler("vtoken", "ekey", func(ctx context.Context, event *Event) error {
+		got = event
+		return nil
+	})
+	handler.Sync = true
+
+	body, _ := json.Marshal(map[string]string{"encrypt": encryptTestEvent(t, []byte(testEventV2), "ekey")})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newSignedEventRequest(body, "ekey"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	if got == nil || got.EventID != "ev_1" {
+		t.Errorf("Expected decrypted event, got %+v", got)
+	}
+
+	// 签名错误
+	req := newSignedEventRequest(body, "ekey")
+	req.Header.Set(HeaderLarkSignature, "bad")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for bad signature, got %d", rec.Code)
+	}
+
+	// 缺少签名
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/", bytes.NewReader(body)))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for missing signature, got %d", rec.Code)
+	}
+
+	// 密钥错误
+	wrongKey, _ := json.Marshal(map[string]string{"encrypt": encryptTestEvent(t, []byte(testEventV2), "other")})
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, newSignedEventRequest(wrongKey, "ekey"))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for undecryptable event, got %d", rec.Code)
+	}
+}
+
+func TestEventHandlerErrors(t *testing.T) {
+	handler := NewEventHandler("vtoken", "", func(ctx context.Context, event *Event) error {
+		return errors.New("boom")
+	})
+	handler.Sync = true
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newSignedEventRequest([]byte(testEventV2), ""))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500 when handler fails, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, newSignedEventRequest([]byte(strings.Replace(testEventV2, "vtoken", "wrong", 1)), ""))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for wrong token, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, newSignedEventRequest([]byte("not json"), ""))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid body, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET, got %d", rec.Code)
+	}
+}
+
+func TestEventHandlerOverHTTP(t *testing.T) {
+	received := make(chan string, 1)
+	server := httptest.NewServer(NewEventHandler("", "", func(ctx context.Context, event *Event) error {
+		received <- event.EventType
+		return nil
+	}))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL, "application/json", strings.NewReader(testEventV2))
+	if err != nil {
+		t.Fatalf("post event failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200, got %d", resp.StatusCode)
+	}
+	if eventType := <-received; eventType != "im.message.receive_v1" {
+		t.Errorf("Unexpected event type %s", eventType)
+	}
+}
+
+func TestEventHandlerDeduplicate(t *testing.T) {
+	calls := 0
+	fail := true
+	handler := NewEventHandler("vtoken", "", func(ctx context.Context, event *Event) error {
+		calls++
+		if fail {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	handler.Sync = true
+	var duplicates []string
+	handler.OnDuplicate = func(event *Event) {
+		duplicates = append(duplicates, event.EventID)
+	}
+
+	// 处理失败时不记录，飞书重新推送的事件会再次处理
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newSignedEventRequest([]byte(testEventV2), ""))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected 500, got %d", rec.Code)
+	}
+
+	fail = false
+	for i := 0; i < 2; i++ {
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, newSignedEventRequest([]byte(testEventV2), ""))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", rec.Code)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Expected handler to be called 2 times, got %d", calls)
+	}
+	if len(duplicates) != 1 || duplicates[0] != "ev_1" {
+		t.Errorf("Expected ev_1 to be reported as duplicate, got %v", duplicates)
+	}
+}
+
+func TestEventHandlerAsyncByDefault(t *testing.T) {
+	release := make(chan struct{})
+	errs := make(chan error, 1)
+	handler := NewEventHandler("", "", func(ctx context.Context, event *Event) error {
+		<-release
+		return errors.New("boom")
+	})
+	handler.OnError = func(event *Event, err error) {
+		errs <- err
+	}
+	defer handler.Stop()
+
+	// 处理完成前已响应成功，处理失败只回调OnError
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newSignedEventRequest([]byte(testEventV2), ""))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200 before handling, got %d", rec.Code)
+	}
+	close(release)
+	select {
+	case <-errs:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected handler error to be reported")
+	}
+}
+
+func TestEventHandlerAsync(t *testing.T) {
+	release := make(chan struct{})
+	handled := make(chan string, 2)
+	handler := NewEventHandler("", "", func(ctx context.Context, event *Event) error {
+		<-release
+		handled <- event.EventID
+		return errors.New("boom")
+	})
+	var dropped []string
+	handler.OnDrop = func(event *Event) {
+		dropped = append(dropped, event.EventID)
+	}
+	errs := make(chan error, 2)
+	handler.OnError = func(event *Event, err error) {
+		errs <- err
+	}
+	handler.StartAsync(1, 1)
+
+	// 第一个事件由worker处理，第二个事件进入队列，立即响应成功
+	for _, id := range []string{"ev_1", "ev_2"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, newSignedEventRequest([]byte(strings.Replace(testEventV2, "ev_1", id, 1)), ""))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected 200 for %s, got %d", id, rec.Code)
+		}
+		if id == "ev_1" {
+			// 等待worker取出第一个事件，避免与第二个事件竞争队列
+			for len(handler.queue) != 0 {
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}
+
+	// 队列已满时响应503，事件ID不记录，重新推送时可以处理
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newSignedEventRequest([]byte(strings.Replace(testEventV2, "ev_1", "ev_3", 1)), ""))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 when queue is full, got %d", rec.Code)
+	}
+	if len(dropped) != 1 || dropped[0] != "ev_3" {
+		t.Errorf("Expected ev_3 to be dropped, got %v", dropped)
+	}
+	if added, _ := handler.Seen.Add("ev_3"); !added {
+		t.Error("Expected dropped event to be removed from seen store")
+	}
+
+	close(release)
+	handler.Stop()
+	close(handled)
+	var ids []string
+	for id := range handled {
+		ids = append(ids, id)
+	}
+	if strings.Join(ids, ",") != "ev_1,ev_2" {
+		t.Errorf("Expected queued events to be handled before stop, got %v", ids)
+	}
+	if len(errs) != 2 {
+		t.Errorf("Expected OnError to be called 2 times, got %d", len(errs))
+	}
+}
@@ -0,0 +1,493 @@
+package easylark
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MentionResolver 将Markdown中的@name解析为用户open_id，无法解析时返回false
+type MentionResolver func(name string) (openID string, ok bool)
+
+// MarkdownConverter 将GitHub风格的Markdown转换为富文本消息或卡片
+//
+// 飞书不支持的语法会降级处理：标题转为加粗文本，表格转为按行排列的文本，
+// 无法解析的@提及保留为普通文本。
+type MarkdownConverter struct {
+	// MentionResolver 解析@提及，为空时@name保留为普通文本
+	MentionResolver MentionResolver
+}
+
+// NewMarkdownConverter 创建Markdown转换器
+func NewMarkdownConverter(resolver MentionResolver) *MarkdownConverter {
+	return &MarkdownConverter{MentionResolver: resolver}
+}
+
+// MarkdownToPost 使用默认转换器将Markdown转换为富文本消息
+func MarkdownToPost(title, markdown string) (*PostContent, error) {
+	return NewMarkdownConverter(nil).ToPost(title, markdown)
+}
+
+// MarkdownToCard 使用默认转换器将Markdown转换为卡片消息
+func MarkdownToCard(title, markdown string) (*MessageCard, error) {
+	return NewMarkdownConverter(nil).ToCard(title, markdown)
+}
+
+// markdownEscapable 行内可以用反斜杠转义的字符
+const markdownEscapable = "\\`*_{}[]()#+-.!~@|<>"
+
+// mdBlockKind Markdown块类型
+type mdBlockKind int
+
+const (
+	mdParagraph mdBlockKind = iota
+	mdHeading
+	mdCode
+	mdListItem
+	mdQuote
+	mdHR
+	mdTable
+)
+
+// mdBlock Markdown块
+type mdBlock struct {
+	kind   mdBlockKind
+	text   string
+	level  int
+	marker string
+	lang   string
+	rows   [][]string
+}
+
+var (
+	mdFenceRegexp    = regexp.MustCompile("^ {0,3}(```+|~~~+)\\s*([^`\\s]*)")
+	mdHeadingRegexp  = regexp.MustCompile(`^ {0,3}(#{1,6})\s+(.*?)\s*#*\s*$`)
+	mdHRRegexp       = regexp.MustCompile(`^ {0,3}(-(\s*-){2,}|\*(\s*\*){2,}|_(\s*_){2,})\s*$`)
+	mdListRegexp     = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	mdTaskRegexp     = regexp.MustCompile(`^\[([ xX])\]\s+(.*)$`)
+	mdQuoteRegexp    = regexp.MustCompile(`^ {0,3}>\s?(.*)$`)
+	mdTableSepRegexp = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+)
+
+// parseMarkdownBlocks 将Markdown拆分为块
+func parseMarkdownBlocks(markdown string) []mdBlock {
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	var blocks []mdBlock
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		if m := mdFenceRegexp.FindStringSubmatch(line); m != nil {
+			fence := m[1]
+			var code []string
+			for i++; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+					break
+				}
+				code = append(code, lines[i])
+			}
+			blocks = append(blocks, mdBlock{kind: mdCode, lang: m[2], text: strings.Join(code, "\n")})
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			continue
+		case mdHRRegexp.MatchString(line):
+			blocks = append(blocks, mdBlock{kind: mdHR})
+		case mdHeadingRegexp.MatchString(line):
+			m := mdHeadingRegexp.FindStringSubmatch(line)
+			blocks = append(blocks, mdBlock{kind: mdHeading, level: len(m[1]), text: m[2]})
+		case mdListRegexp.MatchString(line):
+			m := mdListRegexp.FindStringSubmatch(line)
+			marker, text := "•", m[3]
+			if m[2][0] >= '0' && m[2][0] <= '9' {
+				marker = strings.TrimRight(m[2], ".)") + "."
+			}
+			if t := mdTaskRegexp.FindStringSubmatch(text); t != nil {
+				marker, text = "☐", t[2]
+				if t[1] != " " {
+					marker = "☑"
+				}
+			}
+			indent := len(strings.ReplaceAll(m[1], "\t", "  "))
+			blocks = append(blocks, mdBlock{kind: mdListItem, level: indent / 2, marker: marker, text: text})
+		case mdQuoteRegexp.MatchString(line):
+			blocks = append(blocks, mdBlock{kind: mdQuote, text: mdQuoteRegexp.FindStringSubmatch(line)[1]})
+		case strings.Contains(line, "|") && i+1 < len(lines) && mdTableSepRegexp.MatchString(lines[i+1]) && strings.Contains(lines[i+1], "-"):
+			rows := [][]string{splitTableRow(line)}
+			for i += 2; i < len(lines) && strings.Contains(lines[i], "|"); i++ {
+				rows = append(rows, splitTableRow(lines[i]))
+			}
+			i--
+			blocks = append(blocks, mdBlock{kind: mdTable, rows: rows})
+		default:
+			blocks = append(blocks, mdBlock{kind: mdParagraph, text: trimmed})
+		}
+	}
+	return blocks
+}
+
+// splitTableRow 拆分表格行
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	line = strings.TrimSuffix(line, "|")
+	cells := strings.Split(line, "|")
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	return cells
+}
+
+// ToPost 将Markdown转换为富文本消息，title为空时使用开头的一级标题
+func (c *MarkdownConverter) ToPost(title, markdown string) (*PostContent, error) {
+	blocks := parseMarkdownBlocks(markdown)
+	if title == "" && len(blocks) > 0 && blocks[0].kind == mdHeading && blocks[0].level == 1 {
+		title = blocks[0].text
+		blocks = blocks[1:]
+	}
+
+	builder := NewPostBuilder(title)
+	for _, block := range blocks {
+		switch block.kind {
+		case mdCode:
+			if block.text == "" {
+				continue
+			}
+			builder.CodeBlock(block.lang, block.text)
+		case mdHR:
+			builder.HR()
+		case mdHeading:
+			builder.Add(c.parseInline(block.text, []TextStyle{TextStyleBold})...).Paragraph()
+		case mdListItem:
+			prefix := strings.Repeat("    ", block.level) + block.marker + " "
+			builder.Add(prependPostText(prefix, c.parseInline(block.text, nil))...).Paragraph()
+		case mdQuote:
+			builder.Add(prependPostText("┃ ", c.parseInline(block.text, []TextStyle{TextStyleItalic}))...).Paragraph()
+		case mdTable:
+			for i, row := range block.rows {
+				var styles []TextStyle
+				if i == 0 {
+					styles = []TextStyle{TextStyleBold}
+				}
+				builder.Add(c.parseInline(strings.Join(row, " | "), styles)...).Paragraph()
+			}
+		default:
+			builder.Add(c.parseInline(block.text, nil)...).Paragraph()
+		}
+	}
+
+	post, err := builder.Build()
+	if err != nil {
+		return nil, fmt.Errorf("convert markdown to post failed: %w", err)
+	}
+	return post, nil
+}
+
+// parseInline 解析行内语法为富文本元素
+func (c *MarkdownConverter) parseInline(text string, styles []TextStyle) []PostElement {
+	var elements []PostElement
+	var buf strings.Builder
+
+	flush := func() {
+		if buf.Len() == 0 {
+			return
+		}
+		elements = appendPostText(elements, buf.String(), styles)
+		buf.Reset()
+	}
+	withStyle := func(style TextStyle) []TextStyle {
+		next := make([]TextStyle, 0, len(styles)+1)
+		next = append(next, styles...)
+		for _, s := range styles {
+			if s == style {
+				return next
+			}
+		}
+		return append(next, style)
+	}
+
+	for i := 0; i < len(text); {
+		rest := text[i:]
+		switch {
+		case rest[0] == '\\' && len(rest) > 1 && strings.IndexByte(markdownEscapable, rest[1]) >= 0:
+			buf.WriteByte(rest[1])
+			i += 2
+			continue
+		case strings.HasPrefix(rest, "**") || strings.HasPrefix(rest, "__"):
+			if end := indexUnescaped(rest[2:], rest[:2]); end > 0 {
+				flush()
+				elements = append(elements, c.parseInline(rest[2:2+end], withStyle(TextStyleBold))...)
+				i += end + 4
+				continue
+			}
+		case strings.HasPrefix(rest, "~~"):
+			if end := indexUnescaped(rest[2:], "~~"); end > 0 {
+				flush()
+				elements = append(elements, c.parseInline(rest[2:2+end], withStyle(TextStyleLineThrough))...)
+				i += end + 4
+				continue
+			}
+		case rest[0] == '*' || (rest[0] == '_' && isWordBoundary(text, i)):
+			if end := indexUnescaped(rest[1:], rest[:1]); end > 0 && rest[1] != ' ' {
+				flush()
+				elements = append(elements, c.parseInline(rest[1:1+end], withStyle(TextStyleItalic))...)
+				i += end + 2
+				continue
+			}
+		case rest[0] == '`':
+			if end := strings.IndexByte(rest[1:], '`'); end >= 0 {
+				buf.WriteString(rest[1 : 1+end])
+				i += end + 2
+				continue
+			}
+		case strings.HasPrefix(rest, "!["):
+			if alt, target, n, ok := parseMarkdownLink(rest[1:]); ok {
+				flush()
+				// 富文本只支持上传后的图片key，图片链接转为普通链接
+				if isImageKey(target) {
+					elements = append(elements, PostElement{Tag: PostTagImage, ImageKey: target})
+				} else if isURL(target) {
+					if alt == "" {
+						alt = target
+					}
+					elements = append(elements, PostElement{Tag: PostTagLink, Text: stripInlineMarkdown(alt), Href: target, Style: styles})
+				} else if alt != "" {
+					elements = appendPostText(elements, alt, styles)
+				}
+				i += n + 1
+				continue
+			}
+		case rest[0] == '[':
+			if label, target, n, ok := parseMarkdownLink(rest); ok {
+				flush()
+				if label == "" {
+					label = target
+				}
+				elements = append(elements, PostElement{Tag: PostTagLink, Text: stripInlineMarkdown(label), Href: target, Style: styles})
+				i += n
+				continue
+			}
+		case rest[0] == '<':
+			if end := strings.IndexByte(rest, '>'); end > 0 && isURL(rest[1:end]) {
+				flush()
+				elements = append(elements, PostElement{Tag: PostTagLink, Text: rest[1:end], Href: rest[1:end], Style: styles})
+				i += end + 1
+				continue
+			}
+		case rest[0] == '@' && isWordBoundary(text, i):
+			if name := mentionName(rest[1:]); name != "" && c.MentionResolver != nil {
+				if openID, ok := c.MentionResolver(name); ok {
+					flush()
+					elements = append(elements, PostElement{Tag: PostTagAt, UserId: openID, Style: styles})
+					i += len(name) + 1
+					continue
+				}
+			}
+		}
+
+		r, size := utf8.DecodeRuneInString(rest)
+		buf.WriteRune(r)
+		i += size
+	}
+	flush()
+	return elements
+}
+
+// indexUnescaped 查找未被反斜杠转义的delim，用于查找行内样式的结束标记
+func indexUnescaped(s, delim string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && strings.IndexByte(markdownEscapable, s[i+1]) >= 0 {
+			i++
+			continue
+		}
+		if strings.HasPrefix(s[i:], delim) {
+			return i
+		}
+	}
+	return -1
+}
+
+// appendPostText 追加文本元素，与前一个样式相同的文本元素合并
+func appendPostText(elements []PostElement, text string, styles []TextStyle) []PostElement {
+	if n := len(elements); n > 0 {
+		last := &elements[n-1]
+		if last.Tag == PostTagText && sameStyles(last.Style, styles) {
+			last.Text += text
+			return elements
+		}
+	}
+	return append(elements, PostElement{Tag: PostTagText, Text: text, Style: styles})
+}
+
+// prependPostText 在元素前插入无样式文本，能合并时合并到首个文本元素
+func prependPostText(text string, elements []PostElement) []PostElement {
+	if len(elements) > 0 && elements[0].Tag == PostTagText && len(elements[0].Style) == 0 {
+		elements[0].Text = text + elements[0].Text
+		return elements
+	}
+	return append([]PostElement{{Tag: PostTagText, Text: text}}, elements...)
+}
+
+// sameStyles 判断两组样式是否相同
+func sameStyles(a, b []TextStyle) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// parseMarkdownLink 解析[label](target)形式的链接，返回消耗的字节数
+func parseMarkdownLink(s string) (label, target string, n int, ok bool) {
+	closeLabel := strings.Index(s, "](")
+	if !strings.HasPrefix(s, "[") || closeLabel < 0 {
+		return "", "", 0, false
+	}
+	closeTarget := strings.IndexByte(s[closeLabel+2:], ')')
+	if closeTarget < 0 {
+		return "", "", 0, false
+	}
+	target = strings.TrimSpace(s[closeLabel+2 : closeLabel+2+closeTarget])
+	if space := strings.IndexByte(target, ' '); space > 0 {
+		target = target[:space]
+	}
+	return s[1:closeLabel], target, closeLabel + 3 + closeTarget, true
+}
+
+// stripInlineMarkdown 去除链接文本中的强调符号
+func stripInlineMarkdown(s string) string {
+	return strings.NewReplacer("**", "", "__", "", "~~", "", "`", "").Replace(s)
+}
+
+// isWordBoundary 判断位置i之前是否为单词边界
+func isWordBoundary(s string, i int) bool {
+	if i == 0 {
+		return true
+	}
+	r, _ := utf8.DecodeLastRuneInString(s[:i])
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+}
+
+// mentionName 读取@之后的用户名
+func mentionName(s string) string {
+	end := 0
+	for end < len(s) {
+		r, size := utf8.DecodeRuneInString(s[end:])
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' && r != '.' {
+			break
+		}
+		end += size
+	}
+	return strings.TrimRight(s[:end], ".-")
+}
+
+// isURL 判断是否为http链接
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// isImageKey 判断是否为通过UploadImage获取的图片key
+func isImageKey(s string) bool {
+	return strings.HasPrefix(s, "img_")
+}
+
+var mdImageRegexp = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]*)\)`)
+
+var mdMentionRegexp = regexp.MustCompile(`(^|[^\w@])@([\p{L}\p{N}_][\p{L}\p{N}_.-]*)`)
+
+// ToCard 将Markdown转换为卡片消息，正文使用markdown元素，分割线使用hr元素
+func (c *MarkdownConverter) ToCard(title, markdown string) (*MessageCard, error) {
+	blocks := parseMarkdownBlocks(markdown)
+	if title == "" && len(blocks) > 0 && blocks[0].kind == mdHeading && blocks[0].level == 1 {
+		title = blocks[0].text
+		blocks = blocks[1:]
+	}
+
+	card := NewMessageCard().SetWideScreenMode(true)
+	if title != "" {
+		card.SetTitle(title)
+	}
+
+	var lines []string
+	flush := func() {
+		if len(lines) > 0 {
+			card.AddMarkdown(strings.Join(lines, "\n"))
+			lines = nil
+		}
+	}
+
+	for _, block := range blocks {
+		switch block.kind {
+		case mdHR:
+			flush()
+			card.AddHR()
+		case mdCode:
+			lines = append(lines, "```"+block.lang, block.text, "```")
+		case mdHeading:
+			lines = append(lines, "**"+c.cardInline(block.text)+"**")
+		case mdListItem:
+			prefix := strings.Repeat("    ", block.level)
+			if block.marker == "•" {
+				lines = append(lines, prefix+"- "+c.cardInline(block.text))
+			} else {
+				lines = append(lines, prefix+block.marker+" "+c.cardInline(block.text))
+			}
+		case mdQuote:
+			lines = append(lines, "> "+c.cardInline(block.text))
+		case mdTable:
+			for i, row := range block.rows {
+				line := c.cardInline(strings.Join(row, " | "))
+				if i == 0 {
+					line = "**" + line + "**"
+				}
+				lines = append(lines, line)
+			}
+		default:
+			lines = append(lines, c.cardInline(block.text))
+		}
+	}
+	flush()
+
+	if err := card.Validate(); err != nil {
+		return nil, fmt.Errorf("convert markdown to card failed: %w", err)
+	}
+	return card, nil
+}
+
+// cardInline 转换卡片markdown行内语法，主要处理@提及和图片链接
+func (c *MarkdownConverter) cardInline(text string) string {
+	text = mdImageRegexp.ReplaceAllStringFunc(text, func(match string) string {
+		m := mdImageRegexp.FindStringSubmatch(match)
+		switch {
+		case isImageKey(m[2]):
+			return match
+		case isURL(m[2]):
+			if m[1] == "" {
+				return "[" + m[2] + "](" + m[2] + ")"
+			}
+			return "[" + m[1] + "](" + m[2] + ")"
+		}
+		return m[1]
+	})
+	if c.MentionResolver == nil {
+		return text
+	}
+	return mdMentionRegexp.ReplaceAllStringFunc(text, func(match string) string {
+		m := mdMentionRegexp.FindStringSubmatch(match)
+		name := strings.TrimRight(m[2], ".-")
+		openID, ok := c.MentionResolver(name)
+		if !ok {
+			return match
+		}
+		return m[1] + MarkdownMention(openID) + strings.TrimPrefix(m[2], name)
+	})
+}
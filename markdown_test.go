@@ -0,0 +1,177 @@
+package easylark
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownToPost(t *testing.T) {
+	markdown := strings.Join([]string{
+		"# Release v1.2.0",
+		"",
+		"Deployed by @alice with **bold _nested_** and ~~old~~ `code`, see [docs](https://example.com).",
+		"",
+		"## Changes",
+		"- fix snake_case handling",
+		"  1. nested item",
+		"- [x] done",
+		"",
+		"```go",
+		"fmt.Println(1)",
+		"```",
+		"---",
+		"![logo](img_v2_abc)",
+		"",
+		"| name | status |",
+		"| --- | --- |",
+		"| api | ok |",
+	}, "\n")
+
+	resolver := func(name string) (string, bool) {
+		if name == "alice" {
+			return "ou_alice", true
+		}
+		return "", false
+	}
+	post, err := NewMarkdownConverter(resolver).ToPost("", markdown)
+	if err != nil {
+		t.Fatalf("convert markdown failed: %v", err)
+	}
+	if err := post.Validate(); err != nil {
+		t.Fatalf("converted post is invalid: %v", err)
+	}
+
+	body := post.ZhCn
+	if body.Title != "Release v1.2.0" {
+		t.Errorf("Expected title from heading, got '%s'", body.Title)
+	}
+
+	first := body.Content[0]
+	wantTags := []string{"text", "at", "text", "text", "text", "text", "text", "text", "a", "text"}
+	if len(first) != len(wantTags) {
+		t.Fatalf("Expected %d elements in first paragraph, got %+v", len(wantTags), first)
+	}
+	for i, tag := range wantTags {
+		if first[i].Tag != tag {
+			t.Errorf("Expected element %d tag '%s', got '%s'", i, tag, first[i].Tag)
+		}
+	}
+	if first[1].UserId != "ou_alice" {
+		t.Errorf("Expected mention of ou_alice, got %+v", first[1])
+	}
+	if first[3].Text != "bold " || !sameStyles(first[3].Style, []TextStyle{TextStyleBold}) {
+		t.Errorf("Expected bold text, got %+v", first[3])
+	}
+	if !sameStyles(first[4].Style, []TextStyle{TextStyleBold, TextStyleItalic}) {
+		t.Errorf("Expected bold italic text, got %+v", first[4])
+	}
+	if first[7].Text != " code, see " {
+		t.Errorf("Expected inline code to degrade to plain text, got %+v", first[7])
+	}
+	if first[8].Href != "https://example.com" || first[8].Text != "docs" {
+		t.Errorf("Expected link to docs, got %+v", first[8])
+	}
+
+	if body.Content[1][0].Text != "Changes" {
+		t.Errorf("Expected heading paragraph, got %+v", body.Content[1])
+	}
+	if body.Content[2][0].Text != "• fix snake_case handling" {
+		t.Errorf("Expected list item with snake_case kept, got %+v", body.Content[2])
+	}
+	if body.Content[3][0].Text != "    1. nested item" {
+		t.Errorf("Expected nested ordered item, got %+v", body.Content[3])
+	}
+	if body.Content[4][0].Text != "☑ done" {
+		t.Errorf("Expected checked task item, got %+v", body.Content[4])
+	}
+	if body.Content[5][0].Tag != PostTagCodeBlock || body.Content[5][0].Language != "go" {
+		t.Errorf("Expected go code block, got %+v", body.Content[5])
+	}
+	if body.Content[6][0].Tag != PostTagHR {
+		t.Errorf("Expected hr, got %+v", body.Content[6])
+	}
+	if body.Content[7][0].Tag != PostTagImage || body.Content[7][0].ImageKey != "img_v2_abc" {
+		t.Errorf("Expected image, got %+v", body.Content[7])
+	}
+	if body.Content[9][0].Text != "api | ok" {
+		t.Errorf("Expected degraded table row, got %+v", body.Content[9])
+	}
+}
+
+func TestMarkdownToCard(t *testing.T) {
+	resolver := func(name string) (string, bool) {
+		return "ou_" + name, name == "bob"
+	}
+	card, err := NewMarkdownConverter(resolver).ToCard("CI Summary", "## Result\nping @bob and @carol\n\n---\n\n- passed")
+	if err != nil {
+		t.Fatalf("convert markdown failed: %v", err)
+	}
+
+	elements := card.Content()["elements"].([]CardElement)
+	if len(elements) != 3 {
+		t.Fatalf("Expected 3 elements, got %d", len(elements))
+	}
+	first, ok := elements[0].(*CardMarkdown)
+	if !ok {
+		t.Fatalf("Expected markdown element, got %T", elements[0])
+	}
+	if first.Content != "**Result**\nping <at id=ou_bob></at> and @carol" {
+		t.Errorf("Unexpected markdown content: %q", first.Content)
+	}
+	if _, ok := elements[1].(*CardHR); !ok {
+		t.Errorf("Expected hr element, got %T", elements[1])
+	}
+}
+
+func TestMarkdownImageTargets(t *testing.T) {
+	post, err := MarkdownToPost("", "![logo](img_v2_abc) ![chart](https://example.com/a.png) ![local](./a.png)")
+	if err != nil {
+		t.Fatalf("convert markdown failed: %v", err)
+	}
+	if err := post.Validate(); err != nil {
+		t.Fatalf("Expected valid post, got %v", err)
+	}
+	line := post.ZhCn.Content[0]
+	if line[0].Tag != PostTagImage || line[0].ImageKey != "img_v2_abc" {
+		t.Errorf("Expected image for img key, got %+v", line[0])
+	}
+	if line[2].Tag != PostTagLink || line[2].Text != "chart" || line[2].Href != "https://example.com/a.png" {
+		t.Errorf("Expected link for image url, got %+v", line[2])
+	}
+	if line[3].Tag != PostTagText || line[3].Text != " local" {
+		t.Errorf("Expected alt text for unsupported target, got %+v", line[3:])
+	}
+
+	card, err := MarkdownToCard("", "![logo](img_v2_abc) ![chart](https://example.com/a.png) ![local](./a.png)")
+	if err != nil {
+		t.Fatalf("convert markdown failed: %v", err)
+	}
+	content := card.Content()["elements"].([]CardElement)[0].(*CardMarkdown).Content
+	if content != "![logo](img_v2_abc) [chart](https://example.com/a.png) local" {
+		t.Errorf("Unexpected card markdown %q", content)
+	}
+}
+
+func TestMarkdownEscapedDelimiters(t *testing.T) {
+	tests := []struct {
+		markdown string
+		text     string
+		style    TextStyle
+	}{
+		{`**a\*c\***`, "a*c*", TextStyleBold},
+		{`__a\_\_b__`, "a__b", TextStyleBold},
+		{`~~a\~\~b~~`, "a~~b", TextStyleLineThrough},
+		{`*a\*b*`, "a*b", TextStyleItalic},
+		{`_a\_b_`, "a_b", TextStyleItalic},
+	}
+	for _, tt := range tests {
+		post, err := MarkdownToPost("", tt.markdown)
+		if err != nil {
+			t.Fatalf("convert %q failed: %v", tt.markdown, err)
+		}
+		line := post.ZhCn.Content[0]
+		if len(line) != 1 || line[0].Text != tt.text || !sameStyles(line[0].Style, []TextStyle{tt.style}) {
+			t.Errorf("Expected %q to be %q with style %s, got %+v", tt.markdown, tt.text, tt.style, line)
+		}
+	}
+}
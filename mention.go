@@ -0,0 +1,169 @@
+package easylark
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MentionAllID @所有人时使用的ID，适用于文本、富文本和卡片
+const MentionAllID = "all"
+
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// EscapeText 转义文本消息中的用户输入，避免被解析为<at>等标签
+func EscapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+var markdownEscaper = strings.NewReplacer(
+	"&", "&amp;", "<", "&lt;", ">", "&gt;",
+	"\\", "\\\\", "*", "\\*", "_", "\\_", "~", "\\~", "`", "\\`",
+	"[", "\\[", "]", "\\]", "(", "\\(", ")", "\\)", "#", "\\#", "|", "\\|",
+)
+
+// EscapeMarkdown 转义卡片markdown和富文本md元素中的用户输入，避免被解析为格式或标签
+func EscapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+var attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+// TextBuilder 文本消息构造器，普通文本会被转义，可安全拼接任意字符串
+type TextBuilder struct {
+	buf strings.Builder
+}
+
+// NewTextBuilder 创建文本消息构造器
+func NewTextBuilder() *TextBuilder {
+	return &TextBuilder{}
+}
+
+// Text 追加转义后的文本
+func (b *TextBuilder) Text(s string) *TextBuilder {
+	b.buf.WriteString(EscapeText(s))
+	return b
+}
+
+// Textf 追加格式化后的文本，格式化结果整体转义
+func (b *TextBuilder) Textf(format string, args ...interface{}) *TextBuilder {
+	return b.Text(fmt.Sprintf(format, args...))
+}
+
+// Raw 追加不转义的文本，调用方需自行保证内容安全
+func (b *TextBuilder) Raw(s string) *TextBuilder {
+	b.buf.WriteString(s)
+	return b
+}
+
+// Newline 追加换行
+func (b *TextBuilder) Newline() *TextBuilder {
+	b.buf.WriteString("\n")
+	return b
+}
+
+// Mention @指定用户，openID为用户的open_id，name为显示名称
+func (b *TextBuilder) Mention(openID, name string) *TextBuilder {
+	fmt.Fprintf(&b.buf, `<at user_id="%s">%s</at>`, attrEscaper.Replace(openID), EscapeText(name))
+	return b
+}
+
+// MentionAll @所有人
+func (b *TextBuilder) MentionAll() *TextBuilder {
+	fmt.Fprintf(&b.buf, `<at user_id="%s">所有人</at>`, MentionAllID)
+	return b
+}
+
+// String 返回构造的文本
+func (b *TextBuilder) String() string {
+	return b.buf.String()
+}
+
+// Build 生成文本消息内容
+func (b *TextBuilder) Build() *TextContent {
+	return &TextContent{Text: b.String()}
+}
+
+// MarkdownBuilder 卡片markdown和富文本md元素的构造器，普通文本会被转义
+type MarkdownBuilder struct {
+	buf strings.Builder
+}
+
+// NewMarkdownBuilder 创建markdown构造器
+func NewMarkdownBuilder() *MarkdownBuilder {
+	return &MarkdownBuilder{}
+}
+
+// Text 追加转义后的文本
+func (b *MarkdownBuilder) Text(s string) *MarkdownBuilder {
+	b.buf.WriteString(EscapeMarkdown(s))
+	return b
+}
+
+// Textf 追加格式化后的文本，格式化结果整体转义
+func (b *MarkdownBuilder) Textf(format string, args ...interface{}) *MarkdownBuilder {
+	return b.Text(fmt.Sprintf(format, args...))
+}
+
+// Raw 追加不转义的markdown，调用方需自行保证内容安全
+func (b *MarkdownBuilder) Raw(s string) *MarkdownBuilder {
+	b.buf.WriteString(s)
+	return b
+}
+
+// Bold 追加加粗文本
+func (b *MarkdownBuilder) Bold(s string) *MarkdownBuilder {
+	b.buf.WriteString("**" + EscapeMarkdown(s) + "**")
+	return b
+}
+
+// Italic 追加斜体文本
+func (b *MarkdownBuilder) Italic(s string) *MarkdownBuilder {
+	b.buf.WriteString("*" + EscapeMarkdown(s) + "*")
+	return b
+}
+
+// Strike 追加删除线文本
+func (b *MarkdownBuilder) Strike(s string) *MarkdownBuilder {
+	b.buf.WriteString("~~" + EscapeMarkdown(s) + "~~")
+	return b
+}
+
+// Link 追加链接，链接地址中的括号会被编码
+func (b *MarkdownBuilder) Link(text, url string) *MarkdownBuilder {
+	url = strings.NewReplacer("(", "%28", ")", "%29", " ", "%20").Replace(url)
+	b.buf.WriteString("[" + EscapeMarkdown(text) + "](" + url + ")")
+	return b
+}
+
+// Newline 追加换行
+func (b *MarkdownBuilder) Newline() *MarkdownBuilder {
+	b.buf.WriteString("\n")
+	return b
+}
+
+// Mention @指定用户，openID为用户的open_id
+func (b *MarkdownBuilder) Mention(openID string) *MarkdownBuilder {
+	b.buf.WriteString(MarkdownMention(openID))
+	return b
+}
+
+// MentionAll @所有人
+func (b *MarkdownBuilder) MentionAll() *MarkdownBuilder {
+	b.buf.WriteString(MarkdownMention(MentionAllID))
+	return b
+}
+
+// String 返回构造的markdown文本
+func (b *MarkdownBuilder) String() string {
+	return b.buf.String()
+}
+
+// Build 生成卡片markdown元素
+func (b *MarkdownBuilder) Build() *CardMarkdown {
+	return &CardMarkdown{Content: b.String()}
+}
+
+// MarkdownMention 生成卡片markdown中@用户的标签
+func MarkdownMention(openID string) string {
+	return fmt.Sprintf("<at id=%s></at>", attrEscaper.Replace(strings.TrimSpace(openID)))
+}
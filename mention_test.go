@@ -0,0 +1,46 @@
+package easylark
+
+import (
+	"testing"
+)
+
+func TestTextBuilder(t *testing.T) {
+	text := NewTextBuilder().
+		Mention("ou_123", "Tom <admin>").
+		Text(" disk usage <at user_id=\"all\"> & rising").
+		Newline().
+		MentionAll().
+		Build()
+
+	want := `<at user_id="ou_123">Tom &lt;admin&gt;</at> disk usage &lt;at user_id="all"&gt; &amp; rising` +
+		"\n" + `<at user_id="all">所有人</at>`
+	if text.Text != want {
+		t.Errorf("Expected '%s', got '%s'", want, text.Text)
+	}
+
+	if got := NewTextBuilder().Mention(`ou_1" x="y`, "a").String(); got != `<at user_id="ou_1&quot; x=&quot;y">a</at>` {
+		t.Errorf("Expected escaped attribute, got '%s'", got)
+	}
+}
+
+func TestMarkdownBuilder(t *testing.T) {
+	md := NewMarkdownBuilder().
+		Bold("build *failed*").
+		Text(" on [main](x) ").
+		Mention("ou_1").
+		Newline().
+		Link("log", "https://ci/job (1)").
+		String()
+
+	want := `**build \*failed\***` + ` on \[main\]\(x\) <at id=ou_1></at>` + "\n" + `[log](https://ci/job%20%281%29)`
+	if md != want {
+		t.Errorf("Expected '%s', got '%s'", want, md)
+	}
+
+	if got := EscapeMarkdown("<at id=all></at>"); got != "&lt;at id=all&gt;&lt;/at&gt;" {
+		t.Errorf("Unexpected escaped markdown '%s'", got)
+	}
+	if err := NewMessageCard().AddElement(NewMarkdownBuilder().MentionAll().Build()).Validate(); err != nil {
+		t.Errorf("Expected valid card, got %v", err)
+	}
+}
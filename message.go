@@ -2,16 +2,23 @@ package easylark
 
 import (
 	"fmt"
+	"strconv"
 )
 
 // MessageService 消息相关API服务
 type MessageService struct {
 	client *Client
+
+	// Templates 消息模板，配合SendTemplate使用
+	Templates *MessageTemplates
 }
 
 // newMessageService 创建消息服务
 func newMessageService(client *Client) *MessageService {
-	return &MessageService{client: client}
+	return &MessageService{
+		client:    client,
+		Templates: NewMessageTemplates(),
+	}
 }
 
 // MessageType 消息类型
@@ -21,7 +28,18 @@ const (
 	MessageTypeText     MessageType = "text"     // 文本消息
 	MessageTypePost     MessageType = "post"     // 富文本消息
 	MessageTypeImage    MessageType = "image"    // 图片消息
-	MessageTypeInteract MessageType = "interact" // 消息卡片
+	// Deprecated: 飞书接口中卡片消息的类型为interactive，请使用MessageTypeInteractive
+	MessageTypeInteract MessageType = "interact"
+
+	MessageTypeFile         MessageType = "file"          // 文件消息
+	MessageTypeAudio        MessageType = "audio"         // 语音消息
+	MessageTypeMedia        MessageType = "media"         // 视频消息
+	MessageTypeSticker      MessageType = "sticker"       // 表情包消息
+	MessageTypeShareChat    MessageType = "share_chat"    // 群名片消息
+	MessageTypeShareUser    MessageType = "share_user"    // 个人名片消息
+	MessageTypeInteractive  MessageType = "interactive"   // 消息卡片
+	MessageTypeSystem       MessageType = "system"        // 系统消息，仅接收
+	MessageTypeMergeForward MessageType = "merge_forward" // 合并转发消息，仅接收
 )
 
 // MessageContent 消息内容接口
@@ -33,6 +51,8 @@ type MessageContent interface {
 // TextContent 文本消息内容
 type TextContent struct {
 	Text string
+	// Mentions 接收消息中的@信息，Text中以@_user_1形式的key占位
+	Mentions []*Mention
 }
 
 // Type 实现MessageContent接口
@@ -47,87 +67,73 @@ func (t *TextContent) Content() map[string]interface{} {
 	}
 }
 
-// MessageCard 消息卡片
-type MessageCard struct {
-	title   string
-	elements []interface{}
-}
-
-// NewMessageCard 创建一个新的消息卡片
-func NewMessageCard() *MessageCard {
-	return &MessageCard{
-		elements: make([]interface{}, 0),
-	}
-}
-
-// SetTitle 设置卡片标题
-func (c *MessageCard) SetTitle(title string) *MessageCard {
-	c.title = title
-	return c
-}
-
-// AddText 添加文本内容
-func (c *MessageCard) AddText(text string) *MessageCard {
-	c.elements = append(c.elements, map[string]interface{}{
-		"tag":  "plain_text",
-		"text": text,
-	})
-	return c
-}
-
-// Type 实现MessageContent接口
-func (c *MessageCard) Type() MessageType {
-	return MessageTypeInteract
+// SendMessage 发送消息
+func (s *MessageService) SendMessage(chatID string, content MessageContent, opts ...SendOption) error {
+	_, err := s.sendMessage(chatID, content, newSendOptions(opts))
+	return err
 }
 
-// Content 实现MessageContent接口
-func (c *MessageCard) Content() map[string]interface{} {
-	return map[string]interface{}{
-		"elements": []interface{}{
-			map[string]interface{}{
-				"tag": "div",
-				"text": map[string]interface{}{
-					"tag":     "plain_text",
-					"content": c.title,
-				},
-				"fields": c.elements,
-			},
-		},
+// sendMessage 发送消息并返回message_id
+func (s *MessageService) sendMessage(chatID string, content MessageContent, options *sendOptions) (string, error) {
+	if err := checkMessageSize(content); err != nil {
+		return "", err
 	}
-}
 
-// SendMessage 发送消息
-func (s *MessageService) SendMessage(chatID string, content MessageContent) error {
-	path := "/im/v1/messages?receive_id_type=chat_id"
+	path := "/im/v1/messages?receive_id_type=" + string(options.idType())
 	
 	reqBody := map[string]interface{}{
 		"receive_id":      chatID,
 		"msg_type":        content.Type(),
 		"content":         content.Content(),
 	}
+	if options.uuid != "" {
+		reqBody["uuid"] = options.uuid
+	}
 	
-	var result APIResponse
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			MessageID string `json:"message_id"`
+		} `json:"data"`
+	}
 	err := s.client.DoRequest("POST", path, reqBody, &result)
 	if err != nil {
-		return err
+		return "", err
 	}
 	
 	if result.Code != 0 {
-		return &Error{Code: result.Code, Message: result.Msg}
+		return "", &Error{Code: result.Code, Message: result.Msg}
 	}
 	
-	return nil
+	return result.Data.MessageID, nil
 }
 
-// SendText 发送文本消息
-func (s *MessageService) SendText(chatID, text string) error {
-	content := &TextContent{Text: text}
-	return s.SendMessage(chatID, content)
+// SendText 发送文本消息，配合WithSplit可将超长文本按行拆分为多条发送
+func (s *MessageService) SendText(chatID, text string, opts ...SendOption) error {
+	options := newSendOptions(opts)
+	if !options.split {
+		_, err := s.sendMessage(chatID, &TextContent{Text: text}, options)
+		return err
+	}
+	
+	chunks, err := splitText(text, options.limit(MessageTypeText))
+	if err != nil {
+		return err
+	}
+	contents := make([]MessageContent, 0, len(chunks))
+	for _, chunk := range chunks {
+		contents = append(contents, &TextContent{Text: chunk})
+	}
+	return s.sendChunks(chatID, contents, options)
 }
 
-// SendCard 发送卡片消息
-func (s *MessageService) SendCard(chatID string, card *MessageCard) error {
-	return s.SendMessage(chatID, card)
+// SendCard 发送卡片消息，发送前会校验卡片结构和大小
+func (s *MessageService) SendCard(chatID string, card *MessageCard, opts ...SendOption) error {
+	if err := card.Validate(); err != nil {
+		return err
+	}
+	return s.SendMessage(chatID, card, opts...)
 }
 
 // GetMessage 获取消息
@@ -152,6 +158,89 @@ func (s *MessageService) GetMessage(messageID string) (map[string]interface{}, e
 	return result.Data, nil
 }
 
+// ReplyMessage 回复指定消息
+func (s *MessageService) ReplyMessage(messageID string, content MessageContent, opts ...SendOption) error {
+	if err := validateMessageContent(content); err != nil {
+		return err
+	}
+	_, err := s.replyMessage(messageID, content, newSendOptions(opts))
+	return err
+}
+
+// replyMessage 回复消息并返回新消息的message_id
+func (s *MessageService) replyMessage(messageID string, content MessageContent, options *sendOptions) (string, error) {
+	if err := checkMessageSize(content); err != nil {
+		return "", err
+	}
+
+	path := fmt.Sprintf("/im/v1/messages/%s/reply", messageID)
+	
+	reqBody := map[string]interface{}{
+		"msg_type": content.Type(),
+		"content":  content.Content(),
+	}
+	if options.uuid != "" {
+		reqBody["uuid"] = options.uuid
+	}
+	
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			MessageID string `json:"message_id"`
+		} `json:"data"`
+	}
+	err := s.client.DoRequest("POST", path, reqBody, &result)
+	if err != nil {
+		return "", err
+	}
+	
+	if result.Code != 0 {
+		return "", &Error{Code: result.Code, Message: result.Msg}
+	}
+	
+	return result.Data.MessageID, nil
+}
+
+// UpdateCard 更新已发送的卡片消息，card可以是MessageCard或TemplateCard
+func (s *MessageService) UpdateCard(messageID string, card MessageContent) error {
+	if card.Type() != MessageTypeInteractive {
+		return fmt.Errorf("update card failed: unsupported message type %s", card.Type())
+	}
+	if err := validateMessageContent(card); err != nil {
+		return err
+	}
+	if err := checkMessageSize(card); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/im/v1/messages/%s", messageID)
+	
+	reqBody := map[string]interface{}{
+		"content": card.Content(),
+	}
+	
+	var result APIResponse
+	err := s.client.DoRequest("PATCH", path, reqBody, &result)
+	if err != nil {
+		return err
+	}
+	
+	if result.Code != 0 {
+		return &Error{Code: result.Code, Message: result.Msg}
+	}
+	
+	return nil
+}
+
+// validateMessageContent 消息内容支持校验时先在本地校验
+func validateMessageContent(content MessageContent) error {
+	if v, ok := content.(interface{ Validate() error }); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
 // PostContent 富文本消息内容
 type PostContent struct {
 	ZhCn *PostBody `json:"zh_cn,omitempty"`
@@ -164,13 +253,20 @@ type PostBody struct {
 	Content [][]PostElement `json:"content"`
 }
 
-// PostElement 富文本元素
+// PostElement 富文本元素，推荐通过PostBuilder构造
 type PostElement struct {
-	Tag      string                 `json:"tag"`
-	Text     string                 `json:"text,omitempty"`
-	Href     string                 `json:"href,omitempty"`
-	UserId   string                 `json:"user_id,omitempty"`
-	Attrs    map[string]interface{} `json:"attrs,omitempty"`
+	Tag       string                 `json:"tag"`
+	Text      string                 `json:"text,omitempty"`
+	Href      string                 `json:"href,omitempty"`
+	UserId    string                 `json:"user_id,omitempty"`
+	UserName  string                 `json:"user_name,omitempty"`
+	ImageKey  string                 `json:"image_key,omitempty"`
+	FileKey   string                 `json:"file_key,omitempty"`
+	EmojiType string                 `json:"emoji_type,omitempty"`
+	Language  string                 `json:"language,omitempty"`
+	UnEscape  bool                   `json:"un_escape,omitempty"`
+	Style     []TextStyle            `json:"style,omitempty"`
+	Attrs     map[string]interface{} `json:"attrs,omitempty"`
 }
 
 // Type 实现MessageContent接口
@@ -211,9 +307,27 @@ func (p *PostContent) WithEnUs(title string, content [][]PostElement) *PostConte
 	return p
 }
 
-// SendPost 发送富文本消息
-func (s *MessageService) SendPost(chatID string, post *PostContent) error {
-	return s.SendMessage(chatID, post)
+// SendPost 发送富文本消息，发送前会校验内容，配合WithSplit可将超长内容按段落拆分为多条发送
+func (s *MessageService) SendPost(chatID string, post *PostContent, opts ...SendOption) error {
+	if err := post.Validate(); err != nil {
+		return err
+	}
+	
+	options := newSendOptions(opts)
+	if !options.split {
+		_, err := s.sendMessage(chatID, post, options)
+		return err
+	}
+	
+	chunks, err := splitPost(post, options.limit(MessageTypePost))
+	if err != nil {
+		return err
+	}
+	contents := make([]MessageContent, 0, len(chunks))
+	for _, chunk := range chunks {
+		contents = append(contents, chunk)
+	}
+	return s.sendChunks(chatID, contents, options)
 }
 
 // ImageContent 图片消息内容
@@ -234,38 +348,25 @@ func (i *ImageContent) Content() map[string]interface{} {
 }
 
 // SendImage 发送图片消息
-func (s *MessageService) SendImage(chatID string, imageKey string) error {
+func (s *MessageService) SendImage(chatID string, imageKey string, opts ...SendOption) error {
 	content := &ImageContent{ImageKey: imageKey}
-	return s.SendMessage(chatID, content)
+	return s.SendMessage(chatID, content, opts...)
 }
 
 // UploadImage 上传图片并获取image_key
 func (s *MessageService) UploadImage(imageBytes []byte, imageName string) (string, error) {
 	path := "/im/v1/images"
-	return s.client.UploadFile(path, imageBytes, imageName)
-}
-
-// CreateGroupRequest 创建群组请求
-type CreateGroupRequest struct {
-	Name        string   `json:"name"`
-	Description string   `json:"description,omitempty"`
-	UserIDs     []string `json:"user_ids,omitempty"`
-}
-
-// CreateGroup 创建群组
-func (s *MessageService) CreateGroup(req *CreateGroupRequest) (string, error) {
-	path := "/im/v1/chats"
 	
 	var result struct {
 		Code int    `json:"code"`
 		Msg  string `json:"msg"`
 		Data struct {
-			ChatID string `json:"chat_id"`
+			ImageKey string `json:"image_key"`
 		} `json:"data"`
 	}
 	
-	err := s.client.DoRequest("POST", path, req, &result)
-	if err != nil {
+	fields := map[string]string{"image_type": "message"}
+	if err := s.client.uploadMultipart(path, fields, imageBytes, imageName, &result); err != nil {
 		return "", err
 	}
 	
@@ -273,87 +374,42 @@ func (s *MessageService) CreateGroup(req *CreateGroupRequest) (string, error) {
 		return "", &Error{Code: result.Code, Message: result.Msg}
 	}
 	
-	return result.Data.ChatID, nil
+	return result.Data.ImageKey, nil
 }
 
-// GetGroupInfo 获取群组信息
-func (s *MessageService) GetGroupInfo(chatID string) (map[string]interface{}, error) {
-	path := fmt.Sprintf("/im/v1/chats/%s", chatID)
-	
-	var result struct {
-		Code int                    `json:"code"`
-		Msg  string                 `json:"msg"`
-		Data map[string]interface{} `json:"data"`
-	}
-	
-	err := s.client.DoRequest("GET", path, nil, &result)
+// CreateGroup 创建群组并返回chat_id，需要完整群信息时使用Client.Chat.Create
+func (s *MessageService) CreateGroup(req *CreateGroupRequest) (string, error) {
+	chat, err := newChatService(s.client).Create(req)
 	if err != nil {
-		return nil, err
-	}
-	
-	if result.Code != 0 {
-		return nil, &Error{Code: result.Code, Message: result.Msg}
+		return "", err
 	}
-	
-	return result.Data, nil
+	return chat.ChatID, nil
 }
 
-// AddGroupMember 添加群成员
-func (s *MessageService) AddGroupMember(chatID string, userIDs []string) error {
-	path := fmt.Sprintf("/im/v1/chats/%s/members", chatID)
-	
-	reqBody := map[string]interface{}{
-		"id_list": userIDs,
-	}
-	
-	var result APIResponse
-	err := s.client.DoRequest("POST", path, reqBody, &result)
-	if err != nil {
-		return err
-	}
-	
-	if result.Code != 0 {
-		return &Error{Code: result.Code, Message: result.Msg}
-	}
-	
-	return nil
+// GetGroupInfo 获取群组信息，等同于Client.Chat.Get
+func (s *MessageService) GetGroupInfo(chatID string) (*Chat, error) {
+	return newChatService(s.client).Get(chatID)
 }
 
-// RemoveGroupMember 移除群成员
-func (s *MessageService) RemoveGroupMember(chatID string, userIDs []string) error {
-	path := fmt.Sprintf("/im/v1/chats/%s/members", chatID)
-	
-	// 构造请求URL参数
-	queryParams := "?"
-	for i, userID := range userIDs {
-		if i > 0 {
-			queryParams += "&"
-		}
-		queryParams += fmt.Sprintf("id_list=%s", userID)
-	}
-	path += queryParams
-	
-	var result APIResponse
-	err := s.client.DoRequest("DELETE", path, nil, &result)
-	if err != nil {
-		return err
-	}
-	
-	if result.Code != 0 {
-		return &Error{Code: result.Code, Message: result.Msg}
-	}
-	
-	return nil
+// AddGroupMember 添加群成员，idType为空时使用open_id，等同于Client.Chat.AddMembers
+func (s *MessageService) AddGroupMember(chatID string, userIDs []string, idType MemberIDType) (*MemberChangeResult, error) {
+	return newChatService(s.client).AddMembers(chatID, userIDs, idType)
+}
+
+// RemoveGroupMember 移除群成员，idType为空时使用open_id，等同于Client.Chat.RemoveMembers
+func (s *MessageService) RemoveGroupMember(chatID string, userIDs []string, idType MemberIDType) (*MemberChangeResult, error) {
+	return newChatService(s.client).RemoveMembers(chatID, userIDs, idType)
 }
 
 // FileContent 文件消息内容
 type FileContent struct {
-	FileKey string `json:"file_key"`
+	FileKey  string `json:"file_key"`
+	FileName string `json:"file_name,omitempty"`
 }
 
 // Type 实现MessageContent接口
 func (f *FileContent) Type() MessageType {
-	return "file"
+	return MessageTypeFile
 }
 
 // Content 实现MessageContent接口
@@ -364,13 +420,66 @@ func (f *FileContent) Content() map[string]interface{} {
 }
 
 // SendFile 发送文件消息
-func (s *MessageService) SendFile(chatID string, fileKey string) error {
+func (s *MessageService) SendFile(chatID string, fileKey string, opts ...SendOption) error {
 	content := &FileContent{FileKey: fileKey}
-	return s.SendMessage(chatID, content)
+	return s.SendMessage(chatID, content, opts...)
 }
 
 // UploadFile 上传文件并获取file_key
 func (s *MessageService) UploadFile(fileBytes []byte, fileName string) (string, error) {
+	return s.UploadTypedFile(FileTypeStream, fileBytes, fileName, 0)
+}
+
+// FileType 上传文件的类型
+type FileType string
+
+const (
+	FileTypeOpus   FileType = "opus"   // 语音，需为opus格式
+	FileTypeMP4    FileType = "mp4"    // 视频，需为mp4格式
+	FileTypePDF    FileType = "pdf"    // PDF文档
+	FileTypeDoc    FileType = "doc"    // Word文档
+	FileTypeXls    FileType = "xls"    // Excel表格
+	FileTypePpt    FileType = "ppt"    // PPT演示文稿
+	FileTypeStream FileType = "stream" // 其他类型文件
+)
+
+// UploadTypedFile 上传指定类型的文件并获取file_key，duration为音视频时长(毫秒)，其他类型传0
+func (s *MessageService) UploadTypedFile(fileType FileType, fileBytes []byte, fileName string, duration int) (string, error) {
 	path := "/im/v1/files"
-	return s.client.UploadFile(path, fileBytes, fileName)
+	
+	fields := map[string]string{
+		"file_type": string(fileType),
+		"file_name": fileName,
+	}
+	if duration > 0 {
+		fields["duration"] = strconv.Itoa(duration)
+	}
+	
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			FileKey string `json:"file_key"`
+		} `json:"data"`
+	}
+	
+	if err := s.client.uploadMultipart(path, fields, fileBytes, fileName, &result); err != nil {
+		return "", err
+	}
+	
+	if result.Code != 0 {
+		return "", &Error{Code: result.Code, Message: result.Msg}
+	}
+	
+	return result.Data.FileKey, nil
+}
+
+// UploadAudio 上传opus格式的语音并获取file_key，用于发送语音消息
+func (s *MessageService) UploadAudio(audioBytes []byte, fileName string, duration int) (string, error) {
+	return s.UploadTypedFile(FileTypeOpus, audioBytes, fileName, duration)
+}
+
+// UploadVideo 上传mp4格式的视频并获取file_key，用于发送视频消息
+func (s *MessageService) UploadVideo(videoBytes []byte, fileName string, duration int) (string, error) {
+	return s.UploadTypedFile(FileTypeMP4, videoBytes, fileName, duration)
 }
\ No newline at end of file
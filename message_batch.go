@@ -0,0 +1,59 @@
+package easylark
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// BatchSendResult 批量发送的结果
+type BatchSendResult struct {
+	MessageIDs map[string]string // 发送成功的会话ID到message_id
+	Errors     map[string]error  // 发送失败的会话ID到错误
+}
+
+// Failed 获取发送失败的会话ID，按字典序排列
+func (r *BatchSendResult) Failed() []string {
+	ids := make([]string, 0, len(r.Errors))
+	for id := range r.Errors {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// BatchSendMessage 向多个会话依次发送同一条消息
+//
+// 配合WithUUID时每个会话使用由key和会话ID派生的uuid，整批重试不会产生重复消息。
+// 部分会话发送失败时返回的错误会列出失败的会话，result中包含每个会话的结果。
+func (s *MessageService) BatchSendMessage(chatIDs []string, content MessageContent, opts ...SendOption) (*BatchSendResult, error) {
+	if err := validateMessageContent(content); err != nil {
+		return nil, err
+	}
+	options := newSendOptions(opts)
+
+	result := &BatchSendResult{
+		MessageIDs: make(map[string]string),
+		Errors:     make(map[string]error),
+	}
+	for _, chatID := range chatIDs {
+		if _, ok := result.MessageIDs[chatID]; ok {
+			continue
+		}
+		if _, ok := result.Errors[chatID]; ok {
+			continue
+		}
+		messageID, err := s.sendMessage(chatID, content, options.withUUID(options.uuidFor("receiver", chatID)))
+		if err != nil {
+			result.Errors[chatID] = err
+			continue
+		}
+		result.MessageIDs[chatID] = messageID
+	}
+
+	if len(result.Errors) > 0 {
+		return result, fmt.Errorf("batch send failed for %d of %d receivers: %s",
+			len(result.Errors), len(chatIDs), strings.Join(result.Failed(), ", "))
+	}
+	return result, nil
+}
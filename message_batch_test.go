@@ -0,0 +1,47 @@
+package easylark
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestBatchSendMessage(t *testing.T) {
+	uuids := make(map[string]string)
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		chatID := reqBody["receive_id"].(string)
+		uuids[chatID], _ = reqBody["uuid"].(string)
+		if chatID == "chat_bad" {
+			w.Write([]byte(`{"code":230002,"msg":"bot not in chat"}`))
+			return
+		}
+		writeTestResponse(w, map[string]interface{}{"message_id": "om_" + chatID})
+	})
+
+	result, err := client.Message.BatchSendMessage(
+		[]string{"chat_a", "chat_bad", "chat_b", "chat_a"},
+		&TextContent{Text: "digest"},
+		WithUUID("digest-2024-01-01"),
+	)
+	if err == nil || !strings.Contains(err.Error(), "chat_bad") {
+		t.Errorf("Expected error listing failed chat, got %v", err)
+	}
+	if result == nil {
+		t.Fatal("Expected result")
+	}
+	if result.MessageIDs["chat_a"] != "om_chat_a" || result.MessageIDs["chat_b"] != "om_chat_b" {
+		t.Errorf("Unexpected message ids: %v", result.MessageIDs)
+	}
+	if failed := result.Failed(); len(failed) != 1 || failed[0] != "chat_bad" {
+		t.Errorf("Expected chat_bad to fail, got %v", failed)
+	}
+	if len(uuids) != 3 {
+		t.Errorf("Expected duplicate receivers to be sent once, got %v", uuids)
+	}
+	if uuids["chat_a"] == "" || uuids["chat_a"] == uuids["chat_b"] {
+		t.Errorf("Expected distinct uuid per receiver, got %v", uuids)
+	}
+}
@@ -0,0 +1,170 @@
+package easylark
+
+// AudioContent 语音消息内容
+type AudioContent struct {
+	FileKey  string `json:"file_key"`
+	Duration int    `json:"duration,omitempty"` // 时长，单位毫秒，仅接收消息时有值
+}
+
+// Type 实现MessageContent接口
+func (a *AudioContent) Type() MessageType {
+	return MessageTypeAudio
+}
+
+// Content 实现MessageContent接口
+func (a *AudioContent) Content() map[string]interface{} {
+	return map[string]interface{}{
+		"file_key": a.FileKey,
+	}
+}
+
+// MediaContent 视频消息内容
+type MediaContent struct {
+	FileKey  string `json:"file_key"`
+	ImageKey string `json:"image_key"` // 视频封面图片
+	FileName string `json:"file_name,omitempty"`
+	Duration int    `json:"duration,omitempty"` // 时长，单位毫秒，仅接收消息时有值
+}
+
+// Type 实现MessageContent接口
+func (m *MediaContent) Type() MessageType {
+	return MessageTypeMedia
+}
+
+// Content 实现MessageContent接口
+func (m *MediaContent) Content() map[string]interface{} {
+	return map[string]interface{}{
+		"file_key":  m.FileKey,
+		"image_key": m.ImageKey,
+	}
+}
+
+// StickerContent 表情包消息内容，目前仅支持发送机器人收到的表情包
+type StickerContent struct {
+	FileKey string `json:"file_key"`
+}
+
+// Type 实现MessageContent接口
+func (s *StickerContent) Type() MessageType {
+	return MessageTypeSticker
+}
+
+// Content 实现MessageContent接口
+func (s *StickerContent) Content() map[string]interface{} {
+	return map[string]interface{}{
+		"file_key": s.FileKey,
+	}
+}
+
+// ShareChatContent 群名片消息内容
+type ShareChatContent struct {
+	ChatID string `json:"chat_id"`
+}
+
+// Type 实现MessageContent接口
+func (s *ShareChatContent) Type() MessageType {
+	return MessageTypeShareChat
+}
+
+// Content 实现MessageContent接口
+func (s *ShareChatContent) Content() map[string]interface{} {
+	return map[string]interface{}{
+		"chat_id": s.ChatID,
+	}
+}
+
+// ShareUserContent 个人名片消息内容
+type ShareUserContent struct {
+	UserID string `json:"user_id"` // 用户的open_id
+}
+
+// Type 实现MessageContent接口
+func (s *ShareUserContent) Type() MessageType {
+	return MessageTypeShareUser
+}
+
+// Content 实现MessageContent接口
+func (s *ShareUserContent) Content() map[string]interface{} {
+	return map[string]interface{}{
+		"user_id": s.UserID,
+	}
+}
+
+// InteractiveContent 接收到的卡片消息内容，Card为卡片JSON
+type InteractiveContent struct {
+	Card map[string]interface{}
+}
+
+// Type 实现MessageContent接口
+func (i *InteractiveContent) Type() MessageType {
+	return MessageTypeInteractive
+}
+
+// Content 实现MessageContent接口
+func (i *InteractiveContent) Content() map[string]interface{} {
+	return i.Card
+}
+
+// SystemContent 系统消息内容，如入群、退群提示，仅接收
+type SystemContent struct {
+	Template   string   `json:"template"`
+	FromUser   []string `json:"from_user,omitempty"`
+	ToChatters []string `json:"to_chatters,omitempty"`
+}
+
+// Type 实现MessageContent接口
+func (s *SystemContent) Type() MessageType {
+	return MessageTypeSystem
+}
+
+// Content 实现MessageContent接口
+func (s *SystemContent) Content() map[string]interface{} {
+	return map[string]interface{}{
+		"template":    s.Template,
+		"from_user":   s.FromUser,
+		"to_chatters": s.ToChatters,
+	}
+}
+
+// MergeForwardContent 合并转发消息内容，仅接收
+type MergeForwardContent struct {
+	// Messages 被合并转发的子消息，通过GetMessageDetail获取时填充
+	Messages []*Message
+}
+
+// Type 实现MessageContent接口
+func (m *MergeForwardContent) Type() MessageType {
+	return MessageTypeMergeForward
+}
+
+// Content 实现MessageContent接口
+func (m *MergeForwardContent) Content() map[string]interface{} {
+	return map[string]interface{}{
+		"content": "Merged and Forwarded Message",
+	}
+}
+
+// SendAudio 发送语音消息，fileKey通过UploadAudio获取
+func (s *MessageService) SendAudio(chatID, fileKey string, opts ...SendOption) error {
+	return s.SendMessage(chatID, &AudioContent{FileKey: fileKey}, opts...)
+}
+
+// SendMedia 发送视频消息，fileKey通过UploadVideo获取，imageKey为通过UploadImage上传的封面
+func (s *MessageService) SendMedia(chatID, fileKey, imageKey string, opts ...SendOption) error {
+	return s.SendMessage(chatID, &MediaContent{FileKey: fileKey, ImageKey: imageKey}, opts...)
+}
+
+// SendSticker 发送表情包消息
+func (s *MessageService) SendSticker(chatID, fileKey string, opts ...SendOption) error {
+	return s.SendMessage(chatID, &StickerContent{FileKey: fileKey}, opts...)
+}
+
+// SendShareChat 发送群名片消息，sharedChatID为被分享的群
+func (s *MessageService) SendShareChat(chatID, sharedChatID string, opts ...SendOption) error {
+	return s.SendMessage(chatID, &ShareChatContent{ChatID: sharedChatID}, opts...)
+}
+
+// SendShareUser 发送个人名片消息，openID为被分享的用户
+func (s *MessageService) SendShareUser(chatID, openID string, opts ...SendOption) error {
+	return s.SendMessage(chatID, &ShareUserContent{UserID: openID}, opts...)
+}
@@ -0,0 +1,80 @@
+package easylark
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestSendAdditionalContentTypes(t *testing.T) {
+	var got []map[string]interface{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		got = append(got, reqBody)
+		writeTestResponse(w, map[string]interface{}{"message_id": "om_1"})
+	})
+
+	sends := []func() error{
+		func() error { return client.Message.SendAudio("chat123", "file_audio") },
+		func() error { return client.Message.SendMedia("chat123", "file_video", "img_cover") },
+		func() error { return client.Message.SendSticker("chat123", "file_sticker") },
+		func() error { return client.Message.SendShareChat("chat123", "oc_shared") },
+		func() error { return client.Message.SendShareUser("chat123", "ou_shared") },
+	}
+	for _, send := range sends {
+		if err := send(); err != nil {
+			t.Fatalf("send message failed: %v", err)
+		}
+	}
+
+	want := []struct {
+		msgType string
+		key     string
+		value   string
+	}{
+		{"audio", "file_key", "file_audio"},
+		{"media", "image_key", "img_cover"},
+		{"sticker", "file_key", "file_sticker"},
+		{"share_chat", "chat_id", "oc_shared"},
+		{"share_user", "user_id", "ou_shared"},
+	}
+	for i, w := range want {
+		if got[i]["msg_type"] != w.msgType {
+			t.Errorf("Expected msg_type '%s', got '%v'", w.msgType, got[i]["msg_type"])
+		}
+		content := got[i]["content"].(map[string]interface{})
+		if content[w.key] != w.value {
+			t.Errorf("Expected %s '%s', got '%v'", w.key, w.value, content[w.key])
+		}
+	}
+}
+
+func TestUploadAudioAndImage(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("parse multipart form failed: %v", err)
+		}
+		switch r.URL.Path {
+		case "/open-apis/im/v1/files":
+			if r.FormValue("file_type") != "opus" || r.FormValue("duration") != "3000" || r.FormValue("file_name") != "voice.opus" {
+				t.Errorf("Unexpected form values: %v", r.MultipartForm.Value)
+			}
+			writeTestResponse(w, map[string]interface{}{"file_key": "file_audio"})
+		case "/open-apis/im/v1/images":
+			if r.FormValue("image_type") != "message" {
+				t.Errorf("Expected image_type 'message', got '%s'", r.FormValue("image_type"))
+			}
+			writeTestResponse(w, map[string]interface{}{"image_key": "img_cover"})
+		}
+	})
+
+	fileKey, err := client.Message.UploadAudio([]byte("opus"), "voice.opus", 3000)
+	if err != nil || fileKey != "file_audio" {
+		t.Errorf("Expected file_key 'file_audio', got '%s', %v", fileKey, err)
+	}
+	imageKey, err := client.Message.UploadImage([]byte("png"), "cover.png")
+	if err != nil || imageKey != "img_cover" {
+		t.Errorf("Expected image_key 'img_cover', got '%s', %v", imageKey, err)
+	}
+}
@@ -0,0 +1,279 @@
+package easylark
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Message 接收到的消息
+type Message struct {
+	MessageID      string         `json:"message_id"`
+	RootID         string         `json:"root_id,omitempty"`
+	ParentID       string         `json:"parent_id,omitempty"`
+	ThreadID       string         `json:"thread_id,omitempty"`
+	MsgType        string         `json:"msg_type"`
+	CreateTime     string         `json:"create_time"`
+	UpdateTime     string         `json:"update_time,omitempty"`
+	Deleted        bool           `json:"deleted"`
+	Updated        bool           `json:"updated"`
+	ChatID         string         `json:"chat_id"`
+	Sender         *MessageSender `json:"sender,omitempty"`
+	Body           MessageBody    `json:"body"`
+	Mentions       []*Mention     `json:"mentions,omitempty"`
+	UpperMessageID string         `json:"upper_message_id,omitempty"`
+
+	// Children 合并转发消息的子消息
+	Children []*Message `json:"-"`
+}
+
+// MessageBody 消息体，Content为JSON字符串
+type MessageBody struct {
+	Content string `json:"content"`
+}
+
+// MessageSender 消息发送者
+type MessageSender struct {
+	ID         string `json:"id"`
+	IDType     string `json:"id_type"`
+	SenderType string `json:"sender_type"`
+	TenantKey  string `json:"tenant_key,omitempty"`
+}
+
+// Mention 消息中的@信息
+type Mention struct {
+	Key       string // 消息内容中的占位符，如@_user_1
+	OpenID    string
+	UserID    string
+	UnionID   string
+	Name      string
+	TenantKey string
+}
+
+// UnmarshalJSON 兼容消息接口和事件中两种id格式
+func (m *Mention) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Key       string          `json:"key"`
+		ID        json.RawMessage `json:"id"`
+		IDType    string          `json:"id_type"`
+		Name      string          `json:"name"`
+		TenantKey string          `json:"tenant_key"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*m = Mention{Key: raw.Key, Name: raw.Name, TenantKey: raw.TenantKey}
+	if len(raw.ID) == 0 {
+		return nil
+	}
+
+	var id string
+	if err := json.Unmarshal(raw.ID, &id); err == nil {
+		switch raw.IDType {
+		case "user_id":
+			m.UserID = id
+		case "union_id":
+			m.UnionID = id
+		default:
+			m.OpenID = id
+		}
+		return nil
+	}
+
+	var ids struct {
+		OpenID  string `json:"open_id"`
+		UserID  string `json:"user_id"`
+		UnionID string `json:"union_id"`
+	}
+	if err := json.Unmarshal(raw.ID, &ids); err != nil {
+		return fmt.Errorf("unmarshal mention id failed: %w", err)
+	}
+	m.OpenID, m.UserID, m.UnionID = ids.OpenID, ids.UserID, ids.UnionID
+	return nil
+}
+
+// PlainText 将文本中的@占位符替换为@用户名
+func (t *TextContent) PlainText() string {
+	return replaceMentionKeys(t.Text, t.Mentions)
+}
+
+// replaceMentionKeys 替换@占位符，优先替换较长的key避免@_user_1误匹配@_user_10
+func replaceMentionKeys(text string, mentions []*Mention) string {
+	sorted := make([]*Mention, 0, len(mentions))
+	for _, mention := range mentions {
+		if mention != nil && mention.Key != "" {
+			sorted = append(sorted, mention)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return len(sorted[i].Key) > len(sorted[j].Key)
+	})
+	for _, mention := range sorted {
+		text = strings.ReplaceAll(text, mention.Key, "@"+mention.Name)
+	}
+	return text
+}
+
+// DecodeContent 将消息体解析为对应类型的消息内容
+func (m *Message) DecodeContent() (MessageContent, error) {
+	content, err := DecodeMessageContent(m.MsgType, m.Body.Content, m.Mentions)
+	if err != nil {
+		return nil, err
+	}
+	if merged, ok := content.(*MergeForwardContent); ok {
+		merged.Messages = m.Children
+	}
+	return content, nil
+}
+
+// DecodeMessageContent 根据消息类型将JSON格式的消息内容解析为对应的消息内容
+//
+// 返回值的具体类型为*TextContent、*PostContent、*ImageContent、*FileContent、
+// *AudioContent、*MediaContent、*StickerContent、*ShareChatContent、
+// *ShareUserContent、*InteractiveContent、*SystemContent或*MergeForwardContent。
+func DecodeMessageContent(msgType, content string, mentions []*Mention) (MessageContent, error) {
+	var target MessageContent
+	switch MessageType(msgType) {
+	case MessageTypeText:
+		var text struct {
+			Text string `json:"text"`
+		}
+		if err := unmarshalMessageContent(msgType, content, &text); err != nil {
+			return nil, err
+		}
+		return &TextContent{Text: text.Text, Mentions: mentions}, nil
+	case MessageTypePost:
+		return decodePostContent(content, mentions)
+	case MessageTypeInteractive, MessageTypeInteract:
+		card := make(map[string]interface{})
+		if err := unmarshalMessageContent(msgType, content, &card); err != nil {
+			return nil, err
+		}
+		return &InteractiveContent{Card: card}, nil
+	case MessageTypeMergeForward:
+		return &MergeForwardContent{}, nil
+	case MessageTypeImage:
+		target = &ImageContent{}
+	case MessageTypeFile:
+		target = &FileContent{}
+	case MessageTypeAudio:
+		target = &AudioContent{}
+	case MessageTypeMedia:
+		target = &MediaContent{}
+	case MessageTypeSticker:
+		target = &StickerContent{}
+	case MessageTypeShareChat:
+		target = &ShareChatContent{}
+	case MessageTypeShareUser:
+		target = &ShareUserContent{}
+	case MessageTypeSystem:
+		target = &SystemContent{}
+	default:
+		return nil, fmt.Errorf("decode message content failed: unsupported msg_type %q", msgType)
+	}
+
+	if err := unmarshalMessageContent(msgType, content, target); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// unmarshalMessageContent 解析消息内容JSON
+func unmarshalMessageContent(msgType, content string, v interface{}) error {
+	if err := json.Unmarshal([]byte(content), v); err != nil {
+		return fmt.Errorf("decode %s message content failed: %w", msgType, err)
+	}
+	return nil
+}
+
+// decodePostContent 解析富文本消息，兼容接收时的单语言格式和发送时的多语言格式
+func decodePostContent(content string, mentions []*Mention) (*PostContent, error) {
+	var raw map[string]json.RawMessage
+	if err := unmarshalMessageContent(string(MessageTypePost), content, &raw); err != nil {
+		return nil, err
+	}
+	if wrapped, ok := raw["post"]; ok {
+		content = string(wrapped)
+		raw = nil
+		if err := unmarshalMessageContent(string(MessageTypePost), content, &raw); err != nil {
+			return nil, err
+		}
+	}
+
+	post := &PostContent{}
+	var target interface{} = post
+	if _, ok := raw["content"]; ok {
+		post.ZhCn = &PostBody{}
+		target = post.ZhCn
+	}
+	if err := unmarshalMessageContent(string(MessageTypePost), content, target); err != nil {
+		return nil, err
+	}
+
+	for _, body := range []*PostBody{post.ZhCn, post.EnUs} {
+		if body != nil {
+			resolvePostMentions(body, mentions)
+		}
+	}
+	return post, nil
+}
+
+// resolvePostMentions 将富文本中@元素的占位符替换为用户open_id
+func resolvePostMentions(body *PostBody, mentions []*Mention) {
+	for _, paragraph := range body.Content {
+		for i := range paragraph {
+			element := &paragraph[i]
+			if element.Tag != PostTagAt {
+				continue
+			}
+			for _, mention := range mentions {
+				if mention != nil && mention.Key == element.UserId {
+					element.UserId = mention.OpenID
+					if element.UserName == "" {
+						element.UserName = mention.Name
+					}
+					break
+				}
+			}
+		}
+	}
+}
+
+// GetMessageDetail 获取消息并解析为Message，合并转发消息的子消息填充到Children
+func (s *MessageService) GetMessageDetail(messageID string) (*Message, error) {
+	path := fmt.Sprintf("/im/v1/messages/%s", messageID)
+
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			Items []*Message `json:"items"`
+		} `json:"data"`
+	}
+
+	err := s.client.DoRequest("GET", path, nil, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Code != 0 {
+		return nil, &Error{Code: result.Code, Message: result.Msg}
+	}
+
+	byID := make(map[string]*Message, len(result.Data.Items))
+	for _, item := range result.Data.Items {
+		byID[item.MessageID] = item
+	}
+	for _, item := range result.Data.Items {
+		if upper, ok := byID[item.UpperMessageID]; ok && item.UpperMessageID != item.MessageID {
+			upper.Children = append(upper.Children, item)
+		}
+	}
+
+	message, ok := byID[messageID]
+	if !ok {
+		return nil, fmt.Errorf("get message failed: message %s not found in response", messageID)
+	}
+	return message, nil
+}
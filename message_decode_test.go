@@ -0,0 +1,143 @@
+package easylark
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestDecodeMessageContent(t *testing.T) {
+	tests := []struct {
+		msgType string
+		content string
+		check   func(t *testing.T, content MessageContent)
+	}{
+		{"image", `{"image_key":"img_1"}`, func(t *testing.T, content MessageContent) {
+			if c := content.(*ImageContent); c.ImageKey != "img_1" {
+				t.Errorf("Unexpected image content: %+v", c)
+			}
+		}},
+		{"file", `{"file_key":"file_1","file_name":"a.txt"}`, func(t *testing.T, content MessageContent) {
+			if c := content.(*FileContent); c.FileName != "a.txt" {
+				t.Errorf("Unexpected file content: %+v", c)
+			}
+		}},
+		{"audio", `{"file_key":"file_2","duration":2000}`, func(t *testing.T, content MessageContent) {
+			if c := content.(*AudioContent); c.Duration != 2000 {
+				t.Errorf("Unexpected audio content: %+v", c)
+			}
+		}},
+		{"media", `{"file_key":"file_3","image_key":"img_3","file_name":"a.mp4","duration":1000}`, func(t *testing.T, content MessageContent) {
+			if c := content.(*MediaContent); c.ImageKey != "img_3" {
+				t.Errorf("Unexpected media content: %+v", c)
+			}
+		}},
+		{"sticker", `{"file_key":"file_4"}`, func(t *testing.T, content MessageContent) {
+			if c := content.(*StickerContent); c.FileKey != "file_4" {
+				t.Errorf("Unexpected sticker content: %+v", c)
+			}
+		}},
+		{"share_chat", `{"chat_id":"oc_1"}`, func(t *testing.T, content MessageContent) {
+			if c := content.(*ShareChatContent); c.ChatID != "oc_1" {
+				t.Errorf("Unexpected share_chat content: %+v", c)
+			}
+		}},
+		{"share_user", `{"user_id":"ou_1"}`, func(t *testing.T, content MessageContent) {
+			if c := content.(*ShareUserContent); c.UserID != "ou_1" {
+				t.Errorf("Unexpected share_user content: %+v", c)
+			}
+		}},
+		{"interactive", `{"title":"t","elements":[]}`, func(t *testing.T, content MessageContent) {
+			if c := content.(*InteractiveContent); c.Card["title"] != "t" || c.Type() != MessageTypeInteractive {
+				t.Errorf("Unexpected interactive content: %+v", c)
+			}
+		}},
+		{"system", `{"template":"{from_user} invited {to_chatters}","from_user":["A"],"to_chatters":["B"]}`, func(t *testing.T, content MessageContent) {
+			if c := content.(*SystemContent); len(c.ToChatters) != 1 {
+				t.Errorf("Unexpected system content: %+v", c)
+			}
+		}},
+		{"merge_forward", `{"content":"Merged and Forwarded Message"}`, func(t *testing.T, content MessageContent) {
+			if _, ok := content.(*MergeForwardContent); !ok {
+				t.Errorf("Unexpected merge_forward content: %T", content)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		content, err := DecodeMessageContent(tt.msgType, tt.content, nil)
+		if err != nil {
+			t.Errorf("decode %s failed: %v", tt.msgType, err)
+			continue
+		}
+		tt.check(t, content)
+	}
+
+	if _, err := DecodeMessageContent("location", `{}`, nil); err == nil {
+		t.Error("Expected error for unsupported msg_type")
+	}
+	if _, err := DecodeMessageContent("text", `not json`, nil); err == nil {
+		t.Error("Expected error for invalid content")
+	}
+}
+
+func TestDecodeMessageMentions(t *testing.T) {
+	var mentions []*Mention
+	data := `[{"key":"@_user_1","id":"ou_1","id_type":"open_id","name":"Tom"},` +
+		`{"key":"@_user_10","id":{"open_id":"ou_10","user_id":"u10"},"name":"Jerry"}]`
+	if err := json.Unmarshal([]byte(data), &mentions); err != nil {
+		t.Fatalf("unmarshal mentions failed: %v", err)
+	}
+	if mentions[0].OpenID != "ou_1" || mentions[1].UserID != "u10" {
+		t.Errorf("Unexpected mentions: %+v %+v", mentions[0], mentions[1])
+	}
+
+	content, err := DecodeMessageContent("text", `{"text":"@_user_1 @_user_10 hi"}`, mentions)
+	if err != nil {
+		t.Fatalf("decode text failed: %v", err)
+	}
+	text := content.(*TextContent)
+	if text.PlainText() != "@Tom @Jerry hi" {
+		t.Errorf("Expected '@Tom @Jerry hi', got '%s'", text.PlainText())
+	}
+
+	content, err = DecodeMessageContent("post", `{"title":"t","content":[[{"tag":"at","user_id":"@_user_1"},{"tag":"text","text":" hi"}]]}`, mentions)
+	if err != nil {
+		t.Fatalf("decode post failed: %v", err)
+	}
+	at := content.(*PostContent).ZhCn.Content[0][0]
+	if at.UserId != "ou_1" || at.UserName != "Tom" {
+		t.Errorf("Expected resolved post mention, got %+v", at)
+	}
+}
+
+func TestGetMessageDetail(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/open-apis/im/v1/messages/om_merge" {
+			t.Errorf("Unexpected path '%s'", r.URL.Path)
+		}
+		writeTestResponse(w, map[string]interface{}{
+			"items": []map[string]interface{}{
+				{"message_id": "om_merge", "msg_type": "merge_forward", "body": map[string]string{"content": "Merged and Forwarded Message"}},
+				{"message_id": "om_child", "msg_type": "text", "upper_message_id": "om_merge", "body": map[string]string{"content": `{"text":"hello"}`}},
+			},
+		})
+	})
+
+	message, err := client.Message.GetMessageDetail("om_merge")
+	if err != nil {
+		t.Fatalf("get message detail failed: %v", err)
+	}
+	content, err := message.DecodeContent()
+	if err != nil {
+		t.Fatalf("decode message failed: %v", err)
+	}
+	merged := content.(*MergeForwardContent)
+	if len(merged.Messages) != 1 || merged.Messages[0].MessageID != "om_child" {
+		t.Fatalf("Expected one child message, got %+v", merged.Messages)
+	}
+	child, err := merged.Messages[0].DecodeContent()
+	if err != nil || child.(*TextContent).Text != "hello" {
+		t.Errorf("Unexpected child content: %+v, %v", child, err)
+	}
+}
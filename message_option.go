@@ -0,0 +1,154 @@
+package easylark
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ReceiveIDType 消息接收者的ID类型
+type ReceiveIDType string
+
+// 接收者ID类型
+const (
+	ReceiveIDTypeChatID  ReceiveIDType = "chat_id"
+	ReceiveIDTypeOpenID  ReceiveIDType = "open_id"
+	ReceiveIDTypeUserID  ReceiveIDType = "user_id"
+	ReceiveIDTypeUnionID ReceiveIDType = "union_id"
+	ReceiveIDTypeEmail   ReceiveIDType = "email"
+)
+
+// SendOption 发送消息的可选配置
+type SendOption func(*sendOptions)
+
+// sendOptions 发送消息的配置
+type sendOptions struct {
+	split         bool
+	splitLimit    int
+	threadReplies bool
+	uuid          string
+	receiveIDType ReceiveIDType
+}
+
+// newSendOptions 应用可选配置
+func newSendOptions(opts []SendOption) *sendOptions {
+	options := &sendOptions{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(options)
+		}
+	}
+	return options
+}
+
+// idType 获取接收者ID类型，默认为chat_id
+func (o *sendOptions) idType() ReceiveIDType {
+	if o.receiveIDType == "" {
+		return ReceiveIDTypeChatID
+	}
+	return o.receiveIDType
+}
+
+// limit 获取拆分时单条消息内容的字节上限
+func (o *sendOptions) limit(msgType MessageType) int {
+	if o.splitLimit > 0 {
+		return o.splitLimit
+	}
+	return MessageSizeLimit(msgType)
+}
+
+// WithSplit 内容超过大小限制时拆分为多条消息依次发送，仅对SendText和SendPost生效
+//
+// 文本按行拆分，富文本按段落拆分，单行超限的文本会被截断为多段。
+func WithSplit() SendOption {
+	return func(o *sendOptions) {
+		o.split = true
+	}
+}
+
+// WithSplitLimit 启用拆分并自定义单条消息内容的字节上限
+func WithSplitLimit(bytes int) SendOption {
+	return func(o *sendOptions) {
+		o.split = true
+		o.splitLimit = bytes
+	}
+}
+
+// WithSplitThread 启用拆分，并将第一条之后的消息以回复第一条消息的方式发送
+func WithSplitThread() SendOption {
+	return func(o *sendOptions) {
+		o.split = true
+		o.threadReplies = true
+	}
+}
+
+// WithReceiveIDType 指定接收者ID的类型，默认chatID参数为群ID，可改为向用户单独发送
+func WithReceiveIDType(idType ReceiveIDType) SendOption {
+	return func(o *sendOptions) {
+		o.receiveIDType = idType
+	}
+}
+
+// WithUUID 设置消息的幂等键，相同key在1小时内重复发送只会产生一条消息
+//
+// uuid由key确定性派生，重试时传入相同的key即可安全重发；
+// 拆分发送和批量发送时会为每条消息分别派生不同的uuid。
+func WithUUID(key string) SendOption {
+	return func(o *sendOptions) {
+		if key != "" {
+			o.uuid = deriveUUID(key)
+		}
+	}
+}
+
+// WithAutoUUID 生成随机的幂等键
+//
+// uuid在调用WithAutoUUID时生成，重试时复用同一个SendOption即可避免重复消息。
+func WithAutoUUID() SendOption {
+	return WithUUID(newAutoUUIDKey())
+}
+
+// randRead 随机数来源，测试时可替换
+var randRead = rand.Read
+
+// autoUUIDCounter 随机数不可用时用于生成幂等键的计数器
+var autoUUIDCounter uint64
+
+// newAutoUUIDKey 生成随机的幂等键，随机数不可用时使用进程、时间和计数器生成
+func newAutoUUIDKey() string {
+	buf := make([]byte, 16)
+	if _, err := randRead(buf); err == nil {
+		return fmt.Sprintf("%x", buf)
+	}
+	return fmt.Sprintf("%d-%d-%d", os.Getpid(), time.Now().UnixNano(), atomic.AddUint64(&autoUUIDCounter, 1))
+}
+
+// uuidFor 为拆分或批量发送中的单条消息派生uuid，未设置幂等键时返回空
+func (o *sendOptions) uuidFor(parts ...string) string {
+	if o.uuid == "" {
+		return ""
+	}
+	if len(parts) == 0 {
+		return o.uuid
+	}
+	return deriveUUID(o.uuid + "/" + strings.Join(parts, "/"))
+}
+
+// withUUID 复制配置并替换uuid
+func (o *sendOptions) withUUID(uuid string) *sendOptions {
+	copied := *o
+	copied.uuid = uuid
+	return &copied
+}
+
+// deriveUUID 由key计算UUID格式的幂等键，长度为36，满足接口不超过50字符的限制
+func deriveUUID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	sum[6] = sum[6]&0x0f | 0x50
+	sum[8] = sum[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
@@ -0,0 +1,90 @@
+package easylark
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWithUUID(t *testing.T) {
+	a := newSendOptions([]SendOption{WithUUID("alert-42")})
+	b := newSendOptions([]SendOption{WithUUID("alert-42")})
+	c := newSendOptions([]SendOption{WithUUID("alert-43")})
+
+	if a.uuid == "" || a.uuid != b.uuid {
+		t.Errorf("Expected same key to derive same uuid, got %q and %q", a.uuid, b.uuid)
+	}
+	if a.uuid == c.uuid {
+		t.Error("Expected different keys to derive different uuids")
+	}
+	if len(a.uuid) > 50 {
+		t.Errorf("Expected uuid within 50 chars, got %d", len(a.uuid))
+	}
+	if a.uuidFor("chunk", "0") == a.uuidFor("chunk", "1") {
+		t.Error("Expected derived uuids to differ per chunk")
+	}
+	if newSendOptions(nil).uuidFor("chunk", "0") != "" {
+		t.Error("Expected no uuid without WithUUID")
+	}
+
+	auto := WithAutoUUID()
+	first := newSendOptions([]SendOption{auto})
+	second := newSendOptions([]SendOption{auto})
+	if first.uuid == "" || first.uuid != second.uuid {
+		t.Error("Expected reused WithAutoUUID option to keep its uuid")
+	}
+	if newSendOptions([]SendOption{WithAutoUUID()}).uuid == first.uuid {
+		t.Error("Expected each WithAutoUUID call to generate a new uuid")
+	}
+
+	// 随机数不可用时仍然生成幂等键
+	randRead = func(b []byte) (int, error) { return 0, errors.New("no entropy") }
+	defer func() { randRead = rand.Read }()
+	fallback := newSendOptions([]SendOption{WithAutoUUID()}).uuid
+	if fallback == "" || fallback == newSendOptions([]SendOption{WithAutoUUID()}).uuid {
+		t.Errorf("Expected distinct fallback uuids, got %q", fallback)
+	}
+}
+
+func TestSendMessageUUID(t *testing.T) {
+	var uuids []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		uuid, _ := reqBody["uuid"].(string)
+		uuids = append(uuids, uuid)
+		writeTestResponse(w, map[string]interface{}{"message_id": "om_1"})
+	})
+
+	if err := client.Message.SendText("chat123", "hello"); err != nil {
+		t.Fatalf("send text failed: %v", err)
+	}
+	if err := client.Message.SendText("chat123", "hello", WithUUID("job-1")); err != nil {
+		t.Fatalf("send text failed: %v", err)
+	}
+	if err := client.Message.ReplyMessage("om_0", &TextContent{Text: "hi"}, WithUUID("job-1")); err != nil {
+		t.Fatalf("reply message failed: %v", err)
+	}
+	if err := client.Message.SendText("chat123", "aaaaa\nbbbbb", WithUUID("job-1"), WithSplitLimit(20)); err != nil {
+		t.Fatalf("send split text failed: %v", err)
+	}
+
+	if len(uuids) != 5 {
+		t.Fatalf("Expected 5 requests, got %d", len(uuids))
+	}
+	if uuids[0] != "" {
+		t.Errorf("Expected no uuid by default, got %q", uuids[0])
+	}
+	if uuids[1] != deriveUUID("job-1") || uuids[2] != uuids[1] {
+		t.Errorf("Expected uuid derived from key, got %v", uuids)
+	}
+	if uuids[3] == "" || uuids[3] == uuids[4] || uuids[3] == uuids[1] {
+		t.Errorf("Expected distinct uuid per chunk, got %v", uuids[3:])
+	}
+	if strings.Count(strings.Join(uuids, ""), "-") != 16 {
+		t.Errorf("Expected uuid format, got %v", uuids)
+	}
+}
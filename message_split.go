@@ -0,0 +1,231 @@
+package easylark
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 消息内容的大小上限
+const (
+	TextMessageSizeLimit = 150 * 1024 // 文本消息
+	PostMessageSizeLimit = 30 * 1024  // 富文本消息
+	CardMessageSizeLimit = 30 * 1024  // 卡片消息
+)
+
+// MessageSizeLimit 获取消息类型的内容大小上限，0表示不检查
+func MessageSizeLimit(msgType MessageType) int {
+	switch msgType {
+	case MessageTypeText:
+		return TextMessageSizeLimit
+	case MessageTypePost:
+		return PostMessageSizeLimit
+	case MessageTypeInteract, MessageTypeInteractive:
+		return CardMessageSizeLimit
+	}
+	return 0
+}
+
+// MessageSizeError 消息内容超过大小上限
+type MessageSizeError struct {
+	MsgType MessageType
+	Size    int
+	Limit   int
+}
+
+// Error 实现error接口
+func (e *MessageSizeError) Error() string {
+	return fmt.Sprintf("message too large: %s content is %d bytes, limit is %d bytes", e.MsgType, e.Size, e.Limit)
+}
+
+// contentSize 计算消息内容序列化后的字节数
+func contentSize(content MessageContent) (int, error) {
+	data, err := json.Marshal(content.Content())
+	if err != nil {
+		return 0, fmt.Errorf("marshal message content failed: %w", err)
+	}
+	return len(data), nil
+}
+
+// checkMessageSize 在调用接口前检查消息内容大小
+func checkMessageSize(content MessageContent) error {
+	limit := MessageSizeLimit(content.Type())
+	if limit == 0 {
+		return nil
+	}
+	size, err := contentSize(content)
+	if err != nil {
+		return err
+	}
+	if size > limit {
+		return &MessageSizeError{MsgType: content.Type(), Size: size, Limit: limit}
+	}
+	return nil
+}
+
+// jsonLen 计算字符串作为JSON字符串值时的字节数，不含引号
+func jsonLen(s string) int {
+	data, _ := json.Marshal(s)
+	return len(data) - 2
+}
+
+// splitText 将文本按行拆分，使每段序列化后不超过limit字节
+func splitText(text string, limit int) ([]string, error) {
+	overhead, err := contentSize(&TextContent{})
+	if err != nil {
+		return nil, err
+	}
+	budget := limit - overhead
+	if budget <= 0 {
+		return nil, fmt.Errorf("split text failed: limit %d is too small", limit)
+	}
+
+	var chunks []string
+	var current strings.Builder
+	currentLen := 0
+	flush := func() {
+		if current.Len() > 0 {
+			chunks = append(chunks, strings.TrimSuffix(current.String(), "\n"))
+			current.Reset()
+			currentLen = 0
+		}
+	}
+
+	for _, line := range strings.SplitAfter(text, "\n") {
+		lineLen := jsonLen(line)
+		if currentLen+lineLen <= budget {
+			current.WriteString(line)
+			currentLen += lineLen
+			continue
+		}
+		flush()
+		if lineLen <= budget {
+			current.WriteString(line)
+			currentLen = lineLen
+			continue
+		}
+
+		// 单行超限时按字符截断
+		for _, r := range line {
+			runeLen := jsonLen(string(r))
+			if currentLen+runeLen > budget {
+				flush()
+			}
+			current.WriteRune(r)
+			currentLen += runeLen
+		}
+	}
+	flush()
+
+	if len(chunks) == 0 {
+		chunks = append(chunks, text)
+	}
+	return chunks, nil
+}
+
+// splitPost 将富文本按段落拆分，使每条消息序列化后不超过limit字节
+func splitPost(post *PostContent, limit int) ([]*PostContent, error) {
+	bodies := []*PostBody{post.ZhCn, post.EnUs}
+	locales := 0
+	for _, body := range bodies {
+		if body != nil {
+			locales++
+		}
+	}
+	if locales == 0 {
+		return nil, fmt.Errorf("split post failed: no content for any language")
+	}
+
+	parts := make([][][][]PostElement, len(bodies))
+	count := 0
+	for i, body := range bodies {
+		if body == nil {
+			continue
+		}
+		chunks, err := splitPostParagraphs(body, limit/locales)
+		if err != nil {
+			return nil, err
+		}
+		parts[i] = chunks
+		if len(chunks) > count {
+			count = len(chunks)
+		}
+	}
+
+	posts := make([]*PostContent, 0, count)
+	for n := 0; n < count; n++ {
+		chunk := &PostContent{}
+		for i, body := range bodies {
+			if body == nil || n >= len(parts[i]) {
+				continue
+			}
+			chunkBody := &PostBody{Title: body.Title, Content: parts[i][n]}
+			if count > 1 {
+				chunkBody.Title = strings.TrimSpace(fmt.Sprintf("%s (%d/%d)", body.Title, n+1, count))
+			}
+			if i == 0 {
+				chunk.ZhCn = chunkBody
+			} else {
+				chunk.EnUs = chunkBody
+			}
+		}
+		posts = append(posts, chunk)
+	}
+	return posts, nil
+}
+
+// splitPostParagraphs 将单一语言的富文本段落分组
+func splitPostParagraphs(body *PostBody, budget int) ([][][]PostElement, error) {
+	overhead, err := contentSize(&PostContent{ZhCn: &PostBody{Title: body.Title + " (999/999)", Content: [][]PostElement{}}})
+	if err != nil {
+		return nil, err
+	}
+
+	var chunks [][][]PostElement
+	var current [][]PostElement
+	size := overhead
+	for _, paragraph := range body.Content {
+		data, err := json.Marshal(paragraph)
+		if err != nil {
+			return nil, fmt.Errorf("marshal post paragraph failed: %w", err)
+		}
+		paragraphSize := len(data) + 1
+		if overhead+paragraphSize > budget {
+			return nil, &MessageSizeError{MsgType: MessageTypePost, Size: overhead + paragraphSize, Limit: budget}
+		}
+		if size+paragraphSize > budget {
+			chunks = append(chunks, current)
+			current = nil
+			size = overhead
+		}
+		current = append(current, paragraph)
+		size += paragraphSize
+	}
+	if len(current) > 0 || len(chunks) == 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks, nil
+}
+
+// sendChunks 依次发送拆分后的消息，启用WithSplitThread时后续消息回复第一条，每条消息使用独立派生的uuid
+func (s *MessageService) sendChunks(chatID string, contents []MessageContent, options *sendOptions) error {
+	firstID := ""
+	for i, content := range contents {
+		chunkOptions := options.withUUID(options.uuidFor("chunk", strconv.Itoa(i)))
+		var err error
+		if i > 0 && options.threadReplies && firstID != "" {
+			_, err = s.replyMessage(firstID, content, chunkOptions)
+		} else {
+			var messageID string
+			messageID, err = s.sendMessage(chatID, content, chunkOptions)
+			if i == 0 {
+				firstID = messageID
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("send chunk %d/%d failed: %w", i+1, len(contents), err)
+		}
+	}
+	return nil
+}
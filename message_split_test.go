@@ -0,0 +1,118 @@
+package easylark
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSplitText(t *testing.T) {
+	lines := make([]string, 0, 50)
+	for i := 0; i < 50; i++ {
+		lines = append(lines, strings.Repeat("x", 18))
+	}
+	text := strings.Join(lines, "\n")
+
+	chunks, err := splitText(text, 200)
+	if err != nil {
+		t.Fatalf("split text failed: %v", err)
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("Expected multiple chunks, got %d", len(chunks))
+	}
+	if strings.Join(chunks, "\n") != text {
+		t.Error("Expected chunks to join back to the original text")
+	}
+	for i, chunk := range chunks {
+		size, _ := contentSize(&TextContent{Text: chunk})
+		if size > 200 {
+			t.Errorf("Chunk %d is %d bytes, exceeds limit", i, size)
+		}
+		if strings.HasPrefix(chunk, "\n") || strings.HasSuffix(chunk, "\n") {
+			t.Errorf("Chunk %d should be split on line boundaries: %q", i, chunk)
+		}
+	}
+
+	long := strings.Repeat("中", 100)
+	chunks, err = splitText(long, 100)
+	if err != nil {
+		t.Fatalf("split long line failed: %v", err)
+	}
+	if strings.Join(chunks, "") != long {
+		t.Error("Expected long line chunks to join back to the original text")
+	}
+}
+
+func TestSendTextSplitThread(t *testing.T) {
+	var requests []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		content := reqBody["content"].(map[string]interface{})
+		requests = append(requests, r.URL.Path+" "+content["text"].(string))
+		writeTestResponse(w, map[string]interface{}{"message_id": "om_first"})
+	})
+
+	text := "line one\nline two\nline three"
+	if err := client.Message.SendText("chat123", text, WithSplitLimit(30), WithSplitThread()); err != nil {
+		t.Fatalf("send text failed: %v", err)
+	}
+
+	want := []string{
+		"/open-apis/im/v1/messages line one",
+		"/open-apis/im/v1/messages/om_first/reply line two",
+		"/open-apis/im/v1/messages/om_first/reply line three",
+	}
+	if strings.Join(requests, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected requests %v, got %v", want, requests)
+	}
+}
+
+func TestSendPostSplit(t *testing.T) {
+	var titles []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var reqBody struct {
+			Content struct {
+				Post PostContent `json:"post"`
+			} `json:"content"`
+		}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		titles = append(titles, reqBody.Content.Post.ZhCn.Title)
+		writeTestResponse(w, map[string]interface{}{"message_id": "om_1"})
+	})
+
+	builder := NewPostBuilder("日志")
+	for i := 0; i < 20; i++ {
+		builder.Text(strings.Repeat("log ", 10)).Paragraph()
+	}
+	post, err := builder.Build()
+	if err != nil {
+		t.Fatalf("build post failed: %v", err)
+	}
+
+	if err := client.Message.SendPost("chat123", post, WithSplitLimit(400)); err != nil {
+		t.Fatalf("send post failed: %v", err)
+	}
+	if len(titles) < 2 || titles[0] != "日志 (1/"+string(rune('0'+len(titles)))+")" {
+		t.Errorf("Expected numbered titles, got %v", titles)
+	}
+}
+
+func TestSendCardTooLarge(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected oversized card not to be sent")
+	})
+
+	card := NewMessageCard().SetTitle("dump").AddMarkdown(strings.Repeat("a", CardMessageSizeLimit))
+	err := client.Message.SendCard("chat123", card)
+
+	var sizeErr *MessageSizeError
+	if !errors.As(err, &sizeErr) {
+		t.Fatalf("Expected MessageSizeError, got %v", err)
+	}
+	if sizeErr.Size <= CardMessageSizeLimit || sizeErr.Limit != CardMessageSizeLimit {
+		t.Errorf("Unexpected size error: %+v", sizeErr)
+	}
+}
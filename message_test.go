@@ -184,8 +184,8 @@ func TestSendCard(t *testing.T) {
 				t.Errorf("Expected receive_id 'chat123', got '%s'", reqBody["receive_id"])
 			}
 
-			if reqBody["msg_type"] != "interact" {
-				t.Errorf("Expected msg_type 'interact', got '%s'", reqBody["msg_type"])
+			if reqBody["msg_type"] != "interactive" {
+				t.Errorf("Expected msg_type 'interactive', got '%s'", reqBody["msg_type"])
 			}
 
 			// 返回模拟响应
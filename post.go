@@ -0,0 +1,239 @@
+package easylark
+
+import (
+	"fmt"
+)
+
+// 富文本元素标签
+const (
+	PostTagText      = "text"       // 文本
+	PostTagLink      = "a"          // 超链接
+	PostTagAt        = "at"         // @用户
+	PostTagImage     = "img"        // 图片
+	PostTagMedia     = "media"      // 视频
+	PostTagEmotion   = "emotion"    // 表情
+	PostTagCodeBlock = "code_block" // 代码块
+	PostTagHR        = "hr"         // 分割线
+	PostTagMarkdown  = "md"         // Markdown
+)
+
+// TextStyle 富文本文字样式
+type TextStyle string
+
+const (
+	TextStyleBold        TextStyle = "bold"        // 加粗
+	TextStyleItalic      TextStyle = "italic"      // 斜体
+	TextStyleUnderline   TextStyle = "underline"   // 下划线
+	TextStyleLineThrough TextStyle = "lineThrough" // 删除线
+)
+
+// PostBuilder 富文本消息构造器
+//
+// 元素总是追加到当前段落，调用Paragraph开始新段落；
+// 代码块、分割线和Markdown元素会自动独占一个段落。
+type PostBuilder struct {
+	title      string
+	paragraphs [][]PostElement
+	current    []PostElement
+}
+
+// NewPostBuilder 创建富文本消息构造器
+func NewPostBuilder(title string) *PostBuilder {
+	return &PostBuilder{title: title}
+}
+
+// Title 设置标题
+func (b *PostBuilder) Title(title string) *PostBuilder {
+	b.title = title
+	return b
+}
+
+// Paragraph 结束当前段落并开始新段落
+func (b *PostBuilder) Paragraph() *PostBuilder {
+	if len(b.current) > 0 {
+		b.paragraphs = append(b.paragraphs, b.current)
+		b.current = nil
+	}
+	return b
+}
+
+// Add 向当前段落追加元素
+func (b *PostBuilder) Add(elements ...PostElement) *PostBuilder {
+	b.current = append(b.current, elements...)
+	return b
+}
+
+// addBlock 追加一个独占段落的元素
+func (b *PostBuilder) addBlock(element PostElement) *PostBuilder {
+	b.Paragraph()
+	b.paragraphs = append(b.paragraphs, []PostElement{element})
+	return b
+}
+
+// Text 追加文本
+func (b *PostBuilder) Text(text string, styles ...TextStyle) *PostBuilder {
+	return b.Add(PostElement{Tag: PostTagText, Text: text, Style: styles})
+}
+
+// Link 追加超链接
+func (b *PostBuilder) Link(text, href string, styles ...TextStyle) *PostBuilder {
+	return b.Add(PostElement{Tag: PostTagLink, Text: text, Href: href, Style: styles})
+}
+
+// At @指定用户，userID为open_id或user_id
+func (b *PostBuilder) At(userID string, styles ...TextStyle) *PostBuilder {
+	return b.Add(PostElement{Tag: PostTagAt, UserId: userID, Style: styles})
+}
+
+// AtAll @所有人
+func (b *PostBuilder) AtAll() *PostBuilder {
+	return b.Add(PostElement{Tag: PostTagAt, UserId: MentionAllID})
+}
+
+// Image 追加图片
+func (b *PostBuilder) Image(imageKey string) *PostBuilder {
+	return b.Add(PostElement{Tag: PostTagImage, ImageKey: imageKey})
+}
+
+// Media 追加视频，imageKey为视频封面
+func (b *PostBuilder) Media(fileKey, imageKey string) *PostBuilder {
+	return b.Add(PostElement{Tag: PostTagMedia, FileKey: fileKey, ImageKey: imageKey})
+}
+
+// Emotion 追加表情
+func (b *PostBuilder) Emotion(emojiType string) *PostBuilder {
+	return b.Add(PostElement{Tag: PostTagEmotion, EmojiType: emojiType})
+}
+
+// CodeBlock 追加代码块
+func (b *PostBuilder) CodeBlock(language, code string) *PostBuilder {
+	return b.addBlock(PostElement{Tag: PostTagCodeBlock, Language: language, Text: code})
+}
+
+// HR 追加分割线
+func (b *PostBuilder) HR() *PostBuilder {
+	return b.addBlock(PostElement{Tag: PostTagHR})
+}
+
+// Markdown 追加Markdown内容
+func (b *PostBuilder) Markdown(text string) *PostBuilder {
+	return b.addBlock(PostElement{Tag: PostTagMarkdown, Text: text})
+}
+
+// Body 生成富文本消息体
+func (b *PostBuilder) Body() (*PostBody, error) {
+	b.Paragraph()
+	body := &PostBody{
+		Title:   b.title,
+		Content: b.paragraphs,
+	}
+	if err := body.Validate(); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// Build 生成中文富文本消息内容
+func (b *PostBuilder) Build() (*PostContent, error) {
+	body, err := b.Body()
+	if err != nil {
+		return nil, err
+	}
+	return &PostContent{ZhCn: body}, nil
+}
+
+// Validate 校验富文本消息内容
+func (p *PostContent) Validate() error {
+	if p == nil || (p.ZhCn == nil && p.EnUs == nil) {
+		return fmt.Errorf("invalid post: no content for any language")
+	}
+	if p.ZhCn != nil {
+		if err := p.ZhCn.Validate(); err != nil {
+			return fmt.Errorf("zh_cn: %w", err)
+		}
+	}
+	if p.EnUs != nil {
+		if err := p.EnUs.Validate(); err != nil {
+			return fmt.Errorf("en_us: %w", err)
+		}
+	}
+	return nil
+}
+
+// Validate 校验富文本消息体
+func (b *PostBody) Validate() error {
+	if b.Title == "" && len(b.Content) == 0 {
+		return fmt.Errorf("invalid post: title and content are both empty")
+	}
+	for i, paragraph := range b.Content {
+		for j, element := range paragraph {
+			if err := element.Validate(); err != nil {
+				return fmt.Errorf("paragraph %d element %d: %w", i, j, err)
+			}
+			if isPostBlockTag(element.Tag) && len(paragraph) > 1 {
+				return fmt.Errorf("paragraph %d: invalid post: %s must be the only element in its paragraph", i, element.Tag)
+			}
+		}
+	}
+	return nil
+}
+
+// Validate 校验富文本元素
+func (e PostElement) Validate() error {
+	var missing string
+	switch e.Tag {
+	case PostTagText:
+		if e.Text == "" {
+			missing = "text"
+		}
+	case PostTagLink:
+		if e.Text == "" {
+			missing = "text"
+		} else if e.Href == "" {
+			missing = "href"
+		}
+	case PostTagAt:
+		if e.UserId == "" {
+			missing = "user_id"
+		}
+	case PostTagImage:
+		if e.ImageKey == "" {
+			missing = "image_key"
+		}
+	case PostTagMedia:
+		if e.FileKey == "" {
+			missing = "file_key"
+		}
+	case PostTagEmotion:
+		if e.EmojiType == "" {
+			missing = "emoji_type"
+		}
+	case PostTagCodeBlock, PostTagMarkdown:
+		if e.Text == "" {
+			missing = "text"
+		}
+	case PostTagHR:
+	default:
+		return fmt.Errorf("invalid post: unknown tag %q", e.Tag)
+	}
+	if missing != "" {
+		return fmt.Errorf("invalid post: %s element requires %s", e.Tag, missing)
+	}
+
+	for _, style := range e.Style {
+		switch style {
+		case TextStyleBold, TextStyleItalic, TextStyleUnderline, TextStyleLineThrough:
+		default:
+			return fmt.Errorf("invalid post: unknown text style %q", style)
+		}
+	}
+	if len(e.Style) > 0 && e.Tag != PostTagText && e.Tag != PostTagLink && e.Tag != PostTagAt {
+		return fmt.Errorf("invalid post: %s element does not support style", e.Tag)
+	}
+	return nil
+}
+
+// isPostBlockTag 判断元素是否需要独占一个段落
+func isPostBlockTag(tag string) bool {
+	return tag == PostTagCodeBlock || tag == PostTagHR || tag == PostTagMarkdown
+}
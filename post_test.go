@@ -0,0 +1,76 @@
+package easylark
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPostBuilder(t *testing.T) {
+	post, err := NewPostBuilder("发布通知").
+		Text("服务 ").
+		Text("api", TextStyleBold).
+		Text(" 已发布，详情见").
+		Link("这里", "https://example.com").
+		At("ou_123").
+		CodeBlock("go", "fmt.Println(1)").
+		Text("请关注").
+		AtAll().
+		HR().
+		Image("img_key").
+		Build()
+	if err != nil {
+		t.Fatalf("build post failed: %v", err)
+	}
+
+	content := post.ZhCn.Content
+	if len(content) != 5 {
+		t.Fatalf("Expected 5 paragraphs, got %d", len(content))
+	}
+	if len(content[0]) != 5 {
+		t.Errorf("Expected 5 elements in first paragraph, got %d", len(content[0]))
+	}
+	if content[1][0].Tag != PostTagCodeBlock || content[1][0].Language != "go" {
+		t.Errorf("Expected code block in second paragraph, got %+v", content[1][0])
+	}
+	if content[2][1].UserId != MentionAllID {
+		t.Errorf("Expected at all, got %+v", content[2][1])
+	}
+	if content[3][0].Tag != PostTagHR {
+		t.Errorf("Expected hr in fourth paragraph, got %+v", content[3][0])
+	}
+
+	data, err := json.Marshal(content[0][1])
+	if err != nil {
+		t.Fatalf("marshal element failed: %v", err)
+	}
+	if string(data) != `{"tag":"text","text":"api","style":["bold"]}` {
+		t.Errorf("Unexpected element json: %s", data)
+	}
+}
+
+func TestPostValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		post *PostContent
+		want string
+	}{
+		{"empty", NewPostContent(), "no content"},
+		{"missing href", NewPostContent().WithZhCn("t", [][]PostElement{{{Tag: PostTagLink, Text: "x"}}}), "requires href"},
+		{"unknown tag", NewPostContent().WithEnUs("t", [][]PostElement{{{Tag: "button"}}}), "unknown tag"},
+		{"bad style", NewPostContent().WithZhCn("t", [][]PostElement{{{Tag: PostTagText, Text: "x", Style: []TextStyle{"blink"}}}}), "unknown text style"},
+		{"shared block", NewPostContent().WithZhCn("t", [][]PostElement{{{Tag: PostTagText, Text: "x"}, {Tag: PostTagHR}}}), "only element"},
+	}
+
+	for _, tt := range tests {
+		err := tt.post.Validate()
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected error containing '%s', got %v", tt.name, tt.want, err)
+		}
+	}
+
+	client := NewClient("test-app-id", "test-app-secret")
+	if err := client.Message.SendPost("chat123", NewPostContent()); err == nil {
+		t.Error("Expected SendPost to reject invalid post")
+	}
+}
@@ -0,0 +1,340 @@
+package easylark
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ChatSpec 群组的期望状态，用于EnsureChat
+type ChatSpec struct {
+	// Tag 群组的唯一标识，以"[easylark:tag]"的形式写入群描述，用于匹配已存在的群；
+	// 为空时按群名称匹配
+	Tag         string
+	Name        string
+	Description string
+	ChatType    string // 群类型，仅创建时生效
+
+	IDType   MemberIDType // OwnerID、Managers和Members的ID类型，默认open_id
+	OwnerID  string       // 群主，为空时不修改
+	Managers []string     // 群管理员，只添加缺少的管理员，不移除
+	Members  []string     // 群成员，为空时不同步；群主和管理员会自动加入
+
+	Tabs         []*ChatTab     // 标签页，按名称匹配，只添加缺少的标签页
+	Announcement string         // 群公告文本，仅创建群时写入
+	Welcome      MessageContent // 欢迎消息，仅创建群时发送
+}
+
+// 计划中的变更类型
+const (
+	ChatChangeCreate         = "create_chat"
+	ChatChangeUpdate         = "update_chat"
+	ChatChangeTransferOwner  = "transfer_owner"
+	ChatChangeAddMembers     = "add_members"
+	ChatChangeRemoveMembers  = "remove_members"
+	ChatChangeAddManagers    = "add_managers"
+	ChatChangeCreateTabs     = "create_tabs"
+	ChatChangeAnnouncement   = "set_announcement"
+	ChatChangeWelcomeMessage = "send_welcome"
+)
+
+// ChatChange 计划中的一项变更
+type ChatChange struct {
+	Action string
+	Detail string
+
+	apply func(chatID *string) error
+}
+
+// ChatPlan EnsureChat的执行计划
+type ChatPlan struct {
+	ChatID  string // 已存在或新创建的群ID，dry-run且需要创建时为空
+	Created bool   // 是否需要创建群
+	DryRun  bool
+	Changes []*ChatChange
+}
+
+// String 以可读形式输出计划
+func (p *ChatPlan) String() string {
+	if len(p.Changes) == 0 {
+		return fmt.Sprintf("chat %s is up to date", p.ChatID)
+	}
+	var b strings.Builder
+	for _, change := range p.Changes {
+		fmt.Fprintf(&b, "%s: %s\n", change.Action, change.Detail)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// add 添加变更
+func (p *ChatPlan) add(action, detail string, apply func(chatID *string) error) {
+	p.Changes = append(p.Changes, &ChatChange{Action: action, Detail: detail, apply: apply})
+}
+
+// EnsureOption EnsureChat的可选配置
+type EnsureOption func(*ensureOptions)
+
+// ensureOptions EnsureChat的配置
+type ensureOptions struct {
+	dryRun bool
+}
+
+// WithDryRun 只计算变更计划，不实际修改
+func WithDryRun() EnsureOption {
+	return func(o *ensureOptions) {
+		o.dryRun = true
+	}
+}
+
+// tagMarker 写入群描述的标识
+func (spec *ChatSpec) tagMarker() string {
+	if spec.Tag == "" {
+		return ""
+	}
+	return "[easylark:" + spec.Tag + "]"
+}
+
+// description 生成带标识的群描述
+func (spec *ChatSpec) description() string {
+	marker := spec.tagMarker()
+	if marker == "" {
+		return spec.Description
+	}
+	if spec.Description == "" {
+		return marker
+	}
+	return spec.Description + "\n" + marker
+}
+
+// matches 判断已存在的群是否为spec描述的群
+func (spec *ChatSpec) matches(chat *Chat) bool {
+	if marker := spec.tagMarker(); marker != "" {
+		return strings.Contains(chat.Description, marker)
+	}
+	return chat.Name == spec.Name
+}
+
+// desiredMembers 期望的群成员，包含群主和管理员
+func (spec *ChatSpec) desiredMembers() []string {
+	members := make([]string, 0, len(spec.Members)+len(spec.Managers)+1)
+	if spec.OwnerID != "" {
+		members = append(members, spec.OwnerID)
+	}
+	members = append(members, spec.Managers...)
+	return append(members, spec.Members...)
+}
+
+// EnsureChat 按spec创建群或将已存在的群调整为spec描述的状态，返回执行的变更计划
+//
+// 在机器人所在的群中按Tag或名称查找，找不到时创建群、指定管理员、添加标签页、
+// 写入公告并发送欢迎消息；找到时更新名称和描述、同步成员并转让群主、添加缺少的管理员和标签页。
+// 重复调用是幂等的。使用WithDryRun时只返回计划，不做任何修改。
+func (s *ChatService) EnsureChat(spec *ChatSpec, opts ...EnsureOption) (*ChatPlan, error) {
+	if spec == nil || spec.Name == "" {
+		return nil, fmt.Errorf("ensure chat failed: name is required")
+	}
+	options := &ensureOptions{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(options)
+		}
+	}
+	// 复制spec，填充默认值时不修改调用方的spec
+	copied := *spec
+	spec = &copied
+	if spec.IDType == "" {
+		spec.IDType = MemberIDTypeOpenID
+	}
+
+	existing, err := s.findChat(spec)
+	if err != nil {
+		return nil, fmt.Errorf("ensure chat failed: %w", err)
+	}
+
+	plan := &ChatPlan{DryRun: options.dryRun}
+	if existing == nil {
+		s.planCreate(plan, spec)
+	} else {
+		plan.ChatID = existing.ChatID
+		if err := s.planReconcile(plan, spec, existing); err != nil {
+			return nil, fmt.Errorf("ensure chat failed: %w", err)
+		}
+	}
+
+	if options.dryRun {
+		return plan, nil
+	}
+	for _, change := range plan.Changes {
+		if err := change.apply(&plan.ChatID); err != nil {
+			return plan, fmt.Errorf("ensure chat failed: %s: %w", change.Action, err)
+		}
+	}
+	return plan, nil
+}
+
+// findChat 在机器人所在的群中查找spec描述的群
+func (s *ChatService) findChat(spec *ChatSpec) (*Chat, error) {
+	req := &ListChatsRequest{PageSize: 100, UserIDType: string(spec.IDType)}
+	for {
+		page, err := s.List(req)
+		if err != nil {
+			return nil, err
+		}
+		for _, chat := range page.Items {
+			if spec.matches(chat) {
+				return chat, nil
+			}
+		}
+		if !page.HasMore || page.PageToken == "" {
+			return nil, nil
+		}
+		req.PageToken = page.PageToken
+	}
+}
+
+// planCreate 计划创建群及初始化配置
+func (s *ChatService) planCreate(plan *ChatPlan, spec *ChatSpec) {
+	plan.Created = true
+	idempotencyKey := "ensure-chat/" + spec.Name
+	if spec.Tag != "" {
+		idempotencyKey = "ensure-chat/" + spec.Tag
+	}
+
+	members := spec.desiredMembers()
+	plan.add(ChatChangeCreate, fmt.Sprintf("create chat %q with %d members", spec.Name, len(members)), func(chatID *string) error {
+		chat, err := s.Create(&CreateGroupRequest{
+			Name:        spec.Name,
+			Description: spec.description(),
+			OwnerID:     spec.OwnerID,
+			UserIDs:     members,
+			ChatType:    spec.ChatType,
+			UserIDType:  string(spec.IDType),
+			UUID:        idempotencyKey,
+		})
+		if err != nil {
+			return err
+		}
+		*chatID = chat.ChatID
+		return nil
+	})
+
+	s.planManagers(plan, spec, spec.Managers)
+	if len(spec.Tabs) > 0 {
+		s.planTabs(plan, spec.Tabs)
+	}
+	if spec.Announcement != "" {
+		plan.add(ChatChangeAnnouncement, "write chat announcement", func(chatID *string) error {
+			return s.AppendAnnouncementText(*chatID, spec.Announcement)
+		})
+	}
+	if spec.Welcome != nil {
+		plan.add(ChatChangeWelcomeMessage, fmt.Sprintf("send %s welcome message", spec.Welcome.Type()), func(chatID *string) error {
+			return s.client.Message.SendMessage(*chatID, spec.Welcome, WithUUID(idempotencyKey+"/welcome"))
+		})
+	}
+}
+
+// planReconcile 计划将已存在的群调整为spec描述的状态
+func (s *ChatService) planReconcile(plan *ChatPlan, spec *ChatSpec, chat *Chat) error {
+	update := &UpdateChatRequest{}
+	var fields []string
+	if chat.Name != spec.Name {
+		update.Name = spec.Name
+		fields = append(fields, fmt.Sprintf("name %q -> %q", chat.Name, spec.Name))
+	}
+	if description := spec.description(); chat.Description != description {
+		update.Description = description
+		fields = append(fields, "description")
+	}
+	if len(fields) > 0 {
+		plan.add(ChatChangeUpdate, "update "+strings.Join(fields, ", "), func(chatID *string) error {
+			return s.Update(*chatID, update)
+		})
+	}
+
+	// 先加入新成员，再转让群主，最后移除成员，新群主不在群中时也能转让
+	var toRemove []string
+	if len(spec.Members) > 0 {
+		members, err := s.ListAllMembers(chat.ChatID, spec.IDType)
+		if err != nil {
+			return err
+		}
+		var keep []string
+		if spec.OwnerID == "" {
+			keep = append(keep, chat.OwnerID)
+		}
+		var toAdd []string
+		toAdd, toRemove = diffMembers(members, spec.desiredMembers(), keep...)
+		if len(toAdd) > 0 {
+			plan.add(ChatChangeAddMembers, "add "+strings.Join(toAdd, ", "), func(chatID *string) error {
+				_, err := s.AddMembers(*chatID, toAdd, spec.IDType)
+				return err
+			})
+		}
+	}
+
+	if spec.OwnerID != "" && chat.OwnerID != spec.OwnerID {
+		plan.add(ChatChangeTransferOwner, fmt.Sprintf("transfer owner %s -> %s", chat.OwnerID, spec.OwnerID), func(chatID *string) error {
+			return s.TransferOwner(*chatID, spec.OwnerID, spec.IDType)
+		})
+	}
+
+	if len(toRemove) > 0 {
+		plan.add(ChatChangeRemoveMembers, "remove "+strings.Join(toRemove, ", "), func(chatID *string) error {
+			_, err := s.RemoveMembers(*chatID, toRemove, spec.IDType)
+			return err
+		})
+	}
+
+	if len(spec.Managers) > 0 {
+		// 群列表中不含管理员，需要单独获取群信息
+		detail, err := s.get(chat.ChatID, spec.IDType)
+		if err != nil {
+			return err
+		}
+		s.planManagers(plan, spec, excludeIDs(spec.Managers, detail.UserManagerIDs))
+	}
+
+	if len(spec.Tabs) > 0 {
+		tabs, err := s.ListTabs(chat.ChatID)
+		if err != nil {
+			return err
+		}
+		existing := make(map[string]bool, len(tabs))
+		for _, tab := range tabs {
+			existing[tab.TabName] = true
+		}
+		var missing []*ChatTab
+		for _, tab := range spec.Tabs {
+			if !existing[tab.TabName] {
+				missing = append(missing, tab)
+			}
+		}
+		if len(missing) > 0 {
+			s.planTabs(plan, missing)
+		}
+	}
+	return nil
+}
+
+// planManagers 计划指定缺少的群管理员
+func (s *ChatService) planManagers(plan *ChatPlan, spec *ChatSpec, missing []string) {
+	if len(missing) == 0 {
+		return
+	}
+	plan.add(ChatChangeAddManagers, "add managers "+strings.Join(missing, ", "), func(chatID *string) error {
+		_, err := s.AddManagers(*chatID, missing, spec.IDType)
+		return err
+	})
+}
+
+// planTabs 计划添加标签页
+func (s *ChatService) planTabs(plan *ChatPlan, tabs []*ChatTab) {
+	names := make([]string, 0, len(tabs))
+	for _, tab := range tabs {
+		names = append(names, tab.TabName)
+	}
+	plan.add(ChatChangeCreateTabs, "create tabs "+strings.Join(names, ", "), func(chatID *string) error {
+		_, err := s.CreateTabs(*chatID, tabs)
+		return err
+	})
+}
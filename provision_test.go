@@ -0,0 +1,220 @@
+package easylark
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeChatServer 模拟群组相关接口，记录所有修改请求
+type fakeChatServer struct {
+	mu       sync.Mutex
+	chats    []map[string]interface{}
+	members  []string
+	managers []string
+	tabs     []string
+	mutation []string
+}
+
+func (f *fakeChatServer) handle(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		path := strings.TrimPrefix(r.URL.Path, "/open-apis/im/v1")
+		var reqBody map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		if r.Method != "GET" {
+			f.mutation = append(f.mutation, r.Method+" "+path)
+		}
+
+		switch {
+		case r.Method == "GET" && path == "/chats":
+			writeTestResponse(w, map[string]interface{}{"items": f.chats})
+		case r.Method == "GET" && strings.HasSuffix(path, "/members"):
+			items := make([]map[string]interface{}, 0, len(f.members))
+			for _, id := range f.members {
+				items = append(items, map[string]interface{}{"member_id": id})
+			}
+			writeTestResponse(w, map[string]interface{}{"items": items})
+		case r.Method == "GET" && strings.HasSuffix(path, "/list_tabs"):
+			items := make([]map[string]interface{}, 0, len(f.tabs))
+			for _, name := range f.tabs {
+				items = append(items, map[string]interface{}{"tab_name": name})
+			}
+			writeTestResponse(w, map[string]interface{}{"chat_tabs": items})
+		case r.Method == "GET" && strings.Count(path, "/") == 2:
+			for _, chat := range f.chats {
+				if "/chats/"+chat["chat_id"].(string) == path {
+					detail := map[string]interface{}{"user_manager_id_list": f.managers}
+					for k, v := range chat {
+						detail[k] = v
+					}
+					writeTestResponse(w, detail)
+					return
+				}
+			}
+			t.Errorf("Unexpected chat %s", path)
+		case r.Method == "GET" && strings.HasSuffix(path, "/announcement"):
+			writeTestResponse(w, map[string]interface{}{"revision": "1"})
+		case r.Method == "POST" && path == "/chats":
+			if r.URL.Query().Get("uuid") == "" {
+				t.Error("Expected create chat to be idempotent")
+			}
+			writeTestResponse(w, map[string]interface{}{"chat_id": "oc_new"})
+		case r.Method == "POST" && path == "/messages":
+			if reqBody["uuid"] == nil {
+				t.Error("Expected welcome message to carry uuid")
+			}
+			writeTestResponse(w, map[string]interface{}{"message_id": "om_1"})
+		default:
+			writeTestResponse(w, map[string]interface{}{})
+		}
+	}
+}
+
+func testChatSpec() *ChatSpec {
+	return &ChatSpec{
+		Tag:          "proj-42",
+		Name:         "项目42",
+		Description:  "项目沟通群",
+		OwnerID:      "ou_owner",
+		Managers:     []string{"ou_pm"},
+		Members:      []string{"ou_dev1", "ou_dev2"},
+		Tabs:         []*ChatTab{NewURLTab("看板", "https://board"), NewURLTab("文档", "https://doc")},
+		Announcement: "欢迎加入",
+		Welcome:      &TextContent{Text: "hello"},
+	}
+}
+
+func TestEnsureChatCreate(t *testing.T) {
+	server := &fakeChatServer{}
+	client := newTestClient(t, server.handle(t))
+
+	plan, err := client.Chat.EnsureChat(testChatSpec(), WithDryRun())
+	if err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if !plan.Created || plan.ChatID != "" || len(server.mutation) != 0 {
+		t.Errorf("Expected dry run to plan creation without changes, got %+v, %v", plan, server.mutation)
+	}
+	actions := make([]string, 0, len(plan.Changes))
+	for _, change := range plan.Changes {
+		actions = append(actions, change.Action)
+	}
+	want := []string{ChatChangeCreate, ChatChangeAddManagers, ChatChangeCreateTabs, ChatChangeAnnouncement, ChatChangeWelcomeMessage}
+	if strings.Join(actions, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected actions %v, got %v", want, actions)
+	}
+
+	plan, err = client.Chat.EnsureChat(testChatSpec())
+	if err != nil {
+		t.Fatalf("ensure chat failed: %v", err)
+	}
+	if plan.ChatID != "oc_new" {
+		t.Errorf("Expected created chat id, got %q", plan.ChatID)
+	}
+	wantMutations := []string{
+		"POST /chats",
+		"POST /chats/oc_new/managers/add_managers",
+		"POST /chats/oc_new/chat_tabs",
+		"PATCH /chats/oc_new/announcement",
+		"POST /messages",
+	}
+	if strings.Join(server.mutation, "|") != strings.Join(wantMutations, "|") {
+		t.Errorf("Expected mutations %v, got %v", wantMutations, server.mutation)
+	}
+}
+
+func TestEnsureChatReconcile(t *testing.T) {
+	server := &fakeChatServer{
+		chats: []map[string]interface{}{
+			{"chat_id": "oc_other", "name": "项目42", "description": "同名但不是同一个群"},
+			{"chat_id": "oc_42", "name": "旧名称", "description": "项目沟通群\n[easylark:proj-42]", "owner_id": "ou_owner"},
+		},
+		members:  []string{"ou_owner", "ou_pm", "ou_dev1", "ou_left"},
+		managers: []string{"ou_pm"},
+		tabs:     []string{"看板"},
+	}
+	client := newTestClient(t, server.handle(t))
+
+	spec := testChatSpec()
+	plan, err := client.Chat.EnsureChat(spec, WithDryRun())
+	if err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if plan.Created || plan.ChatID != "oc_42" {
+		t.Errorf("Expected existing chat oc_42 to match by tag, got %+v", plan)
+	}
+	if spec.IDType != "" {
+		t.Error("Expected spec not to be modified")
+	}
+	summary := plan.String()
+	for _, want := range []string{
+		`update_chat: update name "旧名称" -> "项目42"`,
+		"add_members: add ou_dev2",
+		"remove_members: remove ou_left",
+		"create_tabs: create tabs 文档",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Expected plan to contain %q, got:\n%s", want, summary)
+		}
+	}
+	if strings.Contains(summary, ChatChangeTransferOwner) || strings.Contains(summary, ChatChangeWelcomeMessage) ||
+		strings.Contains(summary, ChatChangeAddManagers) {
+		t.Errorf("Unexpected changes in plan:\n%s", summary)
+	}
+	if len(server.mutation) != 0 {
+		t.Errorf("Expected no mutations in dry run, got %v", server.mutation)
+	}
+
+	if _, err := client.Chat.EnsureChat(spec); err != nil {
+		t.Fatalf("ensure chat failed: %v", err)
+	}
+	wantMutations := []string{
+		"PUT /chats/oc_42",
+		"POST /chats/oc_42/members",
+		"DELETE /chats/oc_42/members",
+		"POST /chats/oc_42/chat_tabs",
+	}
+	if strings.Join(server.mutation, "|") != strings.Join(wantMutations, "|") {
+		t.Errorf("Expected mutations %v, got %v", wantMutations, server.mutation)
+	}
+}
+
+func TestEnsureChatReconcileOwnerAndManagers(t *testing.T) {
+	server := &fakeChatServer{
+		chats: []map[string]interface{}{
+			{"chat_id": "oc_42", "name": "项目42", "description": "项目沟通群\n[easylark:proj-42]", "owner_id": "ou_owner"},
+		},
+		members: []string{"ou_owner", "ou_pm", "ou_dev1", "ou_dev2"},
+		tabs:    []string{"看板", "文档"},
+	}
+	client := newTestClient(t, server.handle(t))
+
+	// 新群主不在群中时先加入再转让，之后才移除旧群主
+	spec := testChatSpec()
+	spec.OwnerID = "ou_new"
+	plan, err := client.Chat.EnsureChat(spec)
+	if err != nil {
+		t.Fatalf("ensure chat failed: %v", err)
+	}
+	want := "add_members: add ou_new\ntransfer_owner: transfer owner ou_owner -> ou_new\n" +
+		"remove_members: remove ou_owner\nadd_managers: add managers ou_pm"
+	if plan.String() != want {
+		t.Errorf("Expected plan:\n%s\ngot:\n%s", want, plan.String())
+	}
+
+	// 未指定群主时保留当前群主，管理员已存在时不再添加
+	server.managers = []string{"ou_pm"}
+	spec.OwnerID = ""
+	plan, err = client.Chat.EnsureChat(spec, WithDryRun())
+	if err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if len(plan.Changes) != 0 {
+		t.Errorf("Expected chat to be up to date, got:\n%s", plan)
+	}
+}
@@ -0,0 +1,549 @@
+package easylark
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrScheduleNotFound 定时消息不存在或已发送
+var ErrScheduleNotFound = errors.New("scheduled message not found")
+
+// ErrScheduleGaveUp 定时消息发送失败的次数达到上限，已从store中删除
+var ErrScheduleGaveUp = errors.New("scheduled message gave up after max attempts")
+
+// 定时消息重试的默认配置
+const (
+	defaultScheduleMaxAttempts  = 5
+	defaultScheduleRetryBackoff = 30 * time.Second
+	maxScheduleRetryBackoff     = time.Hour
+)
+
+// Receiver 消息接收者
+type Receiver struct {
+	ID     string        `json:"id"`
+	IDType ReceiveIDType `json:"id_type,omitempty"`
+}
+
+// ChatReceiver 以群ID作为接收者
+func ChatReceiver(chatID string) Receiver {
+	return Receiver{ID: chatID, IDType: ReceiveIDTypeChatID}
+}
+
+// UserReceiver 以open_id作为接收者
+func UserReceiver(openID string) Receiver {
+	return Receiver{ID: openID, IDType: ReceiveIDTypeOpenID}
+}
+
+// ScheduledMessage 待发送的定时消息
+type ScheduledMessage struct {
+	ID        string                 `json:"id"`
+	At        time.Time              `json:"at"`
+	Receiver  Receiver               `json:"receiver"`
+	MsgType   MessageType            `json:"msg_type"`
+	Content   map[string]interface{} `json:"content"`
+	CreatedAt time.Time              `json:"created_at"`
+	// Attempts 已失败的发送次数，发送失败后At会推迟到下一次重试的时间
+	Attempts  int    `json:"attempts,omitempty"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// scheduledContent 定时消息持久化后的消息内容
+type scheduledContent struct {
+	msgType MessageType
+	content map[string]interface{}
+}
+
+// Type 实现MessageContent接口
+func (c *scheduledContent) Type() MessageType {
+	return c.msgType
+}
+
+// Content 实现MessageContent接口
+func (c *scheduledContent) Content() map[string]interface{} {
+	return c.content
+}
+
+// ScheduleStore 定时消息的持久化存储
+type ScheduleStore interface {
+	// Save 保存定时消息，ID相同时覆盖
+	Save(msg *ScheduledMessage) error
+	// Get 获取定时消息，不存在时返回ErrScheduleNotFound
+	Get(id string) (*ScheduledMessage, error)
+	// Delete 删除定时消息，不存在时返回ErrScheduleNotFound
+	Delete(id string) error
+	// List 获取所有待发送的定时消息
+	List() ([]*ScheduledMessage, error)
+}
+
+// MemoryScheduleStore 内存存储，进程重启后定时消息会丢失
+type MemoryScheduleStore struct {
+	mu       sync.Mutex
+	messages map[string]*ScheduledMessage
+}
+
+// NewMemoryScheduleStore 创建内存存储
+func NewMemoryScheduleStore() *MemoryScheduleStore {
+	return &MemoryScheduleStore{
+		messages: make(map[string]*ScheduledMessage),
+	}
+}
+
+// Save 实现ScheduleStore接口
+func (m *MemoryScheduleStore) Save(msg *ScheduledMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages[msg.ID] = msg
+	return nil
+}
+
+// Get 实现ScheduleStore接口
+func (m *MemoryScheduleStore) Get(id string) (*ScheduledMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	msg, ok := m.messages[id]
+	if !ok {
+		return nil, ErrScheduleNotFound
+	}
+	return msg, nil
+}
+
+// Delete 实现ScheduleStore接口
+func (m *MemoryScheduleStore) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.messages[id]; !ok {
+		return ErrScheduleNotFound
+	}
+	delete(m.messages, id)
+	return nil
+}
+
+// List 实现ScheduleStore接口
+func (m *MemoryScheduleStore) List() ([]*ScheduledMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	messages := make([]*ScheduledMessage, 0, len(m.messages))
+	for _, msg := range m.messages {
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
+
+// FileScheduleStore 文件存储，所有定时消息以JSON保存在一个文件中，每次修改整体写入
+type FileScheduleStore struct {
+	path     string
+	mu       sync.Mutex
+	messages map[string]*ScheduledMessage
+}
+
+// NewFileScheduleStore 创建文件存储，文件已存在时加载其中的定时消息
+func NewFileScheduleStore(path string) (*FileScheduleStore, error) {
+	store := &FileScheduleStore{
+		path:     path,
+		messages: make(map[string]*ScheduledMessage),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read schedule file failed: %w", err)
+	}
+	if len(data) == 0 {
+		return store, nil
+	}
+
+	var messages []*ScheduledMessage
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return nil, fmt.Errorf("parse schedule file failed: %w", err)
+	}
+	for _, msg := range messages {
+		store.messages[msg.ID] = msg
+	}
+	return store, nil
+}
+
+// Save 实现ScheduleStore接口
+func (f *FileScheduleStore) Save(msg *ScheduledMessage) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	previous, existed := f.messages[msg.ID]
+	f.messages[msg.ID] = msg
+	if err := f.flush(); err != nil {
+		if existed {
+			f.messages[msg.ID] = previous
+		} else {
+			delete(f.messages, msg.ID)
+		}
+		return err
+	}
+	return nil
+}
+
+// Get 实现ScheduleStore接口
+func (f *FileScheduleStore) Get(id string) (*ScheduledMessage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	msg, ok := f.messages[id]
+	if !ok {
+		return nil, ErrScheduleNotFound
+	}
+	return msg, nil
+}
+
+// Delete 实现ScheduleStore接口
+func (f *FileScheduleStore) Delete(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	msg, ok := f.messages[id]
+	if !ok {
+		return ErrScheduleNotFound
+	}
+	delete(f.messages, id)
+	if err := f.flush(); err != nil {
+		f.messages[id] = msg
+		return err
+	}
+	return nil
+}
+
+// List 实现ScheduleStore接口
+func (f *FileScheduleStore) List() ([]*ScheduledMessage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	messages := make([]*ScheduledMessage, 0, len(f.messages))
+	for _, msg := range f.messages {
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
+
+// flush 先写临时文件再重命名，避免写入中断导致文件损坏
+func (f *FileScheduleStore) flush() error {
+	messages := make([]*ScheduledMessage, 0, len(f.messages))
+	for _, msg := range f.messages {
+		messages = append(messages, msg)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].At.Before(messages[j].At)
+	})
+
+	data, err := json.MarshalIndent(messages, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal schedule failed: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("write schedule file failed: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write schedule file failed: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write schedule file failed: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write schedule file failed: %w", err)
+	}
+	return nil
+}
+
+// Scheduler 定时消息调度器
+//
+// 定时消息保存在ScheduleStore中，Start后到期的消息通过MessageService.SendMessage发送，
+// 消息发送成功后才从store中删除，发送时以定时消息ID派生uuid，
+// 发送后进程中断导致重启后再次发送也不会产生重复消息。
+// 进程停止期间到期的消息会在下次Start后立即发送。
+// 发送失败的消息保留在store中，按RetryBackoff指数退避后重试，失败MaxAttempts次后删除。
+type Scheduler struct {
+	// MaxAttempts 每条消息最多发送的次数，默认5次
+	MaxAttempts int
+	// RetryBackoff 第一次重试前的等待时间，之后每次翻倍，最长1小时，默认30秒
+	RetryBackoff time.Duration
+
+	// OnError 每次发送失败时回调，放弃重试时err包含ErrScheduleGaveUp；
+	// 读取store失败时msg为nil
+	OnError func(msg *ScheduledMessage, err error)
+	// OnSent 发送成功时回调
+	OnSent func(msg *ScheduledMessage, messageID string)
+
+	messages *MessageService
+	store    ScheduleStore
+	now      func() time.Time
+
+	sendMu  sync.Mutex
+	mu      sync.Mutex
+	wake    chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	running bool
+}
+
+// NewScheduler 创建定时消息调度器，store为空时使用内存存储
+func NewScheduler(messages *MessageService, store ScheduleStore) *Scheduler {
+	if store == nil {
+		store = NewMemoryScheduleStore()
+	}
+	return &Scheduler{
+		messages: messages,
+		store:    store,
+		now:      time.Now,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Schedule 在指定时间向receiver发送消息，返回定时消息ID
+func (s *Scheduler) Schedule(at time.Time, receiver Receiver, content MessageContent) (string, error) {
+	if receiver.ID == "" {
+		return "", fmt.Errorf("schedule message failed: receiver id is empty")
+	}
+	if err := validateMessageContent(content); err != nil {
+		return "", err
+	}
+	if err := checkMessageSize(content); err != nil {
+		return "", err
+	}
+
+	id, err := newScheduleID()
+	if err != nil {
+		return "", err
+	}
+	msg := &ScheduledMessage{
+		ID:        id,
+		At:        at,
+		Receiver:  receiver,
+		MsgType:   content.Type(),
+		Content:   content.Content(),
+		CreatedAt: s.now(),
+	}
+	if err := s.store.Save(msg); err != nil {
+		return "", fmt.Errorf("schedule message failed: %w", err)
+	}
+	s.notify()
+	return id, nil
+}
+
+// ScheduleAfter 在d时间后向receiver发送消息，返回定时消息ID
+func (s *Scheduler) ScheduleAfter(d time.Duration, receiver Receiver, content MessageContent) (string, error) {
+	return s.Schedule(s.now().Add(d), receiver, content)
+}
+
+// Cancel 取消尚未发送的定时消息，消息已发送或不存在时返回ErrScheduleNotFound
+func (s *Scheduler) Cancel(id string) error {
+	s.sendMu.Lock()
+	err := s.store.Delete(id)
+	s.sendMu.Unlock()
+	if err != nil {
+		return err
+	}
+	s.notify()
+	return nil
+}
+
+// Pending 获取尚未发送的定时消息，按发送时间排序
+func (s *Scheduler) Pending() ([]*ScheduledMessage, error) {
+	messages, err := s.store.List()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].At.Before(messages[j].At)
+	})
+	return messages, nil
+}
+
+// Start 启动调度，重复调用无效
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return
+	}
+	s.running = true
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go s.run(s.stop, s.done)
+}
+
+// Stop 停止调度并等待正在发送的消息完成，未发送的消息保留在store中
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	close(s.stop)
+	done := s.done
+	s.mu.Unlock()
+	<-done
+}
+
+// notify 唤醒调度循环重新计算下一次发送时间
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run 调度循环
+func (s *Scheduler) run(stop, done chan struct{}) {
+	defer close(done)
+	for {
+		wait := s.dispatchDue(stop)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-s.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// dispatchDue 发送所有到期的消息，返回距离下一条消息的等待时间
+func (s *Scheduler) dispatchDue(stop chan struct{}) time.Duration {
+	const idle = time.Minute
+
+	messages, err := s.Pending()
+	if err != nil {
+		if s.OnError != nil {
+			s.OnError(nil, err)
+		}
+		return idle
+	}
+
+	wait := idle
+	for _, msg := range messages {
+		select {
+		case <-stop:
+			return idle
+		default:
+		}
+
+		now := s.now()
+		if msg.At.After(now) {
+			if d := msg.At.Sub(now); d < wait {
+				wait = d
+			}
+			return wait
+		}
+		// 发送失败的消息推迟重试，可能早于后续消息到期
+		if retryAt := s.dispatch(msg); !retryAt.IsZero() && retryAt.Sub(now) < wait {
+			wait = retryAt.Sub(now)
+		}
+	}
+	return wait
+}
+
+// dispatch 发送一条定时消息，成功后从store中删除，失败时推迟重试并返回重试时间
+func (s *Scheduler) dispatch(msg *ScheduledMessage) time.Time {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+
+	// 发送前已被取消
+	if _, err := s.store.Get(msg.ID); err != nil {
+		if !errors.Is(err, ErrScheduleNotFound) && s.OnError != nil {
+			s.OnError(msg, err)
+		}
+		return time.Time{}
+	}
+
+	// uuid由定时消息ID派生，重试时不会产生重复消息
+	options := newSendOptions([]SendOption{WithUUID(msg.ID), WithReceiveIDType(msg.Receiver.IDType)})
+	messageID, err := s.messages.sendMessage(msg.Receiver.ID, &scheduledContent{msgType: msg.MsgType, content: msg.Content}, options)
+	if err != nil {
+		return s.retry(msg, err)
+	}
+	if delErr := s.store.Delete(msg.ID); delErr != nil && !errors.Is(delErr, ErrScheduleNotFound) && s.OnError != nil {
+		s.OnError(msg, delErr)
+	}
+	if s.OnSent != nil {
+		s.OnSent(msg, messageID)
+	}
+	return time.Time{}
+}
+
+// retry 记录发送失败并推迟到下一次重试的时间，失败次数达到上限时删除消息并返回零值
+func (s *Scheduler) retry(msg *ScheduledMessage, sendErr error) time.Time {
+	failed := *msg
+	failed.Attempts++
+	failed.LastError = sendErr.Error()
+	err := fmt.Errorf("send scheduled message %s failed (attempt %d): %w", msg.ID, failed.Attempts, sendErr)
+
+	maxAttempts := s.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultScheduleMaxAttempts
+	}
+	if failed.Attempts >= maxAttempts {
+		if delErr := s.store.Delete(msg.ID); delErr != nil && !errors.Is(delErr, ErrScheduleNotFound) && s.OnError != nil {
+			s.OnError(msg, delErr)
+		}
+		if s.OnError != nil {
+			s.OnError(&failed, fmt.Errorf("%w: %v", ErrScheduleGaveUp, err))
+		}
+		return time.Time{}
+	}
+
+	failed.At = s.now().Add(s.retryBackoff(failed.Attempts))
+	if saveErr := s.store.Save(&failed); saveErr != nil && s.OnError != nil {
+		s.OnError(msg, fmt.Errorf("reschedule message %s failed: %w", msg.ID, saveErr))
+	}
+	if s.OnError != nil {
+		s.OnError(&failed, err)
+	}
+	return failed.At
+}
+
+// retryBackoff 第attempts次失败后的等待时间
+func (s *Scheduler) retryBackoff(attempts int) time.Duration {
+	backoff := s.RetryBackoff
+	if backoff <= 0 {
+		backoff = defaultScheduleRetryBackoff
+	}
+	for i := 1; i < attempts && backoff < maxScheduleRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxScheduleRetryBackoff {
+		backoff = maxScheduleRetryBackoff
+	}
+	return backoff
+}
+
+// newScheduleID 生成定时消息ID
+func newScheduleID() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate schedule id failed: %w", err)
+	}
+	return fmt.Sprintf("sch_%x", buf), nil
+}
+
+// NextLocalTime 获取loc时区中now之后最近的hour:minute，用于按接收者时区定时发送
+func NextLocalTime(now time.Time, hour, minute int, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.Local
+	}
+	local := now.In(loc)
+	next := time.Date(local.Year(), local.Month(), local.Day(), hour, minute, 0, 0, loc)
+	if !next.After(local) {
+		next = time.Date(local.Year(), local.Month(), local.Day()+1, hour, minute, 0, 0, loc)
+	}
+	return next
+}
@@ -0,0 +1,248 @@
+package easylark
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordedSend 测试服务端收到的发送请求
+type recordedSend struct {
+	IDType    string
+	ReceiveID string
+	UUID      string
+	Text      string
+}
+
+func newSchedulerTestClient(t *testing.T) (*Client, func() []recordedSend) {
+	var mu sync.Mutex
+	var sends []recordedSend
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var reqBody struct {
+			ReceiveID string            `json:"receive_id"`
+			UUID      string            `json:"uuid"`
+			Content   map[string]string `json:"content"`
+		}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		mu.Lock()
+		sends = append(sends, recordedSend{
+			IDType:    r.URL.Query().Get("receive_id_type"),
+			ReceiveID: reqBody.ReceiveID,
+			UUID:      reqBody.UUID,
+			Text:      reqBody.Content["text"],
+		})
+		mu.Unlock()
+		writeTestResponse(w, map[string]interface{}{"message_id": "om_1"})
+	})
+	return client, func() []recordedSend {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]recordedSend(nil), sends...)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("Timed out waiting for condition")
+}
+
+func TestSchedulerDispatch(t *testing.T) {
+	client, sends := newSchedulerTestClient(t)
+	scheduler := NewScheduler(client.Message, nil)
+
+	sent := make(chan string, 2)
+	scheduler.OnSent = func(msg *ScheduledMessage, messageID string) {
+		sent <- msg.ID
+	}
+	scheduler.OnError = func(msg *ScheduledMessage, err error) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	later, err := scheduler.ScheduleAfter(50*time.Millisecond, UserReceiver("ou_1"), &TextContent{Text: "later"})
+	if err != nil {
+		t.Fatalf("schedule failed: %v", err)
+	}
+	cancelled, err := scheduler.ScheduleAfter(30*time.Millisecond, ChatReceiver("oc_1"), &TextContent{Text: "cancelled"})
+	if err != nil {
+		t.Fatalf("schedule failed: %v", err)
+	}
+	if err := scheduler.Cancel(cancelled); err != nil {
+		t.Fatalf("cancel failed: %v", err)
+	}
+	if err := scheduler.Cancel(cancelled); !errors.Is(err, ErrScheduleNotFound) {
+		t.Errorf("Expected ErrScheduleNotFound, got %v", err)
+	}
+
+	select {
+	case id := <-sent:
+		if id != later {
+			t.Errorf("Expected %s to be sent, got %s", later, id)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for scheduled message")
+	}
+
+	got := sends()
+	if len(got) != 1 {
+		t.Fatalf("Expected 1 send, got %v", got)
+	}
+	if got[0].IDType != "open_id" || got[0].ReceiveID != "ou_1" || got[0].Text != "later" {
+		t.Errorf("Unexpected send: %+v", got[0])
+	}
+	if got[0].UUID != deriveUUID(later) {
+		t.Errorf("Expected uuid derived from schedule id, got %q", got[0].UUID)
+	}
+	if pending, _ := scheduler.Pending(); len(pending) != 0 {
+		t.Errorf("Expected no pending messages, got %d", len(pending))
+	}
+}
+
+func TestSchedulerRetry(t *testing.T) {
+	var uuids []string
+	failures := 1
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var reqBody struct {
+			UUID string `json:"uuid"`
+		}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		uuids = append(uuids, reqBody.UUID)
+		if failures > 0 {
+			failures--
+			w.Write([]byte(`{"code":99991400,"msg":"request trigger frequency limit"}`))
+			return
+		}
+		writeTestResponse(w, map[string]interface{}{"message_id": "om_1"})
+	})
+	scheduler := NewScheduler(client.Message, nil)
+	scheduler.MaxAttempts = 3
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	scheduler.now = func() time.Time { return now }
+	var errs []error
+	scheduler.OnError = func(msg *ScheduledMessage, err error) {
+		errs = append(errs, err)
+	}
+	sent := 0
+	scheduler.OnSent = func(msg *ScheduledMessage, messageID string) {
+		sent++
+	}
+	stop := make(chan struct{})
+
+	// 第一次发送失败后保留消息并推迟重试
+	id, _ := scheduler.Schedule(now, ChatReceiver("oc_1"), &TextContent{Text: "hi"})
+	if wait := scheduler.dispatchDue(stop); wait != 30*time.Second {
+		t.Errorf("Expected retry after 30s, got %v", wait)
+	}
+	pending, _ := scheduler.Pending()
+	if len(pending) != 1 || pending[0].Attempts != 1 || !pending[0].At.Equal(now.Add(30*time.Second)) || pending[0].LastError == "" {
+		t.Fatalf("Expected message to be rescheduled, got %+v", pending)
+	}
+	if len(errs) != 1 || errors.Is(errs[0], ErrScheduleGaveUp) {
+		t.Errorf("Unexpected errors %v", errs)
+	}
+
+	// 到期后重试成功，使用相同的uuid
+	now = now.Add(30 * time.Second)
+	scheduler.dispatchDue(stop)
+	if sent != 1 || len(uuids) != 2 || uuids[0] != deriveUUID(id) || uuids[1] != uuids[0] {
+		t.Errorf("Expected retry with the same uuid, sent=%d uuids=%q", sent, uuids)
+	}
+	if pending, _ := scheduler.Pending(); len(pending) != 0 {
+		t.Errorf("Expected no pending messages, got %+v", pending)
+	}
+
+	// 失败次数达到上限后删除
+	failures = 3
+	errs = nil
+	scheduler.Schedule(now, ChatReceiver("oc_1"), &TextContent{Text: "hi"})
+	for i := 0; i < 3; i++ {
+		scheduler.dispatchDue(stop)
+		now = now.Add(time.Hour)
+	}
+	if len(errs) != 3 || !errors.Is(errs[2], ErrScheduleGaveUp) {
+		t.Errorf("Expected to give up after 3 attempts, got %v", errs)
+	}
+	if pending, _ := scheduler.Pending(); len(pending) != 0 {
+		t.Errorf("Expected message to be removed, got %+v", pending)
+	}
+}
+
+func TestSchedulerFileStoreRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedule.json")
+	client, sends := newSchedulerTestClient(t)
+
+	store, err := NewFileScheduleStore(path)
+	if err != nil {
+		t.Fatalf("create store failed: %v", err)
+	}
+	scheduler := NewScheduler(client.Message, store)
+	overdue, err := scheduler.Schedule(time.Now().Add(-time.Minute), ChatReceiver("oc_1"), &TextContent{Text: "overdue"})
+	if err != nil {
+		t.Fatalf("schedule failed: %v", err)
+	}
+	if _, err := scheduler.Schedule(time.Now().Add(time.Hour), ChatReceiver("oc_1"), &TextContent{Text: "future"}); err != nil {
+		t.Fatalf("schedule failed: %v", err)
+	}
+
+	// 模拟重启：重新从文件加载
+	reloaded, err := NewFileScheduleStore(path)
+	if err != nil {
+		t.Fatalf("reload store failed: %v", err)
+	}
+	if pending, _ := reloaded.List(); len(pending) != 2 {
+		t.Fatalf("Expected 2 pending messages after reload, got %d", len(pending))
+	}
+
+	restarted := NewScheduler(client.Message, reloaded)
+	restarted.Start()
+	waitFor(t, func() bool { return len(sends()) == 1 })
+	restarted.Stop()
+
+	if got := sends(); got[0].Text != "overdue" || got[0].UUID != deriveUUID(overdue) {
+		t.Errorf("Unexpected send: %+v", got[0])
+	}
+	final, err := NewFileScheduleStore(path)
+	if err != nil {
+		t.Fatalf("reload store failed: %v", err)
+	}
+	pending, _ := final.List()
+	if len(pending) != 1 || pending[0].Content["text"] != "future" {
+		t.Errorf("Expected future message to remain, got %v", pending)
+	}
+}
+
+func TestSchedulerValidation(t *testing.T) {
+	scheduler := NewScheduler(NewClient("id", "secret").Message, nil)
+	if _, err := scheduler.Schedule(time.Now(), Receiver{}, &TextContent{Text: "hi"}); err == nil {
+		t.Error("Expected error for empty receiver")
+	}
+	if _, err := scheduler.Schedule(time.Now(), ChatReceiver("oc_1"), NewMessageCard()); err == nil {
+		t.Error("Expected error for invalid card")
+	}
+}
+
+func TestNextLocalTime(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	now := time.Date(2024, 1, 1, 0, 30, 0, 0, time.UTC) // 08:30 UTC+8
+
+	next := NextLocalTime(now, 9, 0, loc)
+	if want := time.Date(2024, 1, 1, 9, 0, 0, 0, loc); !next.Equal(want) {
+		t.Errorf("Expected %v, got %v", want, next)
+	}
+	next = NextLocalTime(now, 8, 0, loc)
+	if want := time.Date(2024, 1, 2, 8, 0, 0, 0, loc); !next.Equal(want) {
+		t.Errorf("Expected %v, got %v", want, next)
+	}
+}
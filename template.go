@@ -0,0 +1,202 @@
+package easylark
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// templateKind 模板渲染结果的消息类型
+type templateKind int
+
+const (
+	templateText templateKind = iota
+	templatePost
+	templateCard
+)
+
+// messageTemplate 已注册的消息模板
+type messageTemplate struct {
+	kind  templateKind
+	title *template.Template
+	body  *template.Template
+}
+
+// MessageTemplates 按名称注册的消息模板
+//
+// 模板使用text/template语法，注册时即解析，解析错误直接返回；
+// 模板中可使用以下函数：
+//
+//	escape        转义文本消息中的用户输入
+//	escapeMarkdown 转义markdown中的用户输入，富文本模板中只用反斜杠转义，不转为HTML实体
+//	json          将值编码为JSON，用于卡片模板
+//	mention       生成文本消息中@用户的标签，参数为open_id和名称
+//	mentionAll    生成文本消息中@所有人的标签
+//	mdMention     生成卡片markdown中@用户的标签，参数为open_id
+//	mdMentionAll  生成卡片markdown中@所有人的标签
+type MessageTemplates struct {
+	// MentionResolver 富文本模板中解析@name，为空时@name保留为普通文本
+	MentionResolver MentionResolver
+
+	mu        sync.RWMutex
+	templates map[string]*messageTemplate
+}
+
+// NewMessageTemplates 创建消息模板注册表
+func NewMessageTemplates() *MessageTemplates {
+	return &MessageTemplates{
+		templates: make(map[string]*messageTemplate),
+	}
+}
+
+// templateFuncs 模板可用的函数
+var templateFuncs = template.FuncMap{
+	"escape":         EscapeText,
+	"escapeMarkdown": EscapeMarkdown,
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"mention": func(openID, name string) string {
+		return NewTextBuilder().Mention(openID, name).String()
+	},
+	"mentionAll": func() string {
+		return NewTextBuilder().MentionAll().String()
+	},
+	"mdMention": MarkdownMention,
+	"mdMentionAll": func() string {
+		return MarkdownMention(MentionAllID)
+	},
+}
+
+// postTemplateFuncs 富文本模板中覆盖的函数，模板渲染后由MarkdownConverter解析，不解码HTML实体
+var postTemplateFuncs = template.FuncMap{
+	"escapeMarkdown": postMarkdownEscaper.Replace,
+}
+
+var postMarkdownEscaper = strings.NewReplacer(
+	"\\", "\\\\", "*", "\\*", "_", "\\_", "~", "\\~", "`", "\\`",
+	"[", "\\[", "]", "\\]", "(", "\\(", ")", "\\)", "#", "\\#", "|", "\\|",
+	"<", "\\<", ">", "\\>", "@", "\\@",
+)
+
+// parseTemplate 解析模板，overrides覆盖默认的模板函数，缺失的字段在渲染时报错
+func parseTemplate(name, text string, overrides template.FuncMap) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Funcs(overrides).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse template %s failed: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// register 注册模板，同名模板会被覆盖
+func (t *MessageTemplates) register(name string, tmpl *messageTemplate) error {
+	if name == "" {
+		return fmt.Errorf("register template failed: name is empty")
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.templates == nil {
+		t.templates = make(map[string]*messageTemplate)
+	}
+	t.templates[name] = tmpl
+	return nil
+}
+
+// RegisterText 注册文本消息模板
+func (t *MessageTemplates) RegisterText(name, text string) error {
+	body, err := parseTemplate(name, text, nil)
+	if err != nil {
+		return err
+	}
+	return t.register(name, &messageTemplate{kind: templateText, body: body})
+}
+
+// RegisterPost 注册富文本消息模板，markdown渲染后通过MarkdownConverter转换为富文本
+func (t *MessageTemplates) RegisterPost(name, title, markdown string) error {
+	titleTmpl, err := parseTemplate(name+".title", title, nil)
+	if err != nil {
+		return err
+	}
+	body, err := parseTemplate(name, markdown, postTemplateFuncs)
+	if err != nil {
+		return err
+	}
+	return t.register(name, &messageTemplate{kind: templatePost, title: titleTmpl, body: body})
+}
+
+// RegisterCard 注册卡片消息模板，cardJSON渲染后需为合法的卡片JSON
+func (t *MessageTemplates) RegisterCard(name, cardJSON string) error {
+	body, err := parseTemplate(name, cardJSON, nil)
+	if err != nil {
+		return err
+	}
+	return t.register(name, &messageTemplate{kind: templateCard, body: body})
+}
+
+// Has 判断模板是否已注册
+func (t *MessageTemplates) Has(name string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	_, ok := t.templates[name]
+	return ok
+}
+
+// Render 使用data渲染模板，返回*TextContent、*PostContent或*InteractiveContent
+func (t *MessageTemplates) Render(name string, data interface{}) (MessageContent, error) {
+	t.mu.RLock()
+	tmpl, ok := t.templates[name]
+	t.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("render template failed: template %s not registered", name)
+	}
+
+	body, err := executeTemplate(tmpl.body, data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch tmpl.kind {
+	case templatePost:
+		title, err := executeTemplate(tmpl.title, data)
+		if err != nil {
+			return nil, err
+		}
+		return NewMarkdownConverter(t.MentionResolver).ToPost(strings.TrimSpace(title), body)
+	case templateCard:
+		card := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(body), &card); err != nil {
+			return nil, fmt.Errorf("render template %s failed: invalid card json: %w", name, err)
+		}
+		return &InteractiveContent{Card: card}, nil
+	default:
+		if strings.TrimSpace(body) == "" {
+			return nil, fmt.Errorf("render template %s failed: empty text", name)
+		}
+		return &TextContent{Text: body}, nil
+	}
+}
+
+// executeTemplate 执行模板
+func executeTemplate(tmpl *template.Template, data interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("render template %s failed: %w", tmpl.Name(), err)
+	}
+	return buf.String(), nil
+}
+
+// SendTemplate 使用已注册的模板渲染消息并发送给receiver，receiver的ID类型优先于WithReceiveIDType
+func (s *MessageService) SendTemplate(receiver Receiver, name string, data interface{}, opts ...SendOption) error {
+	content, err := s.Templates.Render(name, data)
+	if err != nil {
+		return err
+	}
+	if receiver.IDType != "" {
+		opts = append(opts, WithReceiveIDType(receiver.IDType))
+	}
+	return s.SendMessage(receiver.ID, content, opts...)
+}
@@ -0,0 +1,120 @@
+package easylark
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMessageTemplatesRender(t *testing.T) {
+	templates := NewMessageTemplates()
+	if err := templates.RegisterText("alert", `{{mention .OwnerID .Owner}} {{escape .Service}} is down`); err != nil {
+		t.Fatalf("register text template failed: %v", err)
+	}
+	if err := templates.RegisterPost("release", "Release {{.Version}}", "**{{escapeMarkdown .Service}}** deployed\n- {{.Note}}"); err != nil {
+		t.Fatalf("register post template failed: %v", err)
+	}
+	if err := templates.RegisterCard("card", `{"elements":[{"tag":"markdown","content":{{json .Message}}}]}`); err != nil {
+		t.Fatalf("register card template failed: %v", err)
+	}
+
+	data := map[string]interface{}{
+		"Owner":   "Tom",
+		"OwnerID": "ou_1",
+		"Service": "api<prod>",
+		"Version": "v1",
+		"Note":    "fixed",
+		"Message": `quote " and newline` + "\n",
+	}
+
+	content, err := templates.Render("alert", data)
+	if err != nil {
+		t.Fatalf("render text failed: %v", err)
+	}
+	if text := content.(*TextContent).Text; text != `<at user_id="ou_1">Tom</at> api&lt;prod&gt; is down` {
+		t.Errorf("Unexpected text '%s'", text)
+	}
+
+	content, err = templates.Render("release", data)
+	if err != nil {
+		t.Fatalf("render post failed: %v", err)
+	}
+	post := content.(*PostContent)
+	if post.ZhCn.Title != "Release v1" || len(post.ZhCn.Content) != 2 {
+		t.Fatalf("Unexpected post: %+v", post.ZhCn)
+	}
+	// 富文本模板中的escapeMarkdown只用反斜杠转义，转换后还原为原文
+	if bold := post.ZhCn.Content[0][0]; bold.Text != "api<prod>" || !sameStyles(bold.Style, []TextStyle{TextStyleBold}) {
+		t.Errorf("Expected bold text api<prod>, got %+v", bold)
+	}
+	templates.RegisterPost("escaped", "", "x {{escapeMarkdown .}} **{{escapeMarkdown .}}**")
+	content, err = templates.Render("escaped", "a<b & *c* @alice")
+	if err != nil {
+		t.Fatalf("render post failed: %v", err)
+	}
+	line := content.(*PostContent).ZhCn.Content[0]
+	if len(line) != 2 || line[0].Text != "x a<b & *c* @alice " || line[1].Text != "a<b & *c* @alice" {
+		t.Errorf("Expected escaped input to render literally, got %+v", line)
+	}
+
+	content, err = templates.Render("card", data)
+	if err != nil {
+		t.Fatalf("render card failed: %v", err)
+	}
+	element := content.(*InteractiveContent).Card["elements"].([]interface{})[0].(map[string]interface{})
+	if element["content"] != data["Message"] {
+		t.Errorf("Unexpected card content %v", element["content"])
+	}
+}
+
+func TestMessageTemplatesErrors(t *testing.T) {
+	templates := NewMessageTemplates()
+	if err := templates.RegisterText("broken", "{{.Name"); err == nil {
+		t.Error("Expected parse error at registration")
+	}
+	if templates.Has("broken") {
+		t.Error("Expected broken template not to be registered")
+	}
+
+	templates.RegisterText("strict", "{{.Missing}}")
+	if _, err := templates.Render("strict", map[string]interface{}{}); err == nil {
+		t.Error("Expected error for missing key")
+	}
+	if _, err := templates.Render("unknown", nil); err == nil || !strings.Contains(err.Error(), "not registered") {
+		t.Errorf("Expected not registered error, got %v", err)
+	}
+
+	templates.RegisterCard("badcard", `{"elements": {{.}}`)
+	if _, err := templates.Render("badcard", 1); err == nil {
+		t.Error("Expected invalid card json error")
+	}
+}
+
+func TestSendTemplate(t *testing.T) {
+	var receivers []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		receivers = append(receivers, r.URL.Query().Get("receive_id_type")+":"+reqBody["receive_id"].(string))
+		content := reqBody["content"].(map[string]interface{})
+		if reqBody["msg_type"] != "text" || content["text"] != "hello Tom" {
+			t.Errorf("Unexpected request body %v", reqBody)
+		}
+		writeTestResponse(w, map[string]interface{}{"message_id": "om_1"})
+	})
+
+	if err := client.Message.Templates.RegisterText("greet", "hello {{.}}"); err != nil {
+		t.Fatalf("register template failed: %v", err)
+	}
+	if err := client.Message.SendTemplate(ChatReceiver("chat123"), "greet", "Tom"); err != nil {
+		t.Fatalf("send template failed: %v", err)
+	}
+	if err := client.Message.SendTemplate(UserReceiver("ou_1"), "greet", "Tom"); err != nil {
+		t.Fatalf("send template failed: %v", err)
+	}
+	want := []string{"chat_id:chat123", "open_id:ou_1"}
+	if strings.Join(receivers, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected receivers %v, got %v", want, receivers)
+	}
+}
@@ -0,0 +1,513 @@
+package easylark
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrWSRejected 长连接被服务端拒绝，如应用凭证错误或连接数超限，重连无法恢复
+var ErrWSRejected = errors.New("long connection rejected")
+
+// 长连接的默认配置
+const (
+	defaultWSPingInterval = 2 * time.Minute
+	defaultWSMinBackoff   = time.Second
+	defaultWSMaxBackoff   = 2 * time.Minute
+	wsDialTimeout         = 10 * time.Second
+	wsFragmentTTL         = time.Minute
+)
+
+// 获取长连接地址和握手返回的错误码
+const (
+	wsCodeSystemBusy       = 1
+	wsCodeInternalError    = 1000040343
+	wsCodeExceedConnLimit  = 1000040350
+	wsHandshakeAuthFailed  = 514
+	wsHandshakeAuthErrCode = "Handshake-Autherrcode"
+)
+
+// wsClientConfig 服务端下发的长连接配置，时间单位为秒，缺少的字段不修改当前配置
+type wsClientConfig struct {
+	ReconnectCount    *int `json:"ReconnectCount"`
+	ReconnectInterval int  `json:"ReconnectInterval"`
+	ReconnectNonce    int  `json:"ReconnectNonce"`
+	PingInterval      int  `json:"PingInterval"`
+}
+
+// WSClient 通过长连接接收事件，无需公网地址
+//
+// 长连接收到的事件与EventHandler使用相同的方式去重和分发：
+//
+//	dispatcher := easylark.NewEventDispatcher().OnMessageReceive(...)
+//	ws := easylark.NewWSClient(client, dispatcher.Dispatch)
+//	err := ws.Start(ctx)
+//
+// 配置Cards后长连接同时接收卡片回调；未配置时卡片回调直接回复成功，避免服务端超时重推。
+type WSClient struct {
+	// Events 处理事件的EventHandler，可配置去重、异步处理和回调；长连接的事件不加密，无需配置EncryptKey
+	Events *EventHandler
+	// Cards 处理卡片回调的CardActionHandler，长连接的回调不签名，只校验VerificationToken
+	Cards *CardActionHandler
+	// MinBackoff和MaxBackoff 重连的最小和最大间隔，间隔按指数增长并加入随机抖动；
+	// 服务端下发重连间隔时以其为最小间隔，下发随机值时以其为抖动范围
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// OnConnected 连接建立后调用
+	OnConnected func()
+	// OnError 连接断开或事件处理失败时调用
+	OnError func(err error)
+
+	client *Client
+
+	mu                sync.Mutex
+	pingInterval      time.Duration
+	reconnectCount    int           // 服务端下发的最大重连次数，小于0时不限
+	reconnectInterval time.Duration // 服务端下发的重连间隔，为0时使用MinBackoff
+	reconnectNonce    time.Duration // 服务端下发的重连随机抖动范围，为0时使用间隔的50%
+}
+
+// NewWSClient 创建长连接客户端，handler与NewEventHandler的处理函数相同
+func NewWSClient(client *Client, handler EventHandlerFunc) *WSClient {
+	return &WSClient{
+		Events:         NewEventHandler("", "", handler),
+		MinBackoff:     defaultWSMinBackoff,
+		MaxBackoff:     defaultWSMaxBackoff,
+		client:         client,
+		pingInterval:   defaultWSPingInterval,
+		reconnectCount: -1,
+	}
+}
+
+// Start 建立长连接并持续接收事件，断开后自动重连，阻塞到ctx取消或连接被拒绝
+//
+// ctx取消时返回nil；连接被拒绝或超过服务端配置的重连次数时返回错误。
+func (c *WSClient) Start(ctx context.Context) error {
+	attempt := 0
+	for {
+		connected, err := c.serve(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if errors.Is(err, ErrWSRejected) {
+			return err
+		}
+		c.reportError(err)
+
+		if connected {
+			attempt = 0
+		}
+		attempt++
+		if limit := c.maxReconnect(); limit >= 0 && attempt > limit {
+			return fmt.Errorf("long connection failed after %d reconnect attempts: %w", limit, err)
+		}
+
+		timer := time.NewTimer(c.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// serve 建立一次连接并处理到连接断开，connected表示连接是否建立成功
+func (c *WSClient) serve(ctx context.Context) (bool, error) {
+	rawURL, err := c.endpoint(ctx)
+	if err != nil {
+		return false, err
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false, fmt.Errorf("parse long connection url failed: %w", err)
+	}
+	serviceID, _ := strconv.ParseInt(u.Query().Get("service_id"), 10, 32)
+
+	dialCtx, cancel := context.WithTimeout(ctx, wsDialTimeout)
+	conn, err := dialWebSocket(dialCtx, rawURL)
+	cancel()
+	if err != nil {
+		var handshakeErr *wsHandshakeError
+		if errors.As(err, &handshakeErr) && handshakeErr.rejected() {
+			return false, fmt.Errorf("%w: %v", ErrWSRejected, err)
+		}
+		return false, err
+	}
+	if c.OnConnected != nil {
+		c.OnConnected()
+	}
+
+	session := &wsSession{
+		client:    c,
+		conn:      conn,
+		serviceID: int32(serviceID),
+		fragments: make(map[string]*wsFragments),
+		done:      make(chan struct{}),
+	}
+	return true, session.run(ctx)
+}
+
+// endpoint 获取长连接地址，并应用服务端下发的配置
+func (c *WSClient) endpoint(ctx context.Context) (string, error) {
+	body, err := json.Marshal(map[string]string{
+		"AppID":     c.client.AppID,
+		"AppSecret": c.client.AppSecret,
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshal request body failed: %w", err)
+	}
+
+	endpointURL := strings.TrimSuffix(BaseURL, "/open-apis") + "/callback/ws/endpoint"
+	req, err := http.NewRequestWithContext(ctx, "POST", endpointURL, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("create request failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("locale", "zh")
+
+	resp, err := c.client.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("get long connection endpoint failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("read response body failed: %w", err)
+	}
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			URL          string          `json:"URL"`
+			ClientConfig *wsClientConfig `json:"ClientConfig"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("unmarshal response body failed: %w", err)
+	}
+
+	switch result.Code {
+	case 0:
+	case wsCodeSystemBusy, wsCodeInternalError:
+		return "", fmt.Errorf("get long connection endpoint failed: %w", &Error{Code: result.Code, Message: result.Msg})
+	default:
+		return "", fmt.Errorf("%w: %v", ErrWSRejected, &Error{Code: result.Code, Message: result.Msg})
+	}
+	if result.Data.URL == "" {
+		return "", fmt.Errorf("get long connection endpoint failed: empty url")
+	}
+	c.applyConfig(result.Data.ClientConfig)
+	return result.Data.URL, nil
+}
+
+// applyConfig 应用服务端下发的配置
+func (c *WSClient) applyConfig(config *wsClientConfig) {
+	if config == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if config.PingInterval > 0 {
+		c.pingInterval = time.Duration(config.PingInterval) * time.Second
+	}
+	if config.ReconnectCount != nil {
+		c.reconnectCount = *config.ReconnectCount
+	}
+	if config.ReconnectInterval > 0 {
+		c.reconnectInterval = time.Duration(config.ReconnectInterval) * time.Second
+	}
+	if config.ReconnectNonce > 0 {
+		c.reconnectNonce = time.Duration(config.ReconnectNonce) * time.Second
+	}
+}
+
+// interval 当前的ping间隔
+func (c *WSClient) interval() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pingInterval
+}
+
+// maxReconnect 最大重连次数，小于0时不限
+func (c *WSClient) maxReconnect() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.reconnectCount
+}
+
+// backoff 第attempt次重连前的等待时间
+func (c *WSClient) backoff(attempt int) time.Duration {
+	c.mu.Lock()
+	interval, nonce := c.reconnectInterval, c.reconnectNonce
+	c.mu.Unlock()
+
+	lower, upper := c.MinBackoff, c.MaxBackoff
+	if lower <= 0 {
+		lower = defaultWSMinBackoff
+	}
+	if interval > lower {
+		lower = interval
+	}
+	if upper < lower {
+		upper = lower
+	}
+	d := lower
+	for i := 1; i < attempt && d < upper; i++ {
+		d *= 2
+	}
+	if d > upper {
+		d = upper
+	}
+	// 加入随机抖动，避免大量客户端同时重连
+	if nonce > 0 {
+		return d + time.Duration(rand.Int63n(int64(nonce)))
+	}
+	return d + time.Duration(rand.Int63n(int64(d)/2+1))
+}
+
+// reportError 报告错误
+func (c *WSClient) reportError(err error) {
+	if err != nil && c.OnError != nil {
+		c.OnError(err)
+	}
+}
+
+// rejected 判断握手失败是否为无法通过重连恢复的错误
+func (e *wsHandshakeError) rejected() bool {
+	switch e.StatusCode {
+	case http.StatusForbidden:
+		return true
+	case wsHandshakeAuthFailed:
+		return e.Header.Get(wsHandshakeAuthErrCode) == strconv.Itoa(wsCodeExceedConnLimit)
+	}
+	return false
+}
+
+// wsFragments 分片事件的缓存
+type wsFragments struct {
+	parts   [][]byte
+	created time.Time
+}
+
+// wsSession 一次长连接
+type wsSession struct {
+	client    *WSClient
+	conn      *wsConn
+	serviceID int32
+
+	fragments map[string]*wsFragments // 只在读取的goroutine中访问
+	done      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// run 处理连接直到断开，ctx取消时关闭连接
+func (s *wsSession) run(ctx context.Context) error {
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.conn.close()
+		case <-s.done:
+		}
+	}()
+	go s.pingLoop()
+
+	err := s.readLoop(ctx)
+	close(s.done)
+	s.conn.close()
+	s.wg.Wait()
+	return err
+}
+
+// pingLoop 定时发送ping
+func (s *wsSession) pingLoop() {
+	for {
+		frame := &wsFrame{Service: s.serviceID, Method: wsMethodControl}
+		frame.setHeader(wsHeaderType, wsTypePing)
+		if err := s.conn.writeFrame(wsOpBinary, frame.marshal()); err != nil {
+			return
+		}
+
+		timer := time.NewTimer(s.client.interval())
+		select {
+		case <-s.done:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// readLoop 读取并处理帧，超过3个ping间隔没有收到数据时认为连接已断开
+func (s *wsSession) readLoop(ctx context.Context) error {
+	for {
+		s.conn.setReadDeadline(time.Now().Add(3 * s.client.interval()))
+		opcode, data, err := s.conn.readMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("read long connection failed: %w", err)
+		}
+		if opcode != wsOpBinary {
+			continue
+		}
+
+		frame, err := unmarshalWSFrame(data)
+		if err != nil {
+			s.client.reportError(err)
+			continue
+		}
+		switch frame.Method {
+		case wsMethodControl:
+			if frame.header(wsHeaderType) == wsTypePong && len(frame.Payload) > 0 {
+				var config wsClientConfig
+				if err := json.Unmarshal(frame.Payload, &config); err == nil {
+					s.client.applyConfig(&config)
+				}
+			}
+		case wsMethodData:
+			s.handleData(ctx, frame)
+		}
+	}
+}
+
+// handleData 处理数据帧，分片全部到达后异步处理事件或卡片回调并回复ack
+func (s *wsSession) handleData(ctx context.Context, frame *wsFrame) {
+	typ := frame.header(wsHeaderType)
+	if typ != wsTypeEvent && typ != wsTypeCard {
+		return
+	}
+	payload := s.combine(frame)
+	if payload == nil {
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		start := time.Now()
+		var status int
+		var data []byte
+		if typ == wsTypeCard {
+			status, data = s.dispatchCard(ctx, payload)
+		} else {
+			status = s.dispatch(ctx, payload)
+		}
+		if err := s.ack(frame, status, data, time.Since(start)); err != nil {
+			s.client.reportError(fmt.Errorf("ack %s failed: %w", typ, err))
+		}
+	}()
+}
+
+// combine 拼接分片的事件，分片未全部到达时返回nil
+func (s *wsSession) combine(frame *wsFrame) []byte {
+	sum, _ := strconv.Atoi(frame.header(wsHeaderSum))
+	if sum <= 1 {
+		return frame.Payload
+	}
+	seq, _ := strconv.Atoi(frame.header(wsHeaderSeq))
+	if seq < 0 || seq >= sum {
+		return nil
+	}
+
+	now := time.Now()
+	for id, cached := range s.fragments {
+		if now.Sub(cached.created) > wsFragmentTTL {
+			delete(s.fragments, id)
+		}
+	}
+
+	id := frame.header(wsHeaderMessageID)
+	cached, ok := s.fragments[id]
+	if !ok || len(cached.parts) != sum {
+		cached = &wsFragments{parts: make([][]byte, sum), created: now}
+		s.fragments[id] = cached
+	}
+	cached.parts[seq] = frame.Payload
+	for _, part := range cached.parts {
+		if part == nil {
+			return nil
+		}
+	}
+	delete(s.fragments, id)
+	return bytes.Join(cached.parts, nil)
+}
+
+// dispatch 解析并处理事件，返回ack的状态码
+func (s *wsSession) dispatch(ctx context.Context, payload []byte) int {
+	events := s.client.Events
+	event, _, err := events.parse(nil, payload)
+	if err != nil {
+		s.client.reportError(err)
+		return http.StatusBadRequest
+	}
+	if event == nil {
+		return http.StatusOK
+	}
+	status, err := events.process(ctx, event)
+	if err != nil {
+		s.client.reportError(fmt.Errorf("event %s: %w", event.EventID, err))
+		return status
+	}
+	return http.StatusOK
+}
+
+// dispatchCard 解析并处理卡片回调，返回ack的状态码和回调的响应；未配置Cards时直接回复成功
+func (s *wsSession) dispatchCard(ctx context.Context, payload []byte) (int, []byte) {
+	cards := s.client.Cards
+	if cards == nil {
+		return http.StatusOK, nil
+	}
+	event, _, err := cards.parse(nil, payload)
+	if err != nil {
+		s.client.reportError(err)
+		return http.StatusBadRequest, nil
+	}
+	if event == nil {
+		return http.StatusOK, nil
+	}
+
+	var resp *CardResponse
+	if handler := cards.route(event); handler != nil {
+		resp, err = handler(ctx, event)
+		if err != nil {
+			s.client.reportError(fmt.Errorf("card action %s: %w", event.Token, err))
+			return http.StatusInternalServerError, nil
+		}
+	}
+	data, err := resp.marshal(event.V2)
+	if err != nil {
+		s.client.reportError(err)
+		return http.StatusInternalServerError, nil
+	}
+	return http.StatusOK, data
+}
+
+// ack 回复事件或卡片回调的处理结果，data为卡片回调的响应，以base64编码放入data字段
+func (s *wsSession) ack(frame *wsFrame, status int, data []byte, elapsed time.Duration) error {
+	body := map[string]interface{}{"code": status}
+	if data != nil {
+		body["data"] = data
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	resp := *frame
+	resp.Headers = append([]wsHeader(nil), frame.Headers...)
+	resp.setHeader(wsHeaderBizRT, strconv.FormatInt(elapsed.Milliseconds(), 10))
+	resp.Payload = payload
+	return s.conn.writeFrame(wsOpBinary, resp.marshal())
+}
@@ -0,0 +1,270 @@
+package easylark
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// acceptTestWebSocket 在测试服务端完成WebSocket握手
+func acceptTestWebSocket(t *testing.T, w http.ResponseWriter, r *http.Request) *wsConn {
+	t.Helper()
+	conn, brw, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		t.Fatalf("hijack failed: %v", err)
+	}
+	fmt.Fprintf(brw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n",
+		wsAcceptKey(r.Header.Get("Sec-WebSocket-Key")))
+	brw.Flush()
+	t.Cleanup(func() { conn.Close() })
+	return &wsConn{conn: conn, br: brw.Reader}
+}
+
+// readTestFrame 在测试服务端读取一个长连接帧
+func readTestFrame(t *testing.T, conn *wsConn) *wsFrame {
+	t.Helper()
+	conn.setReadDeadline(time.Now().Add(5 * time.Second))
+	_, data, err := conn.readMessage()
+	if err != nil {
+		t.Fatalf("read frame failed: %v", err)
+	}
+	frame, err := unmarshalWSFrame(data)
+	if err != nil {
+		t.Fatalf("decode frame failed: %v", err)
+	}
+	return frame
+}
+
+// readTestAck 在测试服务端读取事件的ack，跳过ping
+func readTestAck(t *testing.T, conn *wsConn) *wsFrame {
+	t.Helper()
+	for {
+		frame := readTestFrame(t, conn)
+		if frame.Method == wsMethodData {
+			return frame
+		}
+	}
+}
+
+// writeTestEventFrames 在测试服务端按分片发送事件
+func writeTestEventFrames(t *testing.T, conn *wsConn, messageID string, payload []byte, sum int) {
+	t.Helper()
+	size := (len(payload) + sum - 1) / sum
+	for seq := 0; seq < sum; seq++ {
+		end := (seq + 1) * size
+		if end > len(payload) {
+			end = len(payload)
+		}
+		frame := &wsFrame{SeqID: 100, LogID: 200, Service: 7, Method: wsMethodData, Payload: payload[seq*size : end]}
+		frame.setHeader(wsHeaderType, wsTypeEvent)
+		frame.setHeader(wsHeaderMessageID, messageID)
+		frame.setHeader(wsHeaderSum, strconv.Itoa(sum))
+		frame.setHeader(wsHeaderSeq, strconv.Itoa(seq))
+		frame.setHeader(wsHeaderTraceID, "trace_1")
+		if err := conn.writeFrame(wsOpBinary, frame.marshal()); err != nil {
+			t.Fatalf("write frame failed: %v", err)
+		}
+	}
+}
+
+func TestWSClient(t *testing.T) {
+	conns := make(chan *wsConn, 2)
+	endpointCalls := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/callback/ws/endpoint":
+			endpointCalls++
+			var reqBody map[string]string
+			json.NewDecoder(r.Body).Decode(&reqBody)
+			if reqBody["AppID"] != "test-app-id" || reqBody["AppSecret"] != "test-app-secret" {
+				t.Errorf("Unexpected endpoint request: %v", reqBody)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"code": 0,
+				"data": map[string]interface{}{
+					"URL":          "ws://" + r.Host + "/ws?device_id=d_1&service_id=7",
+					"ClientConfig": map[string]interface{}{"PingInterval": 30, "ReconnectCount": -1},
+				},
+			})
+		case "/ws":
+			conns <- acceptTestWebSocket(t, w, r)
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	})
+
+	received := make(chan *MessageReceiveEvent, 2)
+	dispatcher := NewEventDispatcher().OnMessageReceive(func(ctx context.Context, event *MessageReceiveEvent) error {
+		received <- event
+		return nil
+	})
+	ws := NewWSClient(client, dispatcher.Dispatch)
+	ws.MinBackoff = 10 * time.Millisecond
+	ws.MaxBackoff = 20 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- ws.Start(ctx) }()
+
+	server := <-conns
+	ping := readTestFrame(t, server)
+	if ping.Method != wsMethodControl || ping.header(wsHeaderType) != wsTypePing || ping.Service != 7 {
+		t.Fatalf("Expected ping frame, got %+v", ping)
+	}
+	if ws.interval() != 30*time.Second {
+		t.Errorf("Expected ping interval from endpoint config, got %v", ws.interval())
+	}
+
+	// pong中携带新的配置
+	pong := &wsFrame{Service: 7, Method: wsMethodControl, Payload: []byte(`{"PingInterval":60}`)}
+	pong.setHeader(wsHeaderType, wsTypePong)
+	server.writeFrame(wsOpBinary, pong.marshal())
+
+	// 分片的事件拼接后分发，处理完成后回复ack
+	writeTestEventFrames(t, server, "msg_1", []byte(testMessageReceiveEvent), 3)
+	ack := readTestAck(t, server)
+	if ack.SeqID != 100 || ack.LogID != 200 || ack.header(wsHeaderMessageID) != "msg_1" || ack.header(wsHeaderBizRT) == "" {
+		t.Errorf("Unexpected ack: %+v", ack)
+	}
+	var ackPayload struct {
+		Code int `json:"code"`
+	}
+	if err := json.Unmarshal(ack.Payload, &ackPayload); err != nil || ackPayload.Code != http.StatusOK {
+		t.Errorf("Expected ack code 200, got %s", ack.Payload)
+	}
+	event := <-received
+	if event.Message.MessageID != "om_1" || event.Sender.SenderID.OpenID != "ou_1" {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+	if ws.interval() != 60*time.Second {
+		t.Errorf("Expected ping interval from pong, got %v", ws.interval())
+	}
+
+	// 连接断开后重连，重复推送的事件只回复ack
+	server.conn.Close()
+	server = <-conns
+	writeTestEventFrames(t, server, "msg_2", []byte(testMessageReceiveEvent), 1)
+	if ack := readTestAck(t, server); ack.header(wsHeaderMessageID) != "msg_2" {
+		t.Errorf("Unexpected ack: %+v", ack)
+	}
+	select {
+	case event := <-received:
+		t.Errorf("Expected duplicate event to be skipped, got %+v", event)
+	default:
+	}
+	if endpointCalls != 2 {
+		t.Errorf("Expected 2 endpoint calls, got %d", endpointCalls)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected nil after cancel, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start did not return after cancel")
+	}
+}
+
+func TestWSClientRejected(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":10003,"msg":"invalid app secret"}`))
+	})
+
+	ws := NewWSClient(client, nil)
+	err := ws.Start(context.Background())
+	if !errors.Is(err, ErrWSRejected) {
+		t.Errorf("Expected ErrWSRejected, got %v", err)
+	}
+}
+
+func TestWSClientBackoff(t *testing.T) {
+	ws := &WSClient{MinBackoff: time.Second, MaxBackoff: 10 * time.Second}
+	for attempt, want := range map[int]time.Duration{1: time.Second, 3: 4 * time.Second, 10: 10 * time.Second} {
+		if d := ws.backoff(attempt); d < want || d > want+want/2 {
+			t.Errorf("Expected backoff of attempt %d in [%v, %v], got %v", attempt, want, want+want/2, d)
+		}
+	}
+
+	// 服务端下发的重连间隔作为最小间隔，随机值作为抖动范围
+	ws.applyConfig(&wsClientConfig{ReconnectInterval: 3, ReconnectNonce: 2})
+	if d := ws.backoff(1); d < 3*time.Second || d >= 5*time.Second {
+		t.Errorf("Expected backoff in [3s, 5s), got %v", d)
+	}
+	if d := ws.backoff(10); d < 10*time.Second || d >= 12*time.Second {
+		t.Errorf("Expected backoff in [10s, 12s), got %v", d)
+	}
+}
+
+func TestWSClientCardAction(t *testing.T) {
+	conns := make(chan *wsConn, 1)
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/callback/ws/endpoint":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"code": 0,
+				"data": map[string]interface{}{"URL": "ws://" + r.Host + "/ws?service_id=7"},
+			})
+		case "/ws":
+			conns <- acceptTestWebSocket(t, w, r)
+		}
+	})
+
+	ws := NewWSClient(client, nil)
+	ws.Cards = NewCardActionHandler("vtoken", "").
+		On("submit", func(ctx context.Context, event *CardActionEvent) (*CardResponse, error) {
+			if event.OpenMessageID != "om_1" || event.FormValue["reason"] != "上线" {
+				t.Errorf("Unexpected card action: %+v", event)
+			}
+			return NewCardToast(CardToastSuccess, "已提交"), nil
+		})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ws.Start(ctx)
+	server := <-conns
+
+	writeCard := func(messageID, payload string) *wsFrame {
+		frame := &wsFrame{Service: 7, Method: wsMethodData, Payload: []byte(payload)}
+		frame.setHeader(wsHeaderType, wsTypeCard)
+		frame.setHeader(wsHeaderMessageID, messageID)
+		if err := server.writeFrame(wsOpBinary, frame.marshal()); err != nil {
+			t.Fatalf("write frame failed: %v", err)
+		}
+		return readTestAck(t, server)
+	}
+
+	// 卡片回调的响应以base64编码放入ack的data字段
+	ack := writeCard("card_1", testCardCallbackV2)
+	var ackPayload struct {
+		Code int    `json:"code"`
+		Data []byte `json:"data"`
+	}
+	if err := json.Unmarshal(ack.Payload, &ackPayload); err != nil || ackPayload.Code != http.StatusOK {
+		t.Fatalf("Expected ack code 200, got %s", ack.Payload)
+	}
+	var resp struct {
+		Toast *CardToast `json:"toast"`
+	}
+	if err := json.Unmarshal(ackPayload.Data, &resp); err != nil || resp.Toast == nil || resp.Toast.Content != "已提交" {
+		t.Errorf("Unexpected card response: %s", ackPayload.Data)
+	}
+
+	// token错误的回调
+	ack = writeCard("card_2", strings.Replace(testCardCallbackV2, `"token": "vtoken"`, `"token": "other"`, 1))
+	if err := json.Unmarshal(ack.Payload, &ackPayload); err != nil || ackPayload.Code != http.StatusBadRequest {
+		t.Errorf("Expected ack code 400, got %s", ack.Payload)
+	}
+
+	// 未配置Cards时直接回复成功
+	session := &wsSession{client: &WSClient{}}
+	if status, data := session.dispatchCard(ctx, []byte(testCardCallbackV2)); status != http.StatusOK || data != nil {
+		t.Errorf("Expected 200 without data, got %d %s", status, data)
+	}
+}
@@ -0,0 +1,61 @@
+package easylark
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestWSConnFragmentsAndControlFrames(t *testing.T) {
+	clientSide, serverSide := net.Pipe()
+	defer clientSide.Close()
+	defer serverSide.Close()
+
+	client := &wsConn{conn: clientSide, br: bufio.NewReader(clientSide), client: true}
+	server := &wsConn{conn: serverSide, br: bufio.NewReader(serverSide)}
+
+	go func() {
+		// 分片消息中间插入ping
+		serverSide.Write([]byte{wsOpBinary, 3, 'a', 'b', 'c'})
+		serverSide.Write([]byte{0x80 | wsOpPing, 1, 'p'})
+		serverSide.Write([]byte{0x80 | wsOpContinuation, 2, 'd', 'e'})
+		// 126字节以上的长度编码
+		server.writeFrame(wsOpBinary, bytes.Repeat([]byte("x"), 300))
+		server.writeFrame(wsOpClose, nil)
+	}()
+
+	// 服务端读取客户端回复的pong，验证客户端发送的帧带掩码
+	pong := make(chan []byte, 1)
+	go func() {
+		var raw [7]byte // 2字节头部、4字节掩码和1字节payload
+		io.ReadFull(serverSide, raw[:])
+		pong <- raw[:]
+		io.Copy(io.Discard, serverSide)
+	}()
+
+	opcode, message, err := client.readMessage()
+	if err != nil || opcode != wsOpBinary || string(message) != "abcde" {
+		t.Fatalf("Expected fragmented message abcde, got %d %q %v", opcode, message, err)
+	}
+	raw := <-pong
+	if raw[0] != 0x80|wsOpPong || raw[1] != 0x80|1 || raw[6]^raw[2] != 'p' {
+		t.Errorf("Expected masked pong frame, got %v", raw)
+	}
+
+	_, message, err = client.readMessage()
+	if err != nil || len(message) != 300 {
+		t.Fatalf("Expected 300 bytes message, got %d %v", len(message), err)
+	}
+	if _, _, err := client.readMessage(); err != io.EOF {
+		t.Errorf("Expected EOF after close frame, got %v", err)
+	}
+}
+
+func TestWSAcceptKey(t *testing.T) {
+	// RFC 6455中的示例
+	if got := wsAcceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("Unexpected accept key %s", got)
+	}
+}
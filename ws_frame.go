@@ -0,0 +1,237 @@
+package easylark
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// 长连接帧的method
+const (
+	wsMethodControl int32 = 0 // 控制帧，如ping、pong
+	wsMethodData    int32 = 1 // 数据帧，如事件
+)
+
+// 长连接帧的header
+const (
+	wsHeaderType      = "type"
+	wsHeaderMessageID = "message_id"
+	wsHeaderSum       = "sum"
+	wsHeaderSeq       = "seq"
+	wsHeaderTraceID   = "trace_id"
+	wsHeaderBizRT     = "biz_rt"
+)
+
+// 长连接帧的type
+const (
+	wsTypePing  = "ping"
+	wsTypePong  = "pong"
+	wsTypeEvent = "event"
+	wsTypeCard  = "card"
+)
+
+// wsHeader 帧的header
+type wsHeader struct {
+	Key   string
+	Value string
+}
+
+// wsFrame 长连接的帧，使用protobuf编码：
+//
+//	message Frame {
+//		required uint64 SeqID = 1;
+//		required uint64 LogID = 2;
+//		required int32 service = 3;
+//		required int32 method = 4;
+//		repeated Header headers = 5;
+//		optional string payload_encoding = 6;
+//		optional string payload_type = 7;
+//		optional bytes payload = 8;
+//		optional string LogIDNew = 9;
+//	}
+type wsFrame struct {
+	SeqID           uint64
+	LogID           uint64
+	Service         int32
+	Method          int32
+	Headers         []wsHeader
+	PayloadEncoding string
+	PayloadType     string
+	Payload         []byte
+	LogIDNew        string
+}
+
+// header 获取header的值
+func (f *wsFrame) header(key string) string {
+	for _, h := range f.Headers {
+		if h.Key == key {
+			return h.Value
+		}
+	}
+	return ""
+}
+
+// setHeader 设置header的值
+func (f *wsFrame) setHeader(key, value string) {
+	for i := range f.Headers {
+		if f.Headers[i].Key == key {
+			f.Headers[i].Value = value
+			return
+		}
+	}
+	f.Headers = append(f.Headers, wsHeader{Key: key, Value: value})
+}
+
+// protobuf的wire type
+const (
+	pbVarint  = 0
+	pbFixed64 = 1
+	pbBytes   = 2
+	pbFixed32 = 5
+)
+
+// marshal 编码帧
+func (f *wsFrame) marshal() []byte {
+	buf := make([]byte, 0, 64+len(f.Payload))
+	buf = appendPBVarint(buf, 1, f.SeqID)
+	buf = appendPBVarint(buf, 2, f.LogID)
+	// int32按int64的补码编码
+	buf = appendPBVarint(buf, 3, uint64(int64(f.Service)))
+	buf = appendPBVarint(buf, 4, uint64(int64(f.Method)))
+	for _, h := range f.Headers {
+		var header []byte
+		header = appendPBBytes(header, 1, []byte(h.Key))
+		header = appendPBBytes(header, 2, []byte(h.Value))
+		buf = appendPBBytes(buf, 5, header)
+	}
+	if f.PayloadEncoding != "" {
+		buf = appendPBBytes(buf, 6, []byte(f.PayloadEncoding))
+	}
+	if f.PayloadType != "" {
+		buf = appendPBBytes(buf, 7, []byte(f.PayloadType))
+	}
+	if f.Payload != nil {
+		buf = appendPBBytes(buf, 8, f.Payload)
+	}
+	if f.LogIDNew != "" {
+		buf = appendPBBytes(buf, 9, []byte(f.LogIDNew))
+	}
+	return buf
+}
+
+// unmarshalWSFrame 解码帧，忽略未知字段
+func unmarshalWSFrame(data []byte) (*wsFrame, error) {
+	f := &wsFrame{}
+	err := walkPBFields(data, func(field int, varint uint64, bytes []byte) error {
+		switch field {
+		case 1:
+			f.SeqID = varint
+		case 2:
+			f.LogID = varint
+		case 3:
+			f.Service = int32(varint)
+		case 4:
+			f.Method = int32(varint)
+		case 5:
+			var h wsHeader
+			err := walkPBFields(bytes, func(field int, _ uint64, bytes []byte) error {
+				switch field {
+				case 1:
+					h.Key = string(bytes)
+				case 2:
+					h.Value = string(bytes)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			f.Headers = append(f.Headers, h)
+		case 6:
+			f.PayloadEncoding = string(bytes)
+		case 7:
+			f.PayloadType = string(bytes)
+		case 8:
+			f.Payload = append([]byte(nil), bytes...)
+		case 9:
+			f.LogIDNew = string(bytes)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("decode frame failed: %w", err)
+	}
+	return f, nil
+}
+
+// appendPBVarint 追加varint类型的字段
+func appendPBVarint(buf []byte, field int, v uint64) []byte {
+	buf = appendUvarint(buf, uint64(field)<<3|pbVarint)
+	return appendUvarint(buf, v)
+}
+
+// appendPBBytes 追加length-delimited类型的字段
+func appendPBBytes(buf []byte, field int, v []byte) []byte {
+	buf = appendUvarint(buf, uint64(field)<<3|pbBytes)
+	buf = appendUvarint(buf, uint64(len(v)))
+	return append(buf, v...)
+}
+
+// appendUvarint 追加varint编码的v
+func appendUvarint(buf []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	return append(buf, tmp[:n]...)
+}
+
+// errPBTruncated protobuf数据不完整
+var errPBTruncated = errors.New("truncated protobuf data")
+
+// walkPBFields 遍历protobuf消息的字段，varint字段通过varint传递，length-delimited字段通过bytes传递
+func walkPBFields(data []byte, fn func(field int, varint uint64, bytes []byte) error) error {
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errPBTruncated
+		}
+		data = data[n:]
+		field := int(tag >> 3)
+
+		var varint uint64
+		var bytes []byte
+		switch tag & 7 {
+		case pbVarint:
+			varint, n = binary.Uvarint(data)
+			if n <= 0 {
+				return errPBTruncated
+			}
+			data = data[n:]
+		case pbBytes:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < length {
+				return errPBTruncated
+			}
+			bytes = data[n : n+int(length)]
+			data = data[n+int(length):]
+		case pbFixed64:
+			if len(data) < 8 {
+				return errPBTruncated
+			}
+			data = data[8:]
+			continue
+		case pbFixed32:
+			if len(data) < 4 {
+				return errPBTruncated
+			}
+			data = data[4:]
+			continue
+		default:
+			return fmt.Errorf("unsupported wire type %d", tag&7)
+		}
+
+		if err := fn(field, varint, bytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
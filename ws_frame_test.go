@@ -0,0 +1,51 @@
+package easylark
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWSFrameRoundTrip(t *testing.T) {
+	frame := &wsFrame{
+		SeqID:       1 << 40,
+		LogID:       7,
+		Service:     -1,
+		Method:      wsMethodData,
+		PayloadType: "json",
+		Payload:     []byte(`{"a":1}`),
+		LogIDNew:    "log-1",
+	}
+	frame.setHeader(wsHeaderType, wsTypeEvent)
+	frame.setHeader(wsHeaderMessageID, "m_1")
+	frame.setHeader(wsHeaderType, wsTypePing)
+
+	decoded, err := unmarshalWSFrame(frame.marshal())
+	if err != nil {
+		t.Fatalf("decode frame failed: %v", err)
+	}
+	if decoded.SeqID != frame.SeqID || decoded.LogID != 7 || decoded.Service != -1 || decoded.Method != wsMethodData {
+		t.Errorf("Unexpected frame: %+v", decoded)
+	}
+	if decoded.PayloadType != "json" || decoded.LogIDNew != "log-1" || !bytes.Equal(decoded.Payload, frame.Payload) {
+		t.Errorf("Unexpected frame fields: %+v", decoded)
+	}
+	if len(decoded.Headers) != 2 || decoded.header(wsHeaderType) != wsTypePing || decoded.header(wsHeaderMessageID) != "m_1" {
+		t.Errorf("Unexpected headers: %+v", decoded.Headers)
+	}
+}
+
+func TestWSFrameUnknownFieldsAndTruncation(t *testing.T) {
+	data := (&wsFrame{SeqID: 3}).marshal()
+	// 追加未知的fixed32和fixed64字段
+	data = append(data, 10<<3|pbFixed32, 1, 2, 3, 4)
+	data = append(data, 11<<3|pbFixed64, 1, 2, 3, 4, 5, 6, 7, 8)
+	decoded, err := unmarshalWSFrame(data)
+	if err != nil || decoded.SeqID != 3 {
+		t.Errorf("Expected unknown fields to be skipped, got %+v, %v", decoded, err)
+	}
+
+	full := (&wsFrame{Payload: []byte("payload")}).marshal()
+	if _, err := unmarshalWSFrame(full[:len(full)-2]); err == nil {
+		t.Error("Expected error for truncated frame")
+	}
+}